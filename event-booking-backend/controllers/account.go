@@ -0,0 +1,428 @@
+package controllers
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"golang.org/x/crypto/bcrypt"
+	"gorm.io/gorm"
+
+	"event-booking-backend/middlewares"
+	"event-booking-backend/models"
+	"event-booking-backend/services"
+)
+
+const (
+	minPasswordLength       = 8
+	emailVerificationExpiry = 48 * time.Hour
+	passwordResetExpiry     = time.Hour
+)
+
+var errInvalidToken = errors.New("invalid or expired token")
+
+// AccountController handles customer registration, login and the
+// customer's own profile and bookings.
+type AccountController struct {
+	db       *gorm.DB
+	email    *services.Mailer
+	throttle *services.LoginThrottle
+}
+
+func NewAccountController(db *gorm.DB, email *services.Mailer, throttle *services.LoginThrottle) *AccountController {
+	return &AccountController{
+		db:       db,
+		email:    email,
+		throttle: throttle,
+	}
+}
+
+// Register creates a customer account and sends the verification email
+func (c *AccountController) Register(ctx *gin.Context) {
+	var request struct {
+		Email    string `json:"email" binding:"required,email"`
+		Password string `json:"password" binding:"required"`
+		Name     string `json:"name" binding:"required"`
+		Phone    string `json:"phone"`
+		Locale   string `json:"locale"`
+	}
+	if err := ctx.ShouldBindJSON(&request); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if len(request.Password) < minPasswordLength {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("Password must be at least %d characters", minPasswordLength)})
+		return
+	}
+
+	hash, err := bcrypt.GenerateFromPassword([]byte(request.Password), bcrypt.DefaultCost)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create account"})
+		return
+	}
+
+	customer := &models.Customer{
+		Email:        normalizeEmail(request.Email),
+		PasswordHash: string(hash),
+		Name:         request.Name,
+		Phone:        request.Phone,
+		Locale:       requestLocale(ctx, request.Locale),
+	}
+
+	var existing int64
+	c.db.Model(&models.Customer{}).Where("email = ?", customer.Email).Count(&existing)
+	if existing > 0 {
+		ctx.JSON(http.StatusConflict, gin.H{"error": "An account with this email already exists"})
+		return
+	}
+	// The unique index on email settles a race with a concurrent sign-up
+	if err := c.db.Create(customer).Error; err != nil {
+		if errors.Is(err, gorm.ErrDuplicatedKey) {
+			ctx.JSON(http.StatusConflict, gin.H{"error": "An account with this email already exists"})
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create account"})
+		return
+	}
+
+	c.sendVerification(customer)
+	ctx.JSON(http.StatusCreated, customer)
+}
+
+// VerifyEmail marks the account's email as verified and attaches any guest
+// bookings made with that address
+func (c *AccountController) VerifyEmail(ctx *gin.Context) {
+	var request struct {
+		Token string `json:"token" binding:"required"`
+	}
+	if err := ctx.ShouldBindJSON(&request); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	var customer models.Customer
+	var claimed int64
+	err := c.db.Transaction(func(tx *gorm.DB) error {
+		customerID, err := consumeCustomerToken(tx, request.Token, models.TokenEmailVerification)
+		if err != nil {
+			return err
+		}
+		if err := tx.First(&customer, customerID).Error; err != nil {
+			return err
+		}
+		if customer.EmailVerifiedAt == nil {
+			now := time.Now()
+			customer.EmailVerifiedAt = &now
+			if err := tx.Save(&customer).Error; err != nil {
+				return err
+			}
+		}
+		claimed, err = claimGuestBookings(tx, &customer)
+		return err
+	})
+	if errors.Is(err, errInvalidToken) {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid or expired verification link"})
+		return
+	}
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to verify email"})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"customer": customer, "claimedBookings": claimed})
+}
+
+// ResendVerification sends a fresh verification email to the logged-in customer
+func (c *AccountController) ResendVerification(ctx *gin.Context) {
+	customer, ok := c.currentCustomer(ctx)
+	if !ok {
+		return
+	}
+	if customer.EmailVerifiedAt != nil {
+		ctx.JSON(http.StatusConflict, gin.H{"error": "Email is already verified"})
+		return
+	}
+
+	c.sendVerification(customer)
+	ctx.JSON(http.StatusOK, gin.H{"message": "Verification email sent"})
+}
+
+// Login exchanges email and password for an access token
+func (c *AccountController) Login(ctx *gin.Context) {
+	var request struct {
+		Email    string `json:"email" binding:"required,email"`
+		Password string `json:"password" binding:"required"`
+	}
+	if err := ctx.ShouldBindJSON(&request); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	accountKey := services.AccountKey("customer", request.Email)
+	ipKey := services.IPKey(ctx.ClientIP())
+	if throttled(ctx, c.throttle, accountKey, ipKey) {
+		return
+	}
+
+	var customer models.Customer
+	err := c.db.First(&customer, "email = ?", normalizeEmail(request.Email)).Error
+	if err != nil || bcrypt.CompareHashAndPassword([]byte(customer.PasswordHash), []byte(request.Password)) != nil {
+		c.throttle.RecordFailure(ctx.ClientIP(), accountKey, ipKey)
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid email or password"})
+		return
+	}
+	c.throttle.Reset(accountKey)
+
+	token, err := middlewares.GenerateToken(customer.ID, middlewares.RoleCustomer)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to issue token"})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"token": token, "customer": customer})
+}
+
+// ForgotPassword emails a password reset link. It responds the same way
+// whether or not the account exists.
+func (c *AccountController) ForgotPassword(ctx *gin.Context) {
+	var request struct {
+		Email string `json:"email" binding:"required,email"`
+	}
+	if err := ctx.ShouldBindJSON(&request); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	var customer models.Customer
+	if err := c.db.First(&customer, "email = ?", normalizeEmail(request.Email)).Error; err == nil {
+		err := c.db.Transaction(func(tx *gorm.DB) error {
+			token, err := issueCustomerToken(tx, customer.ID, models.TokenPasswordReset, passwordResetExpiry)
+			if err != nil {
+				return err
+			}
+			resetURL := fmt.Sprintf("%s/?reset=%s", services.FrontendURL(), url.QueryEscape(token))
+			return c.email.In(tx).SendPasswordReset(&customer, resetURL)
+		})
+		if err != nil {
+			log.Printf("Failed to send password reset email to customer %d: %v", customer.ID, err)
+		}
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"message": "If an account exists for this email, a reset link has been sent"})
+}
+
+// ResetPassword sets a new password using a reset token
+func (c *AccountController) ResetPassword(ctx *gin.Context) {
+	var request struct {
+		Token    string `json:"token" binding:"required"`
+		Password string `json:"password" binding:"required"`
+	}
+	if err := ctx.ShouldBindJSON(&request); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if len(request.Password) < minPasswordLength {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("Password must be at least %d characters", minPasswordLength)})
+		return
+	}
+
+	hash, err := bcrypt.GenerateFromPassword([]byte(request.Password), bcrypt.DefaultCost)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to reset password"})
+		return
+	}
+
+	var customer models.Customer
+	err = c.db.Transaction(func(tx *gorm.DB) error {
+		customerID, err := consumeCustomerToken(tx, request.Token, models.TokenPasswordReset)
+		if err != nil {
+			return err
+		}
+		if err := tx.First(&customer, customerID).Error; err != nil {
+			return err
+		}
+		return tx.Model(&customer).Update("password_hash", string(hash)).Error
+	})
+	if errors.Is(err, errInvalidToken) {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid or expired reset link"})
+		return
+	}
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to reset password"})
+		return
+	}
+
+	// A reset proves ownership of the mailbox, so lift any lockout.
+	c.throttle.Reset(services.AccountKey("customer", customer.Email))
+
+	ctx.JSON(http.StatusOK, gin.H{"message": "Password updated"})
+}
+
+// GetProfile returns the logged-in customer, used to pre-fill booking forms
+func (c *AccountController) GetProfile(ctx *gin.Context) {
+	customer, ok := c.currentCustomer(ctx)
+	if !ok {
+		return
+	}
+	ctx.JSON(http.StatusOK, customer)
+}
+
+// UpdateProfile updates the logged-in customer's name, phone and, when
+// given, the locale they are emailed in
+func (c *AccountController) UpdateProfile(ctx *gin.Context) {
+	customer, ok := c.currentCustomer(ctx)
+	if !ok {
+		return
+	}
+
+	var request struct {
+		Name   string `json:"name" binding:"required"`
+		Phone  string `json:"phone"`
+		Locale string `json:"locale"`
+	}
+	if err := ctx.ShouldBindJSON(&request); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if request.Locale != "" {
+		locale := services.MatchLocale(request.Locale)
+		if locale == "" {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": "Unsupported locale"})
+			return
+		}
+		customer.Locale = locale
+	}
+
+	customer.Name = request.Name
+	customer.Phone = request.Phone
+	if err := c.db.Save(customer).Error; err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update profile"})
+		return
+	}
+	ctx.JSON(http.StatusOK, customer)
+}
+
+// GetBookings returns the customer's bookings split into upcoming and past
+func (c *AccountController) GetBookings(ctx *gin.Context) {
+	customer, ok := c.currentCustomer(ctx)
+	if !ok {
+		return
+	}
+
+	var bookings []models.Booking
+	if err := c.db.Where("customer_id = ?", customer.ID).Order("event_date DESC").Find(&bookings).Error; err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch bookings"})
+		return
+	}
+
+	now := time.Now()
+	upcoming := []models.Booking{}
+	past := []models.Booking{}
+	for _, booking := range bookings {
+		if booking.StartsAt(services.VenueLocation()).After(now) {
+			upcoming = append(upcoming, booking)
+		} else {
+			past = append(past, booking)
+		}
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"upcoming": upcoming, "past": past})
+}
+
+// ClaimBookings attaches guest bookings made with the customer's verified
+// email to the account
+func (c *AccountController) ClaimBookings(ctx *gin.Context) {
+	customer, ok := c.currentCustomer(ctx)
+	if !ok {
+		return
+	}
+	if customer.EmailVerifiedAt == nil {
+		ctx.JSON(http.StatusForbidden, gin.H{"error": "Verify your email before claiming bookings"})
+		return
+	}
+
+	claimed, err := claimGuestBookings(c.db, customer)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to claim bookings"})
+		return
+	}
+	ctx.JSON(http.StatusOK, gin.H{"claimedBookings": claimed})
+}
+
+func (c *AccountController) currentCustomer(ctx *gin.Context) (*models.Customer, bool) {
+	var customer models.Customer
+	if err := c.db.First(&customer, ctx.GetUint("user_id")).Error; err != nil {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "Account not found"})
+		return nil, false
+	}
+	return &customer, true
+}
+
+func (c *AccountController) sendVerification(customer *models.Customer) {
+	err := c.db.Transaction(func(tx *gorm.DB) error {
+		token, err := issueCustomerToken(tx, customer.ID, models.TokenEmailVerification, emailVerificationExpiry)
+		if err != nil {
+			return err
+		}
+		verifyURL := fmt.Sprintf("%s/?verify=%s", services.FrontendURL(), url.QueryEscape(token))
+		return c.email.In(tx).SendEmailVerification(customer, verifyURL)
+	})
+	if err != nil {
+		log.Printf("Failed to send verification email to customer %d: %v", customer.ID, err)
+	}
+}
+
+func issueCustomerToken(db *gorm.DB, customerID uint, purpose models.TokenPurpose, ttl time.Duration) (string, error) {
+	token, hash, err := services.NewOpaqueToken()
+	if err != nil {
+		return "", err
+	}
+	err = db.Create(&models.CustomerToken{
+		CustomerID: customerID,
+		Purpose:    purpose,
+		TokenHash:  hash,
+		ExpiresAt:  time.Now().Add(ttl),
+	}).Error
+	return token, err
+}
+
+// consumeCustomerToken marks a token used and returns its customer. It
+// fails with errInvalidToken for unknown, used or expired tokens.
+func consumeCustomerToken(tx *gorm.DB, token string, purpose models.TokenPurpose) (uint, error) {
+	now := time.Now()
+	var stored models.CustomerToken
+	err := tx.Where("token_hash = ? AND purpose = ? AND used_at IS NULL AND expires_at > ?",
+		services.HashToken(token), purpose, now).First(&stored).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return 0, errInvalidToken
+	}
+	if err != nil {
+		return 0, err
+	}
+
+	result := tx.Model(&stored).Where("used_at IS NULL").Update("used_at", now)
+	if result.Error != nil {
+		return 0, result.Error
+	}
+	if result.RowsAffected == 0 {
+		return 0, errInvalidToken
+	}
+	return stored.CustomerID, nil
+}
+
+// claimGuestBookings attaches unowned bookings with the customer's email.
+func claimGuestBookings(db *gorm.DB, customer *models.Customer) (int64, error) {
+	result := db.Model(&models.Booking{}).
+		Where("customer_id IS NULL AND LOWER(customer_email) = ?", customer.Email).
+		Update("customer_id", customer.ID)
+	return result.RowsAffected, result.Error
+}
+
+func normalizeEmail(email string) string {
+	return strings.ToLower(strings.TrimSpace(email))
+}
@@ -0,0 +1,59 @@
+package controllers
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+
+	"event-booking-backend/models"
+	"event-booking-backend/services"
+)
+
+func newAccountRouter(t *testing.T, db *gorm.DB) *gin.Engine {
+	t.Helper()
+	notifier, err := services.NewLogNotifier("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	ac := NewAccountController(db, services.NewMailer(notifier, nil), services.NewLoginThrottle(db))
+	router := gin.New()
+	router.POST("/account/register", ac.Register)
+	return router
+}
+
+var registration = gin.H{"email": "Jo@Example.com", "password": "correct horse battery", "name": "Jo"}
+
+func TestRegisterExistingEmail(t *testing.T) {
+	db := newTestDB(t)
+	router := newAccountRouter(t, db)
+
+	if w := serve(router, http.MethodPost, "/account/register", registration); w.Code != http.StatusCreated {
+		t.Fatalf("first sign-up: got %d %s, want 201", w.Code, w.Body.String())
+	}
+	if w := serve(router, http.MethodPost, "/account/register", registration); w.Code != http.StatusConflict {
+		t.Errorf("second sign-up: got %d %s, want 409", w.Code, w.Body.String())
+	}
+}
+
+// TestRegisterRace signs up while another request creates the same account
+// between the existence check and the insert.
+func TestRegisterRace(t *testing.T) {
+	db := newTestDB(t)
+	router := newAccountRouter(t, db)
+	raced := false
+	err := db.Callback().Create().Before("gorm:create").Register("test:race", func(tx *gorm.DB) {
+		if _, ok := tx.Statement.Dest.(*models.Customer); ok && !raced {
+			raced = true
+			tx.Exec("INSERT INTO customers (email, password_hash, name) VALUES (?, 'x', 'Other')", "jo@example.com")
+		}
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if w := serve(router, http.MethodPost, "/account/register", registration); w.Code != http.StatusConflict {
+		t.Errorf("got %d %s, want 409", w.Code, w.Body.String())
+	}
+}
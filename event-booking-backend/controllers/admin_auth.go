@@ -0,0 +1,298 @@
+package controllers
+
+import (
+	"errors"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"golang.org/x/crypto/bcrypt"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"event-booking-backend/middlewares"
+	"event-booking-backend/models"
+	"event-booking-backend/services"
+)
+
+const (
+	adminAccessTokenTTL  = 15 * time.Minute
+	adminRefreshTokenTTL = 7 * 24 * time.Hour
+)
+
+var errRefreshTokenReused = errors.New("refresh token reused")
+
+// AdminAuthController signs admin users in and out and manages their
+// sessions.
+type AdminAuthController struct {
+	db       *gorm.DB
+	throttle *services.LoginThrottle
+}
+
+func NewAdminAuthController(db *gorm.DB, throttle *services.LoginThrottle) *AdminAuthController {
+	return &AdminAuthController{
+		db:       db,
+		throttle: throttle,
+	}
+}
+
+// adminTokens is the response to a successful login or refresh.
+type adminTokens struct {
+	AccessToken  string           `json:"accessToken"`
+	RefreshToken string           `json:"refreshToken"`
+	ExpiresIn    int              `json:"expiresIn"`
+	User         models.AdminUser `json:"user"`
+	// MFAEnrollmentRequired tells the client to send the user through 2FA
+	// setup; other admin routes are refused until they do.
+	MFAEnrollmentRequired bool `json:"mfaEnrollmentRequired,omitempty"`
+}
+
+// Login exchanges email and password for an access and refresh token pair
+func (c *AdminAuthController) Login(ctx *gin.Context) {
+	var request struct {
+		Email    string `json:"email" binding:"required,email"`
+		Password string `json:"password" binding:"required"`
+	}
+	if err := ctx.ShouldBindJSON(&request); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	accountKey := services.AccountKey("admin", request.Email)
+	ipKey := services.IPKey(ctx.ClientIP())
+	if throttled(ctx, c.throttle, accountKey, ipKey) {
+		return
+	}
+
+	var user models.AdminUser
+	err := c.db.First(&user, "email = ?", normalizeEmail(request.Email)).Error
+	if err != nil || bcrypt.CompareHashAndPassword([]byte(user.PasswordHash), []byte(request.Password)) != nil {
+		c.throttle.RecordFailure(ctx.ClientIP(), accountKey, ipKey)
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid email or password"})
+		return
+	}
+	if !user.Active {
+		ctx.JSON(http.StatusForbidden, gin.H{"error": "Account is disabled"})
+		return
+	}
+
+	if user.TOTPEnabled() {
+		// Failures keep counting against the account until the second
+		// factor has been passed too.
+		challenge, err := c.startMFAChallenge(&user)
+		if err != nil {
+			ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to start login"})
+			return
+		}
+		ctx.JSON(http.StatusOK, challenge)
+		return
+	}
+
+	c.throttle.Reset(accountKey)
+	tokens, err := c.startSession(ctx, &user)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to start session"})
+		return
+	}
+	ctx.JSON(http.StatusOK, tokens)
+}
+
+// Refresh rotates the refresh token and issues a new access token. Presenting
+// an already-rotated refresh token revokes the whole session, since it means
+// the token was copied.
+func (c *AdminAuthController) Refresh(ctx *gin.Context) {
+	var request struct {
+		RefreshToken string `json:"refreshToken" binding:"required"`
+	}
+	if err := ctx.ShouldBindJSON(&request); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	ipKey := services.IPKey(ctx.ClientIP())
+	if throttled(ctx, c.throttle, ipKey) {
+		return
+	}
+
+	hash := services.HashToken(request.RefreshToken)
+	var tokens *adminTokens
+	var reusedSessionID string
+	err := c.db.Transaction(func(tx *gorm.DB) error {
+		var session models.AdminSession
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("refresh_token_hash = ? OR previous_token_hash = ?", hash, hash).
+			First(&session).Error
+		if err != nil {
+			return errInvalidToken
+		}
+		if session.RevokedAt != nil || session.ExpiresAt.Before(time.Now()) {
+			return errInvalidToken
+		}
+		if session.RefreshTokenHash != hash {
+			reusedSessionID = session.ID
+			return errRefreshTokenReused
+		}
+
+		var user models.AdminUser
+		if err := tx.First(&user, session.AdminUserID).Error; err != nil || !user.Active {
+			return errInvalidToken
+		}
+
+		refreshToken, refreshHash, err := services.NewOpaqueToken()
+		if err != nil {
+			return err
+		}
+		err = tx.Model(&session).Updates(map[string]interface{}{
+			"previous_token_hash": session.RefreshTokenHash,
+			"refresh_token_hash":  refreshHash,
+			"last_used_at":        time.Now(),
+		}).Error
+		if err != nil {
+			return err
+		}
+
+		tokens, err = issueAdminTokens(&user, session.ID, refreshToken)
+		if err == nil {
+			tokens.MFAEnrollmentRequired = middlewares.MFAEnrollmentRequired(tx, &user)
+		}
+		return err
+	})
+	switch {
+	case errors.Is(err, errRefreshTokenReused):
+		// Revoke after the rollback so the revocation sticks.
+		if err := revokeAdminSessions(c.db.Where("id = ?", reusedSessionID)); err != nil {
+			log.Printf("Failed to revoke reused admin session %s: %v", reusedSessionID, err)
+		}
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid or expired refresh token"})
+	case errors.Is(err, errInvalidToken):
+		c.throttle.RecordFailure(ctx.ClientIP(), ipKey)
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid or expired refresh token"})
+	case err != nil:
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to refresh session"})
+	default:
+		ctx.JSON(http.StatusOK, tokens)
+	}
+}
+
+// Logout revokes the current session
+func (c *AdminAuthController) Logout(ctx *gin.Context) {
+	admin := middlewares.CurrentAdmin(ctx)
+	if err := revokeAdminSessions(c.db.Where("id = ?", admin.SessionID)); err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to log out"})
+		return
+	}
+	ctx.JSON(http.StatusOK, gin.H{"message": "Logged out"})
+}
+
+// LogoutAll revokes every session of the current admin user
+func (c *AdminAuthController) LogoutAll(ctx *gin.Context) {
+	admin := middlewares.CurrentAdmin(ctx)
+	err := c.db.Transaction(func(tx *gorm.DB) error {
+		if err := revokeAdminSessions(tx.Where("admin_user_id = ?", admin.UserID)); err != nil {
+			return err
+		}
+		return recordAudit(tx, ctx, "admin_session.revoked_all", "admin_user", userEntityID(admin.UserID), nil, nil)
+	})
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to log out"})
+		return
+	}
+	ctx.JSON(http.StatusOK, gin.H{"message": "Logged out of all sessions"})
+}
+
+// Me returns the signed-in admin user and what their role allows
+func (c *AdminAuthController) Me(ctx *gin.Context) {
+	var user models.AdminUser
+	if err := c.db.First(&user, middlewares.CurrentAdmin(ctx).UserID).Error; err != nil {
+		ctx.JSON(http.StatusNotFound, gin.H{"error": "Admin user not found"})
+		return
+	}
+	ctx.JSON(http.StatusOK, gin.H{"user": user, "permissions": middlewares.PermissionsFor(user.Role)})
+}
+
+// ListSessions returns the current admin user's live sessions
+func (c *AdminAuthController) ListSessions(ctx *gin.Context) {
+	var sessions []models.AdminSession
+	err := c.db.Where("admin_user_id = ? AND revoked_at IS NULL AND expires_at > ?",
+		middlewares.CurrentAdmin(ctx).UserID, time.Now()).
+		Order("last_used_at DESC").Find(&sessions).Error
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch sessions"})
+		return
+	}
+	ctx.JSON(http.StatusOK, sessions)
+}
+
+// RevokeSession revokes one of the current admin user's sessions
+func (c *AdminAuthController) RevokeSession(ctx *gin.Context) {
+	err := c.db.Transaction(func(tx *gorm.DB) error {
+		query := tx.Where("id = ? AND admin_user_id = ?", ctx.Param("id"), middlewares.CurrentAdmin(ctx).UserID)
+		if err := revokeAdminSessions(query); err != nil {
+			return err
+		}
+		return recordAudit(tx, ctx, "admin_session.revoked", "admin_session", ctx.Param("id"), nil, nil)
+	})
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to revoke session"})
+		return
+	}
+	ctx.JSON(http.StatusOK, gin.H{"message": "Session revoked"})
+}
+
+func (c *AdminAuthController) startSession(ctx *gin.Context, user *models.AdminUser) (*adminTokens, error) {
+	sessionID, _, err := services.NewOpaqueToken()
+	if err != nil {
+		return nil, err
+	}
+	refreshToken, refreshHash, err := services.NewOpaqueToken()
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	session := &models.AdminSession{
+		ID:               sessionID,
+		AdminUserID:      user.ID,
+		RefreshTokenHash: refreshHash,
+		IP:               ctx.ClientIP(),
+		UserAgent:        ctx.Request.UserAgent(),
+		ExpiresAt:        now.Add(adminRefreshTokenTTL),
+		LastUsedAt:       now,
+	}
+	if err := c.db.Create(session).Error; err != nil {
+		return nil, err
+	}
+
+	user.LastLoginAt = &now
+	if err := c.db.Model(user).Update("last_login_at", now).Error; err != nil {
+		return nil, err
+	}
+
+	tokens, err := issueAdminTokens(user, session.ID, refreshToken)
+	if err != nil {
+		return nil, err
+	}
+	tokens.MFAEnrollmentRequired = middlewares.MFAEnrollmentRequired(c.db, user)
+	return tokens, nil
+}
+
+func issueAdminTokens(user *models.AdminUser, sessionID, refreshToken string) (*adminTokens, error) {
+	accessToken, err := middlewares.GenerateSessionToken(user.ID, user.Role, sessionID, adminAccessTokenTTL)
+	if err != nil {
+		return nil, err
+	}
+	return &adminTokens{
+		AccessToken:  accessToken,
+		RefreshToken: refreshToken,
+		ExpiresIn:    int(adminAccessTokenTTL.Seconds()),
+		User:         *user,
+	}, nil
+}
+
+// revokeAdminSessions revokes the live sessions matched by query.
+func revokeAdminSessions(query *gorm.DB) error {
+	return query.Model(&models.AdminSession{}).
+		Where("revoked_at IS NULL").
+		Update("revoked_at", time.Now()).Error
+}
@@ -0,0 +1,454 @@
+package controllers
+
+import (
+	"errors"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"event-booking-backend/middlewares"
+	"event-booking-backend/models"
+	"event-booking-backend/services"
+)
+
+const (
+	mfaChallengeTTL         = 5 * time.Minute
+	mfaChallengeMaxAttempts = 5
+	recoveryCodeCount       = 10
+)
+
+var errInvalidCode = errors.New("invalid code")
+
+// mfaChallenge is returned by Login instead of tokens when the user has 2FA.
+type mfaChallenge struct {
+	MFARequired bool   `json:"mfaRequired"`
+	MFAToken    string `json:"mfaToken"`
+	ExpiresIn   int    `json:"expiresIn"`
+}
+
+// VerifyLoginMFA completes a login with a TOTP or recovery code
+func (c *AdminAuthController) VerifyLoginMFA(ctx *gin.Context) {
+	var request struct {
+		MFAToken     string `json:"mfaToken" binding:"required"`
+		Code         string `json:"code"`
+		RecoveryCode string `json:"recoveryCode"`
+	}
+	if err := ctx.ShouldBindJSON(&request); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if request.Code == "" && request.RecoveryCode == "" {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "code or recoveryCode is required"})
+		return
+	}
+
+	ipKey := services.IPKey(ctx.ClientIP())
+	if throttled(ctx, c.throttle, ipKey) {
+		return
+	}
+
+	var user models.AdminUser
+	verified := false
+	err := c.db.Transaction(func(tx *gorm.DB) error {
+		var challenge models.AdminLoginChallenge
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("token_hash = ? AND expires_at > ?", services.HashToken(request.MFAToken), time.Now()).
+			First(&challenge).Error
+		if err != nil || challenge.Attempts >= mfaChallengeMaxAttempts {
+			return errInvalidToken
+		}
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&user, challenge.AdminUserID).Error; err != nil {
+			return errInvalidToken
+		}
+
+		if request.RecoveryCode != "" {
+			err = useRecoveryCode(tx, &user, request.RecoveryCode)
+		} else {
+			err = acceptTOTPCode(tx, &user, request.Code)
+		}
+		if errors.Is(err, errInvalidCode) {
+			// Count the failed attempt; the challenge survives the error.
+			return tx.Model(&challenge).Update("attempts", challenge.Attempts+1).Error
+		}
+		if err != nil {
+			return err
+		}
+		verified = true
+		return tx.Delete(&challenge).Error
+	})
+	switch {
+	case errors.Is(err, errInvalidToken):
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "Login expired, please sign in again"})
+		return
+	case err != nil:
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to verify code"})
+		return
+	case !verified:
+		c.throttle.RecordFailure(ctx.ClientIP(), services.AccountKey("admin", user.Email), ipKey)
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid code"})
+		return
+	}
+
+	if !user.Active {
+		ctx.JSON(http.StatusForbidden, gin.H{"error": "Account is disabled"})
+		return
+	}
+
+	c.throttle.Reset(services.AccountKey("admin", user.Email))
+	tokens, err := c.startSession(ctx, &user)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to start session"})
+		return
+	}
+	ctx.JSON(http.StatusOK, tokens)
+}
+
+// SetupTOTP starts enrollment by generating a secret. It is not in force
+// until confirmed with a code.
+func (c *AdminAuthController) SetupTOTP(ctx *gin.Context) {
+	var user models.AdminUser
+	if err := c.db.First(&user, middlewares.CurrentAdmin(ctx).UserID).Error; err != nil {
+		ctx.JSON(http.StatusNotFound, gin.H{"error": "Admin user not found"})
+		return
+	}
+	if user.TOTPEnabled() {
+		ctx.JSON(http.StatusConflict, gin.H{"error": "Two-factor authentication is already enabled"})
+		return
+	}
+
+	secret, err := services.NewTOTPSecret()
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to generate secret"})
+		return
+	}
+	if err := c.db.Model(&user).Update("totp_secret", secret).Error; err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to start enrollment"})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{
+		"secret":          secret,
+		"provisioningUri": services.TOTPProvisioningURI(totpIssuer(), user.Email, secret),
+	})
+}
+
+// ConfirmTOTP enables 2FA once the user proves their authenticator works,
+// and returns a fresh set of recovery codes
+func (c *AdminAuthController) ConfirmTOTP(ctx *gin.Context) {
+	var request struct {
+		Code string `json:"code" binding:"required"`
+	}
+	if err := ctx.ShouldBindJSON(&request); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	admin := middlewares.CurrentAdmin(ctx)
+	accountKey, ipKey := services.AccountKey("admin", admin.Email), services.IPKey(ctx.ClientIP())
+	if throttled(ctx, c.throttle, accountKey, ipKey) {
+		return
+	}
+
+	var codes []string
+	err := c.db.Transaction(func(tx *gorm.DB) error {
+		var user models.AdminUser
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&user, admin.UserID).Error; err != nil {
+			return err
+		}
+		if user.TOTPSecret == "" || user.TOTPEnabled() {
+			return errInvalidToken
+		}
+		if err := acceptTOTPCode(tx, &user, request.Code); err != nil {
+			return err
+		}
+		if err := tx.Model(&user).Update("totp_enabled_at", time.Now()).Error; err != nil {
+			return err
+		}
+		if err := recordAudit(tx, ctx, "admin_user.2fa_enabled", "admin_user", userEntityID(user.ID), nil, nil); err != nil {
+			return err
+		}
+
+		var err error
+		codes, err = replaceRecoveryCodes(tx, user.ID)
+		return err
+	})
+	switch {
+	case errors.Is(err, errInvalidToken):
+		ctx.JSON(http.StatusConflict, gin.H{"error": "Start two-factor setup first"})
+	case errors.Is(err, errInvalidCode):
+		c.throttle.RecordFailure(ctx.ClientIP(), accountKey, ipKey)
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid code"})
+	case err != nil:
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to enable two-factor authentication"})
+	default:
+		c.throttle.Reset(accountKey)
+		ctx.JSON(http.StatusOK, gin.H{"recoveryCodes": codes})
+	}
+}
+
+// DisableTOTP turns 2FA off for the current user, unless their role
+// requires it
+func (c *AdminAuthController) DisableTOTP(ctx *gin.Context) {
+	var request struct {
+		Code string `json:"code" binding:"required"`
+	}
+	if err := ctx.ShouldBindJSON(&request); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	admin := middlewares.CurrentAdmin(ctx)
+	if middlewares.MFARequiredForRole(c.db, admin.Role) {
+		ctx.JSON(http.StatusForbidden, gin.H{"error": "Two-factor authentication is required for your role"})
+		return
+	}
+	accountKey, ipKey := services.AccountKey("admin", admin.Email), services.IPKey(ctx.ClientIP())
+	if throttled(ctx, c.throttle, accountKey, ipKey) {
+		return
+	}
+
+	err := c.db.Transaction(func(tx *gorm.DB) error {
+		var user models.AdminUser
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&user, admin.UserID).Error; err != nil {
+			return err
+		}
+		if !user.TOTPEnabled() {
+			return errInvalidToken
+		}
+		if err := acceptTOTPCode(tx, &user, request.Code); err != nil {
+			return err
+		}
+		if err := recordAudit(tx, ctx, "admin_user.2fa_disabled", "admin_user", userEntityID(user.ID), nil, nil); err != nil {
+			return err
+		}
+		return clearTOTP(tx, user.ID)
+	})
+	switch {
+	case errors.Is(err, errInvalidToken):
+		ctx.JSON(http.StatusConflict, gin.H{"error": "Two-factor authentication is not enabled"})
+	case errors.Is(err, errInvalidCode):
+		c.throttle.RecordFailure(ctx.ClientIP(), accountKey, ipKey)
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid code"})
+	case err != nil:
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to disable two-factor authentication"})
+	default:
+		c.throttle.Reset(accountKey)
+		ctx.JSON(http.StatusOK, gin.H{"message": "Two-factor authentication disabled"})
+	}
+}
+
+// RegenerateRecoveryCodes replaces the current user's recovery codes
+func (c *AdminAuthController) RegenerateRecoveryCodes(ctx *gin.Context) {
+	var request struct {
+		Code string `json:"code" binding:"required"`
+	}
+	if err := ctx.ShouldBindJSON(&request); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	admin := middlewares.CurrentAdmin(ctx)
+	accountKey, ipKey := services.AccountKey("admin", admin.Email), services.IPKey(ctx.ClientIP())
+	if throttled(ctx, c.throttle, accountKey, ipKey) {
+		return
+	}
+
+	var codes []string
+	err := c.db.Transaction(func(tx *gorm.DB) error {
+		var user models.AdminUser
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&user, admin.UserID).Error; err != nil {
+			return err
+		}
+		if !user.TOTPEnabled() {
+			return errInvalidToken
+		}
+		if err := acceptTOTPCode(tx, &user, request.Code); err != nil {
+			return err
+		}
+		if err := recordAudit(tx, ctx, "admin_user.recovery_codes_regenerated", "admin_user", userEntityID(user.ID), nil, nil); err != nil {
+			return err
+		}
+
+		var err error
+		codes, err = replaceRecoveryCodes(tx, user.ID)
+		return err
+	})
+	switch {
+	case errors.Is(err, errInvalidToken):
+		ctx.JSON(http.StatusConflict, gin.H{"error": "Two-factor authentication is not enabled"})
+	case errors.Is(err, errInvalidCode):
+		c.throttle.RecordFailure(ctx.ClientIP(), accountKey, ipKey)
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid code"})
+	case err != nil:
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to regenerate recovery codes"})
+	default:
+		c.throttle.Reset(accountKey)
+		ctx.JSON(http.StatusOK, gin.H{"recoveryCodes": codes})
+	}
+}
+
+// GetMFAPolicy returns which roles must use 2FA
+func (c *AdminUserController) GetMFAPolicy(ctx *gin.Context) {
+	var policies []models.MFAPolicy
+	if err := c.db.Order("role").Find(&policies).Error; err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch 2FA policy"})
+		return
+	}
+	ctx.JSON(http.StatusOK, policies)
+}
+
+// UpdateMFAPolicy sets whether a role must use 2FA
+func (c *AdminUserController) UpdateMFAPolicy(ctx *gin.Context) {
+	role := ctx.Param("role")
+	if !middlewares.ValidRole(role) {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid role"})
+		return
+	}
+
+	var request struct {
+		Required *bool `json:"required" binding:"required"`
+	}
+	if err := ctx.ShouldBindJSON(&request); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	policy := models.MFAPolicy{Role: role, Required: *request.Required}
+	err := c.db.Transaction(func(tx *gorm.DB) error {
+		var before *models.MFAPolicy
+		var existing models.MFAPolicy
+		if tx.First(&existing, "role = ?", role).Error == nil {
+			before = &existing
+		}
+		if err := tx.Save(&policy).Error; err != nil {
+			return err
+		}
+		return recordAudit(tx, ctx, "mfa_policy.updated", "mfa_policy", role, before, policy)
+	})
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update 2FA policy"})
+		return
+	}
+	ctx.JSON(http.StatusOK, policy)
+}
+
+// ResetUserTOTP removes 2FA from a user who lost their authenticator and
+// signs them out. If their role requires 2FA they must enroll again.
+func (c *AdminUserController) ResetUserTOTP(ctx *gin.Context) {
+	id, err := strconv.ParseUint(ctx.Param("id"), 10, 64)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID"})
+		return
+	}
+
+	err = c.db.Transaction(func(tx *gorm.DB) error {
+		var user models.AdminUser
+		if err := tx.First(&user, id).Error; err != nil {
+			return err
+		}
+		if err := clearTOTP(tx, user.ID); err != nil {
+			return err
+		}
+		if err := recordAudit(tx, ctx, "admin_user.2fa_reset", "admin_user", userEntityID(user.ID), nil, nil); err != nil {
+			return err
+		}
+		return revokeAdminSessions(tx.Where("admin_user_id = ?", user.ID))
+	})
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		ctx.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+		return
+	}
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to reset two-factor authentication"})
+		return
+	}
+	ctx.JSON(http.StatusOK, gin.H{"message": "Two-factor authentication reset"})
+}
+
+// startMFAChallenge records the first login step and returns the token for
+// the second.
+func (c *AdminAuthController) startMFAChallenge(user *models.AdminUser) (*mfaChallenge, error) {
+	token, hash, err := services.NewOpaqueToken()
+	if err != nil {
+		return nil, err
+	}
+	err = c.db.Create(&models.AdminLoginChallenge{
+		TokenHash:   hash,
+		AdminUserID: user.ID,
+		ExpiresAt:   time.Now().Add(mfaChallengeTTL),
+	}).Error
+	if err != nil {
+		return nil, err
+	}
+	return &mfaChallenge{
+		MFARequired: true,
+		MFAToken:    token,
+		ExpiresIn:   int(mfaChallengeTTL.Seconds()),
+	}, nil
+}
+
+// acceptTOTPCode checks code against the user's secret and records its time
+// step so it cannot be used again. The user row must be locked by tx.
+func acceptTOTPCode(tx *gorm.DB, user *models.AdminUser, code string) error {
+	step, ok := services.VerifyTOTP(user.TOTPSecret, code, time.Now())
+	if !ok || step <= user.TOTPLastStep {
+		return errInvalidCode
+	}
+	user.TOTPLastStep = step
+	return tx.Model(user).Update("totp_last_step", step).Error
+}
+
+func useRecoveryCode(tx *gorm.DB, user *models.AdminUser, code string) error {
+	hash := services.HashToken(strings.ToLower(strings.TrimSpace(code)))
+	result := tx.Model(&models.AdminRecoveryCode{}).
+		Where("admin_user_id = ? AND code_hash = ? AND used_at IS NULL", user.ID, hash).
+		Update("used_at", time.Now())
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return errInvalidCode
+	}
+	return nil
+}
+
+func replaceRecoveryCodes(tx *gorm.DB, userID uint) ([]string, error) {
+	codes, err := services.NewRecoveryCodes(recoveryCodeCount)
+	if err != nil {
+		return nil, err
+	}
+	if err := tx.Where("admin_user_id = ?", userID).Delete(&models.AdminRecoveryCode{}).Error; err != nil {
+		return nil, err
+	}
+	for _, code := range codes {
+		if err := tx.Create(&models.AdminRecoveryCode{AdminUserID: userID, CodeHash: services.HashToken(code)}).Error; err != nil {
+			return nil, err
+		}
+	}
+	return codes, nil
+}
+
+func clearTOTP(tx *gorm.DB, userID uint) error {
+	err := tx.Model(&models.AdminUser{}).Where("id = ?", userID).Updates(map[string]interface{}{
+		"totp_secret":     "",
+		"totp_enabled_at": nil,
+		"totp_last_step":  0,
+	}).Error
+	if err != nil {
+		return err
+	}
+	return tx.Where("admin_user_id = ?", userID).Delete(&models.AdminRecoveryCode{}).Error
+}
+
+func totpIssuer() string {
+	if issuer := os.Getenv("TOTP_ISSUER"); issuer != "" {
+		return issuer
+	}
+	return "Event Booking"
+}
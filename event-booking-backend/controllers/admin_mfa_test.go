@@ -0,0 +1,76 @@
+package controllers
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"event-booking-backend/middlewares"
+	"event-booking-backend/models"
+	"event-booking-backend/services"
+)
+
+// TestTOTPCodeChecksAreThrottled makes sure a stolen session cannot guess
+// its way past the code that guards turning 2FA on or off or minting
+// recovery codes.
+func TestTOTPCodeChecksAreThrottled(t *testing.T) {
+	for _, tt := range []struct {
+		path    string
+		enabled bool
+	}{
+		{"/admin/2fa/confirm", false},
+		{"/admin/2fa/disable", true},
+		{"/admin/2fa/recovery-codes", true},
+	} {
+		path := tt.path
+		t.Run(path, func(t *testing.T) {
+			db := newTestDB(t)
+			var enabled *time.Time
+			if tt.enabled {
+				now := time.Now()
+				enabled = &now
+			}
+			user := models.AdminUser{
+				Email:         "ops@example.com",
+				Name:          "Ops",
+				PasswordHash:  "x",
+				Role:          "manager",
+				Active:        true,
+				TOTPSecret:    "JBSWY3DPEHPK3PXP",
+				TOTPEnabledAt: enabled,
+			}
+			if err := db.Create(&user).Error; err != nil {
+				t.Fatal(err)
+			}
+			ac := NewAdminAuthController(db, services.NewLoginThrottle(db))
+			router := gin.New()
+			router.Use(func(ctx *gin.Context) {
+				ctx.Set("admin", &middlewares.AdminIdentity{UserID: user.ID, Email: user.Email, Role: user.Role})
+			})
+			router.POST("/admin/2fa/confirm", ac.ConfirmTOTP)
+			router.POST("/admin/2fa/disable", ac.DisableTOTP)
+			router.POST("/admin/2fa/recovery-codes", ac.RegenerateRecoveryCodes)
+
+			// The first few failures are free; after that each attempt waits
+			for i := 0; i < 4; i++ {
+				if w := serve(router, http.MethodPost, path, gin.H{"code": "nope!!"}); w.Code != http.StatusBadRequest {
+					t.Fatalf("attempt %d: got %d %s, want 400", i+1, w.Code, w.Body.String())
+				}
+			}
+			w := serve(router, http.MethodPost, path, gin.H{"code": "nope!!"})
+			if w.Code != http.StatusTooManyRequests || w.Header().Get("Retry-After") == "" {
+				t.Fatalf("after 4 failures: got %d %s, want 429 with Retry-After", w.Code, w.Body.String())
+			}
+
+			var row models.LoginThrottle
+			if err := db.First(&row, "key = ?", services.AccountKey("admin", user.Email)).Error; err != nil {
+				t.Fatal(err)
+			}
+			if row.Failures != 4 {
+				t.Errorf("%d failures recorded, want 4", row.Failures)
+			}
+		})
+	}
+}
@@ -0,0 +1,210 @@
+package controllers
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"golang.org/x/crypto/bcrypt"
+	"gorm.io/gorm"
+
+	"event-booking-backend/middlewares"
+	"event-booking-backend/models"
+)
+
+// AdminUserController lets owners manage staff accounts.
+type AdminUserController struct {
+	db *gorm.DB
+}
+
+func NewAdminUserController(db *gorm.DB) *AdminUserController {
+	return &AdminUserController{db: db}
+}
+
+// ListUsers returns all admin users
+func (c *AdminUserController) ListUsers(ctx *gin.Context) {
+	var users []models.AdminUser
+	if err := c.db.Order("email").Find(&users).Error; err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch users"})
+		return
+	}
+	ctx.JSON(http.StatusOK, users)
+}
+
+// CreateUser adds a staff account
+func (c *AdminUserController) CreateUser(ctx *gin.Context) {
+	var request struct {
+		Email    string `json:"email" binding:"required,email"`
+		Name     string `json:"name" binding:"required"`
+		Password string `json:"password" binding:"required"`
+		Role     string `json:"role" binding:"required"`
+	}
+	if err := ctx.ShouldBindJSON(&request); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if !middlewares.ValidRole(request.Role) {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid role"})
+		return
+	}
+	if len(request.Password) < minPasswordLength {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("Password must be at least %d characters", minPasswordLength)})
+		return
+	}
+
+	hash, err := bcrypt.GenerateFromPassword([]byte(request.Password), bcrypt.DefaultCost)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create user"})
+		return
+	}
+
+	user := &models.AdminUser{
+		Email:        normalizeEmail(request.Email),
+		Name:         request.Name,
+		PasswordHash: string(hash),
+		Role:         request.Role,
+		Active:       true,
+	}
+
+	var existing int64
+	c.db.Model(&models.AdminUser{}).Where("email = ?", user.Email).Count(&existing)
+	if existing > 0 {
+		ctx.JSON(http.StatusConflict, gin.H{"error": "A user with this email already exists"})
+		return
+	}
+	err = c.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(user).Error; err != nil {
+			return err
+		}
+		return recordAudit(tx, ctx, "admin_user.created", "admin_user", userEntityID(user.ID), nil, user)
+	})
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create user"})
+		return
+	}
+	ctx.JSON(http.StatusCreated, user)
+}
+
+// UpdateUser changes a user's name, role or active flag. Changing the role
+// or deactivating the user signs them out everywhere.
+func (c *AdminUserController) UpdateUser(ctx *gin.Context) {
+	id, err := strconv.ParseUint(ctx.Param("id"), 10, 64)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID"})
+		return
+	}
+
+	var request struct {
+		Name   *string `json:"name"`
+		Role   *string `json:"role"`
+		Active *bool   `json:"active"`
+	}
+	if err := ctx.ShouldBindJSON(&request); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	var user models.AdminUser
+	if err := c.db.First(&user, id).Error; err != nil {
+		ctx.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+		return
+	}
+
+	before := user
+	self := middlewares.CurrentAdmin(ctx).UserID == user.ID
+	revoke := false
+	if request.Name != nil {
+		user.Name = *request.Name
+	}
+	if request.Role != nil && *request.Role != user.Role {
+		if !middlewares.ValidRole(*request.Role) {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid role"})
+			return
+		}
+		if self {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": "You cannot change your own role"})
+			return
+		}
+		user.Role = *request.Role
+		revoke = true
+	}
+	if request.Active != nil && *request.Active != user.Active {
+		if self {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": "You cannot deactivate your own account"})
+			return
+		}
+		user.Active = *request.Active
+		revoke = revoke || !user.Active
+	}
+
+	err = c.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Save(&user).Error; err != nil {
+			return err
+		}
+		if err := recordAudit(tx, ctx, "admin_user.updated", "admin_user", userEntityID(user.ID), before, user); err != nil {
+			return err
+		}
+		if revoke {
+			return revokeAdminSessions(tx.Where("admin_user_id = ?", user.ID))
+		}
+		return nil
+	})
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update user"})
+		return
+	}
+	ctx.JSON(http.StatusOK, user)
+}
+
+// ResetUserPassword sets a new password for a user and signs them out
+func (c *AdminUserController) ResetUserPassword(ctx *gin.Context) {
+	id, err := strconv.ParseUint(ctx.Param("id"), 10, 64)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID"})
+		return
+	}
+
+	var request struct {
+		Password string `json:"password" binding:"required"`
+	}
+	if err := ctx.ShouldBindJSON(&request); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if len(request.Password) < minPasswordLength {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("Password must be at least %d characters", minPasswordLength)})
+		return
+	}
+
+	hash, err := bcrypt.GenerateFromPassword([]byte(request.Password), bcrypt.DefaultCost)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to reset password"})
+		return
+	}
+
+	var user models.AdminUser
+	if err := c.db.First(&user, id).Error; err != nil {
+		ctx.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+		return
+	}
+
+	err = c.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&user).Update("password_hash", string(hash)).Error; err != nil {
+			return err
+		}
+		if err := recordAudit(tx, ctx, "admin_user.password_reset", "admin_user", userEntityID(user.ID), nil, nil); err != nil {
+			return err
+		}
+		return revokeAdminSessions(tx.Where("admin_user_id = ?", user.ID))
+	})
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to reset password"})
+		return
+	}
+	ctx.JSON(http.StatusOK, gin.H{"message": "Password updated"})
+}
+
+func userEntityID(id uint) string {
+	return strconv.FormatUint(uint64(id), 10)
+}
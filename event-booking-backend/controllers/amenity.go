@@ -0,0 +1,154 @@
+package controllers
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+
+	"event-booking-backend/models"
+)
+
+// AmenityController serves the amenity catalogue publicly and lets
+// managers maintain it. Which halls offer what is set on the halls.
+type AmenityController struct {
+	db *gorm.DB
+}
+
+func NewAmenityController(db *gorm.DB) *AmenityController {
+	return &AmenityController{db: db}
+}
+
+type amenityRequest struct {
+	ID       string `json:"id"`
+	Name     string `json:"name" binding:"required"`
+	Category string `json:"category"`
+	Icon     string `json:"icon"`
+}
+
+// apply copies the request onto amenity, defaulting the category.
+func (r amenityRequest) apply(amenity *models.Amenity) {
+	amenity.Name = strings.TrimSpace(r.Name)
+	amenity.Category = models.Slugify(r.Category)
+	if amenity.Category == "" {
+		amenity.Category = "general"
+	}
+	amenity.Icon = strings.TrimSpace(r.Icon)
+}
+
+// ListAmenities returns the catalogue, by category then name.
+func (c *AmenityController) ListAmenities(ctx *gin.Context) {
+	var amenities []models.Amenity
+	if err := c.db.Order("category, name").Find(&amenities).Error; err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch amenities"})
+		return
+	}
+	ctx.JSON(http.StatusOK, amenities)
+}
+
+// CreateAmenity adds an amenity to the catalogue (admin only). The ID is
+// made from the name unless one is given.
+func (c *AmenityController) CreateAmenity(ctx *gin.Context) {
+	var request amenityRequest
+	if err := ctx.ShouldBindJSON(&request); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	var amenity models.Amenity
+	request.apply(&amenity)
+	amenity.ID = models.Slugify(request.ID)
+	if amenity.ID == "" {
+		amenity.ID = models.Slugify(amenity.Name)
+	}
+	if amenity.ID == "" || amenity.Name == "" {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Name must contain letters or digits"})
+		return
+	}
+
+	err := c.db.Transaction(func(tx *gorm.DB) error {
+		var count int64
+		if err := tx.Model(&models.Amenity{}).Where("id = ?", amenity.ID).Count(&count).Error; err != nil {
+			return err
+		}
+		if count > 0 {
+			return errAmenityExists
+		}
+		if err := tx.Create(&amenity).Error; err != nil {
+			return err
+		}
+		return recordAudit(tx, ctx, "amenity.created", "amenity", amenity.ID, nil, amenity)
+	})
+	if errors.Is(err, errAmenityExists) {
+		ctx.JSON(http.StatusConflict, gin.H{"error": "An amenity with this ID already exists"})
+		return
+	}
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create amenity"})
+		return
+	}
+	ctx.JSON(http.StatusCreated, amenity)
+}
+
+var errAmenityExists = errors.New("amenity already exists")
+
+// UpdateAmenity renames, recategorises or changes the icon of an amenity
+// (admin only). Its ID stays the same, so halls keep it.
+func (c *AmenityController) UpdateAmenity(ctx *gin.Context) {
+	var request amenityRequest
+	if err := ctx.ShouldBindJSON(&request); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	var amenity models.Amenity
+	if err := c.db.First(&amenity, "id = ?", ctx.Param("id")).Error; err != nil {
+		ctx.JSON(http.StatusNotFound, gin.H{"error": "Amenity not found"})
+		return
+	}
+	before := amenity
+	request.apply(&amenity)
+	if amenity.Name == "" {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Name is required"})
+		return
+	}
+
+	err := c.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Save(&amenity).Error; err != nil {
+			return err
+		}
+		return recordAudit(tx, ctx, "amenity.updated", "amenity", amenity.ID, before, amenity)
+	})
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update amenity"})
+		return
+	}
+	ctx.JSON(http.StatusOK, amenity)
+}
+
+// DeleteAmenity removes an amenity from the catalogue and from every hall
+// that offered it (admin only).
+func (c *AmenityController) DeleteAmenity(ctx *gin.Context) {
+	var amenity models.Amenity
+	err := c.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.First(&amenity, "id = ?", ctx.Param("id")).Error; err != nil {
+			return err
+		}
+		if err := tx.Exec("DELETE FROM hall_amenities WHERE amenity_id = ?", amenity.ID).Error; err != nil {
+			return err
+		}
+		if err := tx.Delete(&amenity).Error; err != nil {
+			return err
+		}
+		return recordAudit(tx, ctx, "amenity.deleted", "amenity", amenity.ID, amenity, nil)
+	})
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		ctx.JSON(http.StatusNotFound, gin.H{"error": "Amenity not found"})
+		return
+	}
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete amenity"})
+		return
+	}
+	ctx.JSON(http.StatusOK, gin.H{"message": "Amenity deleted"})
+}
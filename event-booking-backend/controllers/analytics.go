@@ -0,0 +1,473 @@
+package controllers
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+
+	"event-booking-backend/models"
+	"event-booking-backend/services"
+)
+
+// Analytics ranges default to the last defaultAnalyticsDays days and may
+// span at most maxAnalyticsDays.
+const (
+	defaultAnalyticsDays = 30
+	maxAnalyticsDays     = 3 * 366
+	popularPackageLimit  = 10
+)
+
+// netAmount is what a booking brought in: its price and fees less refunds,
+// the same figure as the Net column of the payments export.
+const netAmount = "total_price + fee_total - refund_amount"
+
+// AnalyticsController reports on revenue, occupancy and booking behaviour.
+// Everything is aggregated in SQL; only the grouped rows reach Go.
+//
+// Every report takes from and to (YYYY-MM-DD, inclusive, default the last
+// 30 days) and tz (an IANA zone, default VENUE_TIMEZONE). Booking times are
+// read in tz, so a booking made at 23:30 local time counts towards that
+// day. Event dates are calendar dates, stored as UTC midnight, so they are
+// read in UTC whatever tz is.
+type AnalyticsController struct {
+	db *gorm.DB
+}
+
+func NewAnalyticsController(db *gorm.DB) *AnalyticsController {
+	return &AnalyticsController{db: db}
+}
+
+// analyticsRange is the reporting window. From and To are local midnights
+// in the report's time zone; To is exclusive.
+type analyticsRange struct {
+	From     time.Time
+	To       time.Time
+	Location *time.Location
+	// Column is the booking time the range applies to: created_at (when
+	// the booking was made) or event_date (when the event takes place).
+	Column string
+}
+
+func parseAnalyticsRange(ctx *gin.Context) (*analyticsRange, bool) {
+	tz := ctx.DefaultQuery("tz", services.VenueTimezone())
+	loc, err := time.LoadLocation(tz)
+	if err != nil || tz == "" || tz == "Local" {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "tz must be an IANA time zone such as Europe/London"})
+		return nil, false
+	}
+
+	r := &analyticsRange{Location: loc, Column: "created_at"}
+	switch ctx.DefaultQuery("basis", "booked") {
+	case "booked":
+	case "event":
+		r.Column = "event_date"
+	default:
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "basis must be booked or event"})
+		return nil, false
+	}
+
+	today := time.Now().In(loc)
+	r.To = time.Date(today.Year(), today.Month(), today.Day()+1, 0, 0, 0, 0, loc)
+	if v := ctx.Query("to"); v != "" {
+		to, err := time.ParseInLocation("2006-01-02", v, loc)
+		if err != nil {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": "to must be a date (YYYY-MM-DD)"})
+			return nil, false
+		}
+		r.To = to.AddDate(0, 0, 1)
+	}
+	r.From = r.To.AddDate(0, 0, -defaultAnalyticsDays)
+	if v := ctx.Query("from"); v != "" {
+		from, err := time.ParseInLocation("2006-01-02", v, loc)
+		if err != nil {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": "from must be a date (YYYY-MM-DD)"})
+			return nil, false
+		}
+		r.From = from
+	}
+
+	switch days := r.days(); {
+	case days < 1:
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "from must not be after to"})
+		return nil, false
+	case days > maxAnalyticsDays:
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "The date range can span at most three years"})
+		return nil, false
+	}
+	return r, true
+}
+
+// days counts the calendar days in the range. Days are not always 24 hours
+// long, so the count rounds to the nearest whole day.
+func (r *analyticsRange) days() int {
+	return int((r.To.Sub(r.From) + 12*time.Hour) / (24 * time.Hour))
+}
+
+func (r *analyticsRange) tz() string {
+	return r.Location.String()
+}
+
+// zone is the time zone r.Column is read in: tz for created_at, UTC for
+// the calendar dates in event_date.
+func (r *analyticsRange) zone() string {
+	if r.Column == "event_date" {
+		return "UTC"
+	}
+	return r.tz()
+}
+
+// scope restricts query to bookings inside the range.
+func (r *analyticsRange) scope(query *gorm.DB) *gorm.DB {
+	from, to := r.From, r.To
+	if r.Column == "event_date" {
+		from, to = utcDate(from), utcDate(to)
+	}
+	return query.Where(r.Column+" >= ? AND "+r.Column+" < ?", from, to)
+}
+
+// utcDate is the UTC midnight of t's calendar date, which is how event
+// dates are stored.
+func utcDate(t time.Time) time.Time {
+	y, m, d := t.Date()
+	return time.Date(y, m, d, 0, 0, 0, 0, time.UTC)
+}
+
+// lastYear is the same range a year earlier.
+func (r *analyticsRange) lastYear() *analyticsRange {
+	prev := *r
+	prev.From = r.From.AddDate(-1, 0, 0)
+	prev.To = r.To.AddDate(-1, 0, 0)
+	return &prev
+}
+
+func (r *analyticsRange) describe() gin.H {
+	return gin.H{
+		"from":  r.From.Format("2006-01-02"),
+		"to":    r.To.AddDate(0, 0, -1).Format("2006-01-02"),
+		"tz":    r.tz(),
+		"basis": map[string]string{"created_at": "booked", "event_date": "event"}[r.Column],
+	}
+}
+
+type revenueRow struct {
+	Period   string  `json:"period,omitempty"`
+	HallID   string  `json:"hallId,omitempty"`
+	Bookings int64   `json:"bookings"`
+	Charged  float64 `json:"charged"`
+	Fees     float64 `json:"fees"`
+	Refunded float64 `json:"refunded"`
+	Net      float64 `json:"net"`
+}
+
+func (r *revenueRow) add(o revenueRow) {
+	r.Bookings += o.Bookings
+	r.Charged += o.Charged
+	r.Fees += o.Fees
+	r.Refunded += o.Refunded
+	r.Net += o.Net
+}
+
+// Revenue reports takings per day, week or month (interval) and per hall.
+// periods has one entry for every period in the range, including empty
+// ones; byHall breaks each period down by hall; halls totals each hall over
+// the whole range. Amounts are as recorded on the bookings, cancelled ones
+// included, so refunds and retained fees are counted.
+func (c *AnalyticsController) Revenue(ctx *gin.Context) {
+	r, ok := parseAnalyticsRange(ctx)
+	if !ok {
+		return
+	}
+	interval := ctx.DefaultQuery("interval", "day")
+	if interval != "day" && interval != "week" && interval != "month" {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "interval must be day, week or month"})
+		return
+	}
+
+	var rows []revenueRow
+	err := r.scope(c.db.Model(&models.Booking{})).
+		Select("to_char(date_trunc(?, "+r.Column+" AT TIME ZONE ?), 'YYYY-MM-DD') AS period, hall_id, "+
+			"COUNT(*) AS bookings, SUM(total_price) AS charged, SUM(fee_total) AS fees, "+
+			"SUM(refund_amount) AS refunded, SUM("+netAmount+") AS net", interval, r.zone()).
+		Group("period, hall_id").
+		Order("period, hall_id").
+		Scan(&rows).Error
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to compute revenue"})
+		return
+	}
+
+	var periods []revenueRow
+	index := make(map[string]int)
+	for t := periodStart(r.From, interval); t.Before(r.To); t = nextPeriod(t, interval) {
+		key := t.Format("2006-01-02")
+		index[key] = len(periods)
+		periods = append(periods, revenueRow{Period: key})
+	}
+	hallTotals := make(map[string]*revenueRow)
+	var halls []*revenueRow
+	total := revenueRow{}
+	for _, row := range rows {
+		if i, ok := index[row.Period]; ok {
+			periods[i].add(row)
+		}
+		h, ok := hallTotals[row.HallID]
+		if !ok {
+			h = &revenueRow{HallID: row.HallID}
+			hallTotals[row.HallID] = h
+			halls = append(halls, h)
+		}
+		h.add(row)
+		total.add(row)
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{
+		"range":    r.describe(),
+		"interval": interval,
+		"periods":  periods,
+		"byHall":   rows,
+		"halls":    halls,
+		"total":    total,
+	})
+}
+
+// periodStart truncates t the way Postgres's date_trunc does: weeks start
+// on Monday.
+func periodStart(t time.Time, interval string) time.Time {
+	y, m, d := t.Date()
+	switch interval {
+	case "week":
+		offset := (int(t.Weekday()) + 6) % 7
+		return time.Date(y, m, d-offset, 0, 0, 0, 0, t.Location())
+	case "month":
+		return time.Date(y, m, 1, 0, 0, 0, 0, t.Location())
+	default:
+		return time.Date(y, m, d, 0, 0, 0, 0, t.Location())
+	}
+}
+
+func nextPeriod(t time.Time, interval string) time.Time {
+	switch interval {
+	case "week":
+		return t.AddDate(0, 0, 7)
+	case "month":
+		return t.AddDate(0, 1, 0)
+	default:
+		return t.AddDate(0, 0, 1)
+	}
+}
+
+type occupancyRow struct {
+	HallID         string  `json:"hallId"`
+	Name           string  `json:"name"`
+	Bookings       int64   `json:"bookings"`
+	BookedHours    float64 `json:"bookedHours"`
+	AvailableHours float64 `json:"availableHours"`
+	Rate           float64 `json:"rate"`
+}
+
+// Occupancy reports, per hall, the share of its operating hours taken by
+// bookings that were not cancelled. It always works on event dates.
+func (c *AnalyticsController) Occupancy(ctx *gin.Context) {
+	r, ok := parseAnalyticsRange(ctx)
+	if !ok {
+		return
+	}
+	r.Column = "event_date"
+
+	// Halls archived before the range had no hours to fill.
+	var halls []models.Hall
+	if err := c.db.Where("archived_at IS NULL OR archived_at >= ?", r.From).Order("id").Find(&halls).Error; err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to compute occupancy"})
+		return
+	}
+
+	// end_time wraps past midnight for late slots.
+	var booked []occupancyRow
+	err := r.scope(c.db.Model(&models.Booking{})).
+		Where("status != ?", models.StatusCancelled).
+		Select("hall_id, COUNT(*) AS bookings, " +
+			"SUM(EXTRACT(EPOCH FROM end_time::time - start_time::time) / 3600 " +
+			"+ CASE WHEN end_time::time <= start_time::time THEN 24 ELSE 0 END) AS booked_hours").
+		Group("hall_id").
+		Scan(&booked).Error
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to compute occupancy"})
+		return
+	}
+	byHall := make(map[string]occupancyRow, len(booked))
+	for _, row := range booked {
+		byHall[row.HallID] = row
+	}
+
+	days := float64(r.days())
+	rows := make([]occupancyRow, 0, len(halls))
+	var total occupancyRow
+	for _, hall := range halls {
+		row := byHall[hall.ID]
+		row.HallID = hall.ID
+		row.Name = hall.Name
+		if hours, err := hall.OpenHours(); err == nil {
+			row.AvailableHours = hours * days
+		}
+		if row.AvailableHours > 0 {
+			row.Rate = row.BookedHours / row.AvailableHours
+		}
+		total.Bookings += row.Bookings
+		total.BookedHours += row.BookedHours
+		total.AvailableHours += row.AvailableHours
+		rows = append(rows, row)
+	}
+	if total.AvailableHours > 0 {
+		total.Rate = total.BookedHours / total.AvailableHours
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{
+		"range": r.describe(),
+		"halls": rows,
+		"total": total,
+	})
+}
+
+// bookingSummary is the headline figures for a range.
+type bookingSummary struct {
+	Bookings         int64    `json:"bookings"`
+	Cancelled        int64    `json:"cancelled"`
+	CancellationRate float64  `json:"cancellationRate"`
+	Net              float64  `json:"net"`
+	AvgLeadDays      *float64 `json:"avgLeadDays"`
+	MedianLeadDays   *float64 `json:"medianLeadDays"`
+}
+
+// summarize computes the headline figures. Lead time runs from when the
+// booking was made, in local time, to when the event starts on its date.
+func (c *AnalyticsController) summarize(r *analyticsRange) (*bookingSummary, error) {
+	leads := r.scope(c.db.Model(&models.Booking{})).
+		Select("status, "+netAmount+" AS net, "+
+			"EXTRACT(EPOCH FROM ((event_date AT TIME ZONE 'UTC')::date + start_time::time) - (created_at AT TIME ZONE ?)) / 86400 AS lead_days",
+			r.tz())
+
+	var s bookingSummary
+	err := c.db.Table("(?) AS b", leads).
+		Select("COUNT(*) AS bookings, COUNT(*) FILTER (WHERE status = ?) AS cancelled, "+
+			"COALESCE(SUM(net), 0) AS net, AVG(lead_days) AS avg_lead_days, "+
+			"percentile_cont(0.5) WITHIN GROUP (ORDER BY lead_days) AS median_lead_days", models.StatusCancelled).
+		Scan(&s).Error
+	if err != nil {
+		return nil, err
+	}
+	if s.Bookings > 0 {
+		s.CancellationRate = float64(s.Cancelled) / float64(s.Bookings)
+	}
+	return &s, nil
+}
+
+type slotMixRow struct {
+	Slot     string  `json:"slot"`
+	DayType  string  `json:"dayType"`
+	Bookings int64   `json:"bookings"`
+	Net      float64 `json:"net"`
+}
+
+type packageRow struct {
+	HallID    string  `json:"hallId"`
+	StartTime string  `json:"startTime"`
+	Bookings  int64   `json:"bookings"`
+	Net       float64 `json:"net"`
+}
+
+// Bookings reports lead time, cancellation rate, the peak/off-peak and
+// weekend/weekday mix and the most popular packages. A package is a hall
+// and time slot, which is what the packages page sells. The mix and
+// packages leave out cancelled bookings.
+func (c *AnalyticsController) Bookings(ctx *gin.Context) {
+	r, ok := parseAnalyticsRange(ctx)
+	if !ok {
+		return
+	}
+
+	summary, err := c.summarize(r)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to compute booking statistics"})
+		return
+	}
+
+	// Peak hours and weekends follow isPeakHour and isWeekend, which set
+	// the price.
+	var mix []slotMixRow
+	err = r.scope(c.db.Model(&models.Booking{})).
+		Where("status != ?", models.StatusCancelled).
+		Select("CASE WHEN substr(start_time, 1, 2) BETWEEN '18' AND '22' THEN 'peak' ELSE 'off_peak' END AS slot, " +
+			"CASE WHEN EXTRACT(ISODOW FROM event_date AT TIME ZONE 'UTC') IN (6, 7) THEN 'weekend' ELSE 'weekday' END AS day_type, " +
+			"COUNT(*) AS bookings, SUM(" + netAmount + ") AS net").
+		Group("slot, day_type").
+		Order("slot, day_type").
+		Scan(&mix).Error
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to compute booking statistics"})
+		return
+	}
+
+	var packages []packageRow
+	err = r.scope(c.db.Model(&models.Booking{})).
+		Where("status != ?", models.StatusCancelled).
+		Select("hall_id, start_time, COUNT(*) AS bookings, SUM(" + netAmount + ") AS net").
+		Group("hall_id, start_time").
+		Order("bookings DESC, net DESC, hall_id, start_time").
+		Limit(popularPackageLimit).
+		Scan(&packages).Error
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to compute booking statistics"})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{
+		"range":    r.describe(),
+		"summary":  summary,
+		"mix":      mix,
+		"packages": packages,
+	})
+}
+
+// Comparison sets the range against the same dates a year earlier. Changes
+// are relative (0.25 is 25% up) except cancellationRate, which is the
+// difference in rates; a change is null when last year's figure is zero.
+func (c *AnalyticsController) Comparison(ctx *gin.Context) {
+	r, ok := parseAnalyticsRange(ctx)
+	if !ok {
+		return
+	}
+	prev := r.lastYear()
+
+	current, err := c.summarize(r)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to compute comparison"})
+		return
+	}
+	previous, err := c.summarize(prev)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to compute comparison"})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{
+		"range":         r.describe(),
+		"previousRange": prev.describe(),
+		"current":       current,
+		"previous":      previous,
+		"change": gin.H{
+			"bookings":         relativeChange(float64(current.Bookings), float64(previous.Bookings)),
+			"net":              relativeChange(current.Net, previous.Net),
+			"cancellationRate": current.CancellationRate - previous.CancellationRate,
+		},
+	})
+}
+
+func relativeChange(current, previous float64) *float64 {
+	if previous == 0 {
+		return nil
+	}
+	change := (current - previous) / previous
+	return &change
+}
@@ -0,0 +1,66 @@
+package controllers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+
+	"event-booking-backend/models"
+)
+
+func TestAnalyticsRangeScope(t *testing.T) {
+	db := newTestDB(t)
+	newYork, _ := time.LoadLocation("America/New_York")
+	tests := []struct {
+		basis    string
+		from, to time.Time
+	}{
+		// Event dates are calendar dates at UTC midnight, whatever the zone
+		{"event", time.Date(2026, 3, 7, 0, 0, 0, 0, time.UTC), time.Date(2026, 3, 8, 0, 0, 0, 0, time.UTC)},
+		// Booking times are instants, bounded by local midnights
+		{"booked", time.Date(2026, 3, 7, 0, 0, 0, 0, newYork), time.Date(2026, 3, 8, 0, 0, 0, 0, newYork)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.basis, func(t *testing.T) {
+			ctx, _ := gin.CreateTestContext(httptest.NewRecorder())
+			ctx.Request = httptest.NewRequest(http.MethodGet, "/?from=2026-03-07&to=2026-03-07&tz=America/New_York&basis="+tt.basis, nil)
+			r, ok := parseAnalyticsRange(ctx)
+			if !ok {
+				t.Fatal("range refused")
+			}
+			stmt := r.scope(db.Session(&gorm.Session{DryRun: true})).Find(&[]models.Booking{}).Statement
+			if len(stmt.Vars) != 2 {
+				t.Fatalf("vars = %v", stmt.Vars)
+			}
+			from, to := stmt.Vars[0].(time.Time), stmt.Vars[1].(time.Time)
+			if !from.Equal(tt.from) || !to.Equal(tt.to) {
+				t.Errorf("range is %s to %s, want %s to %s", from, to, tt.from, tt.to)
+			}
+		})
+	}
+}
+
+func TestAnalyticsBadQuery(t *testing.T) {
+	ac := NewAnalyticsController(newTestDB(t))
+	router := gin.New()
+	router.GET("/revenue", ac.Revenue)
+	router.GET("/bookings", ac.Bookings)
+
+	for _, path := range []string{
+		"/revenue?tz=Mars/Olympus",
+		"/revenue?tz=Local",
+		"/revenue?basis=paid",
+		"/revenue?interval=hour",
+		"/revenue?from=2026-02-01&to=2026-01-01",
+		"/revenue?from=2020-01-01&to=2026-01-01",
+		"/bookings?from=01/02/2026",
+	} {
+		if w := serve(router, http.MethodGet, path, nil); w.Code != http.StatusBadRequest {
+			t.Errorf("%s: got %d %s, want 400", path, w.Code, w.Body.String())
+		}
+	}
+}
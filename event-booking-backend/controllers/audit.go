@@ -0,0 +1,208 @@
+package controllers
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"log"
+	"net/http"
+	"reflect"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+
+	"event-booking-backend/middlewares"
+	"event-booking-backend/models"
+	"event-booking-backend/services"
+)
+
+const (
+	auditPageSize    = 100
+	auditMaxPageSize = 500
+	auditExportBatch = 500
+)
+
+// auditIgnoredFields change on every save and would only add noise.
+var auditIgnoredFields = map[string]bool{"updatedAt": true}
+
+// recordAudit appends an audit entry for the admin behind ctx. before and
+// after are the entity as it was and as it is now; pass nil for before on
+// creation and for both when there is no state to compare. Only fields
+// that differ are kept, using their JSON names, so anything hidden from JSON
+// (password hashes, TOTP secrets) never reaches the log.
+func recordAudit(tx *gorm.DB, ctx *gin.Context, action, entityType, entityID string, before, after interface{}) error {
+	changes, err := auditDiff(before, after)
+	if err != nil {
+		return err
+	}
+
+	entry := models.AuditLog{
+		Action:     action,
+		EntityType: entityType,
+		EntityID:   entityID,
+		Changes:    changes,
+		IP:         ctx.ClientIP(),
+		RequestID:  middlewares.GetRequestID(ctx),
+	}
+	if admin := middlewares.CurrentAdmin(ctx); admin != nil {
+		entry.ActorID = &admin.UserID
+		entry.ActorEmail = admin.Email
+		entry.ActorRole = admin.Role
+	}
+	return tx.Create(&entry).Error
+}
+
+func auditDiff(before, after interface{}) (models.AuditChanges, error) {
+	from, err := auditFields(before)
+	if err != nil {
+		return nil, err
+	}
+	to, err := auditFields(after)
+	if err != nil {
+		return nil, err
+	}
+
+	changes := models.AuditChanges{}
+	for field, value := range to {
+		if !auditIgnoredFields[field] && !reflect.DeepEqual(from[field], value) {
+			changes[field] = models.AuditChange{From: from[field], To: value}
+		}
+	}
+	for field, value := range from {
+		if _, ok := to[field]; !ok && !auditIgnoredFields[field] {
+			changes[field] = models.AuditChange{From: value}
+		}
+	}
+	return changes, nil
+}
+
+// auditFields flattens v to its JSON object form.
+func auditFields(v interface{}) (map[string]interface{}, error) {
+	if v == nil {
+		return nil, nil
+	}
+	b, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	var fields map[string]interface{}
+	if err := json.Unmarshal(b, &fields); err != nil {
+		return nil, err
+	}
+	return fields, nil
+}
+
+// AuditController serves the audit log to owners.
+type AuditController struct {
+	db *gorm.DB
+}
+
+func NewAuditController(db *gorm.DB) *AuditController {
+	return &AuditController{db: db}
+}
+
+// ListAudit returns audit entries newest first. Filters: actorId, action,
+// entityType, entityId, from and to (RFC 3339 or YYYY-MM-DD). Pass the last
+// id of a page as beforeId to get the next one.
+func (c *AuditController) ListAudit(ctx *gin.Context) {
+	query, ok := c.filteredAudit(ctx)
+	if !ok {
+		return
+	}
+
+	limit := auditPageSize
+	if v := ctx.Query("limit"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 || n > auditMaxPageSize {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": "limit must be between 1 and 500"})
+			return
+		}
+		limit = n
+	}
+	if v := ctx.Query("beforeId"); v != "" {
+		id, err := strconv.ParseUint(v, 10, 64)
+		if err != nil {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid beforeId"})
+			return
+		}
+		query = query.Where("id < ?", id)
+	}
+
+	var entries []models.AuditLog
+	if err := query.Order("id DESC").Limit(limit).Find(&entries).Error; err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch audit log"})
+		return
+	}
+	ctx.JSON(http.StatusOK, entries)
+}
+
+// ExportAudit streams the filtered audit log as CSV, oldest first.
+func (c *AuditController) ExportAudit(ctx *gin.Context) {
+	query, ok := c.filteredAudit(ctx)
+	if !ok {
+		return
+	}
+
+	ctx.Header("Content-Type", "text/csv; charset=utf-8")
+	ctx.Header("Content-Disposition", `attachment; filename="audit-`+time.Now().Format("2006-01-02")+`.csv"`)
+
+	w := csv.NewWriter(ctx.Writer)
+	w.Write([]string{"id", "created_at", "actor_id", "actor_email", "actor_role", "action",
+		"entity_type", "entity_id", "changes", "ip", "request_id"})
+
+	var entries []models.AuditLog
+	err := query.Order("id").FindInBatches(&entries, auditExportBatch, func(tx *gorm.DB, batch int) error {
+		for _, e := range entries {
+			actorID := ""
+			if e.ActorID != nil {
+				actorID = strconv.FormatUint(uint64(*e.ActorID), 10)
+			}
+			changes, _ := json.Marshal(e.Changes)
+			w.Write([]string{
+				strconv.FormatUint(e.ID, 10),
+				e.CreatedAt.UTC().Format(time.RFC3339),
+				actorID,
+				services.CSVSafe(e.ActorEmail),
+				e.ActorRole,
+				e.Action,
+				e.EntityType,
+				services.CSVSafe(e.EntityID),
+				services.CSVSafe(string(changes)),
+				e.IP,
+				services.CSVSafe(e.RequestID),
+			})
+		}
+		w.Flush()
+		return w.Error()
+	}).Error
+	if err != nil {
+		// Headers are already sent; all we can do is cut the file short.
+		log.Printf("Failed to export audit log: %v", err)
+	}
+	w.Flush()
+}
+
+// filteredAudit applies the query string filters shared by list and export.
+func (c *AuditController) filteredAudit(ctx *gin.Context) (*gorm.DB, bool) {
+	query := c.db.Model(&models.AuditLog{})
+
+	if v := ctx.Query("actorId"); v != "" {
+		id, err := strconv.ParseUint(v, 10, 64)
+		if err != nil {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid actorId"})
+			return nil, false
+		}
+		query = query.Where("actor_id = ?", id)
+	}
+	if v := ctx.Query("action"); v != "" {
+		query = query.Where("action = ?", v)
+	}
+	if v := ctx.Query("entityType"); v != "" {
+		query = query.Where("entity_type = ?", v)
+	}
+	if v := ctx.Query("entityId"); v != "" {
+		query = query.Where("entity_id = ?", v)
+	}
+	return whereTimeRange(ctx, query, "from", "to", "created_at")
+}
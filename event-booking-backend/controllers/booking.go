@@ -1,6 +1,8 @@
 package controllers
 
 import (
+    "errors"
+    "log"
     "net/http"
     "strconv"
     "time"
@@ -8,19 +10,26 @@ import (
     "github.com/gin-gonic/gin"
     "gorm.io/gorm"
 
+    "event-booking-backend/middlewares"
     "event-booking-backend/models"
     "event-booking-backend/services"
 )
 
 type BookingController struct {
-    db    *gorm.DB
-    email *services.EmailService
+    db     *gorm.DB
+    email  *services.Mailer
+    links  *services.ManageLinkService
+    policy services.BookingPolicy
+    guard  *services.SpamGuard
 }
 
-func NewBookingController(db *gorm.DB, email *services.EmailService) *BookingController {
+func NewBookingController(db *gorm.DB, email *services.Mailer, links *services.ManageLinkService, policy services.BookingPolicy, guard *services.SpamGuard) *BookingController {
     return &BookingController{
-        db:    db,
-        email: email,
+        db:     db,
+        email:  email,
+        links:  links,
+        policy: policy,
+        guard:  guard,
     }
 }
 
@@ -37,31 +46,26 @@ func (c *BookingController) CreateBooking(ctx *gin.Context) {
         ctx.JSON(http.StatusBadRequest, gin.H{"error": "Event date must be in the future"})
         return
     }
-
-    // Check hall availability
-    var existingBooking models.Booking
-    result := c.db.Where("hall_id = ? AND DATE(event_date) = DATE(?) AND start_time = ? AND status != ?",
-        request.HallID, request.EventDate, request.StartTime, models.StatusCancelled).First(&existingBooking)
-
-    if result.Error == nil {
-        ctx.JSON(http.StatusConflict, gin.H{"error": "This time slot is already booked"})
+    if !validStartTime(request.StartTime) {
+        ctx.JSON(http.StatusBadRequest, gin.H{"error": "Start time must be in HH:MM format"})
         return
     }
-
-    // Get hall details for pricing
-    var hall models.Hall
-    if err := c.db.First(&hall, "id = ?", request.HallID).Error; err != nil {
-        ctx.JSON(http.StatusNotFound, gin.H{"error": "Hall not found"})
+    layout := models.SeatingLayout(request.Layout)
+    if layout != "" && !layout.Valid() {
+        ctx.JSON(http.StatusBadRequest, gin.H{"error": "Unknown seating layout"})
         return
     }
 
-    // Calculate total price
-    totalPrice := hall.BasePrice
-    if isWeekend(request.EventDate) {
-        totalPrice += hall.WeekendRate
-    }
-    if isPeakHour(request.StartTime) {
-        totalPrice += hall.PeakRate
+    // Refuse bots and hold anything suspicious for review
+    reasons, ok := screenSubmission(ctx, c.guard, services.Submission{
+        Kind:         services.SubmissionBooking,
+        Email:        request.CustomerEmail,
+        Honeypot:     request.Website,
+        CaptchaToken: request.CaptchaToken,
+        Text:         []string{request.CustomerName, request.SpecialRequests},
+    })
+    if !ok {
+        return
     }
 
     // Create booking without setting ID (auto-incremented by database)
@@ -71,52 +75,164 @@ func (c *BookingController) CreateBooking(ctx *gin.Context) {
         CustomerEmail:   request.CustomerEmail,
         CustomerPhone:   request.CustomerPhone,
         GuestCount:      request.GuestCount,
+        Layout:          layout,
         EventDate:       request.EventDate,
         StartTime:       request.StartTime,
         EndTime:         calculateEndTime(request.StartTime),
         SpecialRequests: request.SpecialRequests,
         Status:          models.StatusPending,
-        TotalPrice:      totalPrice,
+        Locale:          requestLocale(ctx, request.Locale),
     }
 
-    if err := c.db.Create(booking).Error; err != nil {
-        ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create booking"})
-        return
+    // Attach the booking to the logged-in customer, if any
+    if ctx.GetString("role") == middlewares.RoleCustomer {
+        customerID := ctx.GetUint("user_id")
+        booking.CustomerID = &customerID
     }
 
-    // Send notifications
-    go func() {
-        if err := c.email.SendBookingConfirmation(booking); err != nil {
-            // Log error but don't fail the request
-            println("Failed to send customer confirmation:", err.Error())
+    // Check availability and create the booking while holding the hall lock
+    // so two concurrent requests cannot both take the same slot
+    var manageToken string
+    err := c.db.Transaction(func(tx *gorm.DB) error {
+        hall, err := lockHall(tx, request.HallID)
+        if err != nil {
+            return err
+        }
+        if !hall.AcceptsBookings(booking.StartsAt(services.VenueLocation())) {
+            return errHallUnavailable
+        }
+        if err := ensureCapacity(tx, hall, layout, request.GuestCount); err != nil {
+            return err
         }
-        if err := c.email.SendAdminNotification(booking); err != nil {
-            println("Failed to send admin notification:", err.Error())
+        if err := ensureSlotFree(tx, request.HallID, request.EventDate, request.StartTime, 0); err != nil {
+            return err
+        }
+        booking.TotalPrice = calculatePrice(hall, request.EventDate, request.StartTime)
+        if err := tx.Create(booking).Error; err != nil {
+            return err
         }
-    }()
+
+        // Queue the notifications with the booking. A booking held for
+        // review is not confirmed to the customer until staff approve it,
+        // so the form cannot be used to send mail to strangers.
+        mail := c.email.In(tx)
+        if len(reasons) > 0 {
+            if err := holdForReview(tx, ctx, models.ReviewBooking, strconv.FormatUint(booking.ID, 10), booking.CustomerEmail, reasons); err != nil {
+                return err
+            }
+        } else {
+            var manageURL string
+            manageToken, manageURL = c.manageLink(booking)
+            if err := mail.SendBookingConfirmation(booking, manageURL); err != nil {
+                return err
+            }
+        }
+        return mail.SendAdminNotification(booking)
+    })
+    switch {
+    case errors.Is(err, errHallNotFound):
+        ctx.JSON(http.StatusNotFound, gin.H{"error": "Hall not found"})
+        return
+    case errors.Is(err, errSlotTaken):
+        ctx.JSON(http.StatusConflict, gin.H{"error": "This time slot is already booked"})
+        return
+    case errors.Is(err, errHallUnavailable):
+        ctx.JSON(http.StatusConflict, gin.H{"error": "This hall is not taking bookings"})
+        return
+    case errors.Is(err, errOverCapacity):
+        ctx.JSON(http.StatusBadRequest, gin.H{"error": "Too many guests for this hall and layout"})
+        return
+    case errors.Is(err, errNoSuchLayout):
+        ctx.JSON(http.StatusBadRequest, gin.H{"error": "This hall does not offer that seating layout"})
+        return
+    case err != nil:
+        ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create booking"})
+        return
+    }
 
     // Convert ID to string for response
     ctx.JSON(http.StatusCreated, models.BookingResponse{
         ID:            strconv.FormatUint(booking.ID, 10),
         Status:        booking.Status,
         BookingDetail: *booking,
+        ManageToken:   manageToken,
     })
 }
 
-// GetBookings returns all bookings (admin only)
+// manageLink issues the customer's manage link and its token, or "" for
+// both when it cannot be issued; the booking goes ahead without the link.
+func (c *BookingController) manageLink(booking *models.Booking) (string, string) {
+    token, err := c.links.Token(booking)
+    if err != nil {
+        log.Printf("Failed to issue manage token for booking %d: %v", booking.ID, err)
+        return "", ""
+    }
+    return token, c.links.URL(token)
+}
+
+// GetBookings returns one page of the bookings matching the query string
+// filters, with the total and the count per status (admin only)
 func (c *BookingController) GetBookings(ctx *gin.Context) {
-    var bookings []models.Booking
-    if err := c.db.Find(&bookings).Error; err != nil {
+    list, ok := parseBookingListQuery(ctx)
+    if !ok {
+        return
+    }
+    statuses, ok := bookingStatuses(ctx)
+    if !ok {
+        return
+    }
+    filtered, ok := filterBookings(ctx, c.db.Model(&models.Booking{}))
+    if !ok {
+        return
+    }
+    // Both queries below start from the same filters
+    filtered = filtered.Session(&gorm.Session{})
+
+    // Counts ignore the status filter so every status tab can show its own
+    var counts []struct {
+        Status models.BookingStatus
+        Count  int64
+    }
+    if err := filtered.Select("status, COUNT(*) AS count").Group("status").Scan(&counts).Error; err != nil {
+        ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to count bookings"})
+        return
+    }
+
+    page := bookingPage{
+        Bookings: []models.Booking{},
+        Counts: map[models.BookingStatus]int64{
+            models.StatusPending:   0,
+            models.StatusConfirmed: 0,
+            models.StatusCancelled: 0,
+        },
+    }
+    for _, row := range counts {
+        page.Counts[row.Status] = row.Count
+        if len(statuses) == 0 || containsStatus(statuses, row.Status) {
+            page.Total += row.Count
+        }
+    }
+
+    query := filtered
+    if len(statuses) > 0 {
+        query = query.Where("status IN ?", statuses)
+    }
+    if err := list.page(query).Find(&page.Bookings).Error; err != nil {
         ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch bookings"})
         return
     }
-    ctx.JSON(http.StatusOK, bookings)
+    if len(page.Bookings) > list.limit {
+        page.Bookings = page.Bookings[:list.limit]
+        page.NextCursor = list.cursor(&page.Bookings[list.limit-1])
+    }
+
+    ctx.JSON(http.StatusOK, page)
 }
 
-// UpdateBookingStatus updates the status of a booking (admin only)
+// UpdateBookingStatus moves a booking along the status rules and notifies
+// the customer (admin only)
 func (c *BookingController) UpdateBookingStatus(ctx *gin.Context) {
-    bookingIDStr := ctx.Param("id")
-    bookingID, err := strconv.ParseUint(bookingIDStr, 10, 64)
+    bookingID, err := strconv.ParseUint(ctx.Param("id"), 10, 64)
     if err != nil {
         ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid booking ID"})
         return
@@ -131,15 +247,14 @@ func (c *BookingController) UpdateBookingStatus(ctx *gin.Context) {
         return
     }
 
-    var booking models.Booking
-    if err := c.db.First(&booking, bookingID).Error; err != nil {
-        ctx.JSON(http.StatusNotFound, gin.H{"error": "Booking not found"})
+    if !validStatus(request.Status) {
+        ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid status"})
         return
     }
 
-    booking.Status = request.Status
-    if err := c.db.Save(&booking).Error; err != nil {
-        ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update booking"})
+    booking, err := c.changeBookingStatus(ctx, bookingID, request.Status)
+    if err != nil {
+        writeStatusError(ctx, err)
         return
     }
 
@@ -164,6 +279,31 @@ func (c *BookingController) GetBooking(ctx *gin.Context) {
 }
 
 // Helper functions
+func containsStatus(statuses []models.BookingStatus, status models.BookingStatus) bool {
+    for _, s := range statuses {
+        if s == status {
+            return true
+        }
+    }
+    return false
+}
+
+func calculatePrice(hall *models.Hall, date time.Time, startTime string) float64 {
+    price := hall.BasePrice
+    if isWeekend(date) {
+        price += hall.WeekendRate
+    }
+    if isPeakHour(startTime) {
+        price += hall.PeakRate
+    }
+    return price
+}
+
+func validStartTime(startTime string) bool {
+    _, err := time.Parse("15:04", startTime)
+    return err == nil
+}
+
 func isWeekend(date time.Time) bool {
     day := date.Weekday()
     return day == time.Saturday || day == time.Sunday
@@ -0,0 +1,287 @@
+package controllers
+
+import (
+	"errors"
+	"log"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"event-booking-backend/middlewares"
+	"event-booking-backend/models"
+)
+
+// Limits on bulk requests and on booking tags.
+const (
+	maxBulkBookings = 200
+	maxBookingTags  = 20
+	maxTagLength    = 40
+)
+
+// Bulk actions.
+const (
+	bulkConfirm      = "confirm"
+	bulkCancel       = "cancel"
+	bulkReassignHall = "reassign_hall"
+	bulkTag          = "tag"
+	bulkUntag        = "untag"
+)
+
+type bulkBookingRequest struct {
+	IDs    []string `json:"ids" binding:"required"`
+	Action string   `json:"action" binding:"required"`
+	// HallID is the hall to move bookings to for reassign_hall.
+	HallID string `json:"hallId"`
+	// Tags are added by tag and removed by untag.
+	Tags []string `json:"tags"`
+	// ChargeFee adds the reschedule fee to reassigned bookings.
+	ChargeFee bool `json:"chargeFee"`
+}
+
+// bulkItemResult is the outcome of the action for one booking.
+type bulkItemResult struct {
+	ID      string          `json:"id"`
+	OK      bool            `json:"ok"`
+	Booking *models.Booking `json:"booking,omitempty"`
+	Error   string          `json:"error,omitempty"`
+}
+
+// BulkUpdateBookings applies one action to many bookings (admin only). Each
+// booking is updated in its own transaction under the same rules as the
+// single-booking endpoints, so one refusal does not stop the rest; the
+// response reports the outcome for every ID in the order given.
+func (c *BookingController) BulkUpdateBookings(ctx *gin.Context) {
+	var request bulkBookingRequest
+	if err := ctx.ShouldBindJSON(&request); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	ids := uniqueStrings(request.IDs)
+	if len(ids) == 0 {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "No bookings selected"})
+		return
+	}
+	if len(ids) > maxBulkBookings {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "At most " + strconv.Itoa(maxBulkBookings) + " bookings can be updated at once"})
+		return
+	}
+
+	var apply func(bookingID uint64) (*models.Booking, error)
+	switch request.Action {
+	case bulkConfirm:
+		apply = func(id uint64) (*models.Booking, error) {
+			return c.changeBookingStatus(ctx, id, models.StatusConfirmed)
+		}
+	case bulkCancel:
+		apply = func(id uint64) (*models.Booking, error) {
+			return c.changeBookingStatus(ctx, id, models.StatusCancelled)
+		}
+	case bulkReassignHall:
+		admin := middlewares.CurrentAdmin(ctx)
+		if admin == nil || !middlewares.HasPermission(admin.Role, middlewares.PermManageBookings) {
+			ctx.JSON(http.StatusForbidden, gin.H{"error": "Insufficient permissions"})
+			return
+		}
+		if request.HallID == "" {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": "hallId is required to reassign bookings"})
+			return
+		}
+		if err := c.db.Select("id").First(&models.Hall{}, "id = ?", request.HallID).Error; err != nil {
+			ctx.JSON(http.StatusNotFound, gin.H{"error": "Hall not found"})
+			return
+		}
+		opts := rescheduleOptions{Actor: "admin", Audit: ctx, Notify: c.email, Links: c.links}
+		if request.ChargeFee {
+			opts.Fee = c.policy.RescheduleFee
+		}
+		apply = func(id uint64) (*models.Booking, error) {
+			return c.reassignHall(id, request.HallID, opts)
+		}
+	case bulkTag, bulkUntag:
+		tags, reason := normalizeTags(request.Tags)
+		if reason != "" {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": reason})
+			return
+		}
+		if len(tags) == 0 {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": "tags is required"})
+			return
+		}
+		add := request.Action == bulkTag
+		apply = func(id uint64) (*models.Booking, error) {
+			return c.updateTags(ctx, id, tags, add)
+		}
+	default:
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "action must be confirm, cancel, reassign_hall, tag or untag"})
+		return
+	}
+
+	results := make([]bulkItemResult, 0, len(ids))
+	succeeded := 0
+	for _, idStr := range ids {
+		result := bulkItemResult{ID: idStr}
+		id, err := strconv.ParseUint(idStr, 10, 64)
+		if err != nil {
+			result.Error = "Invalid booking ID"
+		} else if booking, err := apply(id); err != nil {
+			result.Error = bulkErrorMessage(id, err)
+		} else {
+			result.OK = true
+			result.Booking = booking
+			succeeded++
+		}
+		results = append(results, result)
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{
+		"results":   results,
+		"succeeded": succeeded,
+		"failed":    len(results) - succeeded,
+	})
+}
+
+// reassignHall moves the booking to another hall on the same date and time.
+// The customer gets the usual reschedule confirmation when opts asks for it.
+func (c *BookingController) reassignHall(bookingID uint64, hallID string, opts rescheduleOptions) (*models.Booking, error) {
+	var booking models.Booking
+	if err := c.db.First(&booking, bookingID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errBookingNotFound
+		}
+		return nil, err
+	}
+	if booking.HallID == hallID {
+		return nil, policyError("Booking is already in this hall")
+	}
+
+	result, err := rescheduleBooking(c.db, bookingID, rescheduleRequest{
+		HallID:    hallID,
+		EventDate: booking.EventDate,
+		StartTime: booking.StartTime,
+		Note:      "Hall reassigned by staff",
+	}, opts)
+	if err != nil {
+		return nil, err
+	}
+	return &result.Booking, nil
+}
+
+// updateTags adds tags to, or removes them from, the booking.
+func (c *BookingController) updateTags(ctx *gin.Context, bookingID uint64, tags []string, add bool) (*models.Booking, error) {
+	var booking models.Booking
+	err := c.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&booking, bookingID).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return errBookingNotFound
+			}
+			return err
+		}
+
+		before := booking
+		before.Tags = append(models.StringList(nil), booking.Tags...)
+		action := "booking.untagged"
+		if add {
+			action = "booking.tagged"
+			booking.Tags = uniqueStrings(append(booking.Tags, tags...))
+			if len(booking.Tags) > maxBookingTags {
+				return policyError("A booking can have at most " + strconv.Itoa(maxBookingTags) + " tags")
+			}
+		} else {
+			kept := booking.Tags[:0:0]
+			for _, t := range booking.Tags {
+				if !containsString(tags, t) {
+					kept = append(kept, t)
+				}
+			}
+			booking.Tags = kept
+		}
+		sort.Strings(booking.Tags)
+		if len(booking.Tags) == len(before.Tags) && strings.Join(booking.Tags, ",") == strings.Join(before.Tags, ",") {
+			return nil
+		}
+
+		if err := tx.Model(&booking).Update("tags", booking.Tags).Error; err != nil {
+			return err
+		}
+		return recordAudit(tx, ctx, action, "booking", strconv.FormatUint(booking.ID, 10), before, booking)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &booking, nil
+}
+
+// normalizeTags lowercases and trims tags and drops duplicates and blanks.
+// It returns a reason when a tag is unusable.
+func normalizeTags(raw []string) ([]string, string) {
+	tags := make([]string, 0, len(raw))
+	for _, t := range raw {
+		t = strings.ToLower(strings.TrimSpace(t))
+		if t == "" {
+			continue
+		}
+		if len(t) > maxTagLength {
+			return nil, "Tags must be at most " + strconv.Itoa(maxTagLength) + " characters"
+		}
+		if strings.ContainsRune(t, ',') {
+			return nil, "Tags cannot contain commas"
+		}
+		tags = append(tags, t)
+	}
+	tags = uniqueStrings(tags)
+	if len(tags) > maxBookingTags {
+		return nil, "At most " + strconv.Itoa(maxBookingTags) + " tags can be given"
+	}
+	return tags, ""
+}
+
+func bulkErrorMessage(id uint64, err error) string {
+	var policyErr policyError
+	var transitionErr statusTransitionError
+	switch {
+	case errors.As(err, &policyErr):
+		return policyErr.Error()
+	case errors.As(err, &transitionErr):
+		return transitionErr.Error()
+	case errors.Is(err, errBookingNotFound):
+		return "Booking not found"
+	case errors.Is(err, errHallNotFound):
+		return "Hall not found"
+	case errors.Is(err, errSlotTaken), errors.Is(err, errNotReschedulable), errors.Is(err, errOverCapacity),
+		errors.Is(err, errHallUnavailable), errors.Is(err, errNoSuchLayout):
+		return err.Error()
+	default:
+		log.Printf("Bulk update of booking %d failed: %v", id, err)
+		return "Failed to update booking"
+	}
+}
+
+// uniqueStrings drops blanks and repeats, keeping the first occurrence.
+func uniqueStrings(values []string) []string {
+	seen := make(map[string]bool, len(values))
+	out := make([]string, 0, len(values))
+	for _, v := range values {
+		v = strings.TrimSpace(v)
+		if v == "" || seen[v] {
+			continue
+		}
+		seen[v] = true
+		out = append(out, v)
+	}
+	return out
+}
+
+func containsString(values []string, s string) bool {
+	for _, v := range values {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,198 @@
+package controllers
+
+import (
+	"net/http"
+	"reflect"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+
+	"event-booking-backend/middlewares"
+	"event-booking-backend/models"
+	"event-booking-backend/services"
+)
+
+type bulkFixture struct {
+	db       *gorm.DB
+	notifier *services.LogNotifier
+	router   *gin.Engine
+	// bookings are pending, confirmed and cancelled, in the garden.
+	bookings []models.Booking
+}
+
+func newBulkFixture(t *testing.T, role string) *bulkFixture {
+	t.Helper()
+	t.Setenv("MANAGE_LINK_SECRET", "test-manage-secret")
+	db := newTestDB(t)
+	links, err := services.NewManageLinkService()
+	if err != nil {
+		t.Fatal(err)
+	}
+	notifier, _ := services.NewLogNotifier("")
+	bc := NewBookingController(db, services.NewMailer(notifier, nil), links, services.LoadBookingPolicy(), nil)
+
+	for _, hall := range []models.Hall{
+		{ID: "garden", Name: "Garden", Capacity: 100, BasePrice: 1000, Status: models.HallActive},
+		{ID: "terrace", Name: "Terrace", Capacity: 100, BasePrice: 1500, Status: models.HallActive},
+	} {
+		db.Create(&hall)
+	}
+	f := &bulkFixture{db: db, notifier: notifier}
+	date := time.Now().AddDate(0, 1, 0).Truncate(24 * time.Hour)
+	for i, status := range []models.BookingStatus{models.StatusPending, models.StatusConfirmed, models.StatusCancelled} {
+		booking := models.Booking{HallID: "garden", CustomerName: "Asha", CustomerEmail: "asha@example.com", CustomerPhone: "555",
+			GuestCount: 50, EventDate: date.AddDate(0, 0, i), StartTime: "10:00", EndTime: "14:00",
+			Status: status, TotalPrice: 1000, Tags: models.StringList{}}
+		if err := db.Create(&booking).Error; err != nil {
+			t.Fatal(err)
+		}
+		f.bookings = append(f.bookings, booking)
+	}
+
+	f.router = gin.New()
+	f.router.Use(func(ctx *gin.Context) {
+		ctx.Set("admin", &middlewares.AdminIdentity{UserID: 7, Email: "ops@example.com", Role: role})
+	})
+	f.router.POST("/admin/bookings/bulk", bc.BulkUpdateBookings)
+	return f
+}
+
+func (f *bulkFixture) id(i int) string {
+	return strconv.FormatUint(f.bookings[i].ID, 10)
+}
+
+type bulkResponse struct {
+	Results   []bulkItemResult `json:"results"`
+	Succeeded int              `json:"succeeded"`
+	Failed    int              `json:"failed"`
+}
+
+func (f *bulkFixture) bulk(t *testing.T, body gin.H) bulkResponse {
+	t.Helper()
+	w := serve(f.router, http.MethodPost, "/admin/bookings/bulk", body)
+	if w.Code != http.StatusOK {
+		t.Fatalf("got %d %s, want 200", w.Code, w.Body.String())
+	}
+	var resp bulkResponse
+	decode(t, w, &resp)
+	return resp
+}
+
+func TestBulkConfirmReportsEachBooking(t *testing.T) {
+	f := newBulkFixture(t, middlewares.RoleFrontDesk)
+
+	resp := f.bulk(t, gin.H{"action": "confirm", "ids": []string{f.id(0), f.id(1), f.id(2), "abc", "999", f.id(0)}})
+	want := []struct {
+		id    string
+		error string
+	}{
+		{f.id(0), ""},
+		{f.id(1), "Booking is already confirmed"},
+		{f.id(2), "Cannot change a cancelled booking to confirmed"},
+		{"abc", "Invalid booking ID"},
+		{"999", "Booking not found"},
+	}
+	if len(resp.Results) != len(want) || resp.Succeeded != 1 || resp.Failed != 4 {
+		t.Fatalf("response = %+v, want one confirmed and four refused", resp)
+	}
+	for i, w := range want {
+		got := resp.Results[i]
+		if got.ID != w.id || got.OK != (w.error == "") || got.Error != w.error {
+			t.Errorf("result %d = %+v, want %s %q", i, got, w.id, w.error)
+		}
+	}
+
+	var booking models.Booking
+	f.db.First(&booking, f.bookings[0].ID)
+	if booking.Status != models.StatusConfirmed {
+		t.Errorf("booking %s, want confirmed", booking.Status)
+	}
+	if sent := f.notifier.Sent(); len(sent) != 1 || sent[0].Template != services.TemplateBookingConfirmed {
+		t.Errorf("sent = %+v, want one confirmation", sent)
+	}
+}
+
+func TestBulkReassignHall(t *testing.T) {
+	f := newBulkFixture(t, middlewares.RoleManager)
+	// The terrace is taken on the confirmed booking's date
+	taken := f.bookings[1]
+	taken.ID, taken.HallID = 0, "terrace"
+	f.db.Create(&taken)
+
+	resp := f.bulk(t, gin.H{"action": "reassign_hall", "hallId": "terrace", "ids": []string{f.id(0), f.id(1), f.id(2)}})
+	if resp.Succeeded != 1 || !resp.Results[0].OK || resp.Results[0].Booking.HallID != "terrace" {
+		t.Fatalf("response = %+v, want the pending booking moved", resp)
+	}
+	if got := resp.Results[1].Error; got != errSlotTaken.Error() {
+		t.Errorf("taken slot: %q, want %q", got, errSlotTaken.Error())
+	}
+	if got := resp.Results[2].Error; got != errNotReschedulable.Error() {
+		t.Errorf("cancelled booking: %q, want %q", got, errNotReschedulable.Error())
+	}
+
+	resp = f.bulk(t, gin.H{"action": "reassign_hall", "hallId": "terrace", "ids": []string{f.id(0)}})
+	if got := resp.Results[0].Error; got != "Booking is already in this hall" {
+		t.Errorf("second move: %q", got)
+	}
+	var history int64
+	f.db.Model(&models.BookingHistory{}).Where("booking_id = ? AND to_hall_id = ?", f.bookings[0].ID, "terrace").Count(&history)
+	if history != 1 {
+		t.Errorf("%d moves in history, want 1", history)
+	}
+}
+
+func TestBulkReassignHallNeedsManager(t *testing.T) {
+	f := newBulkFixture(t, middlewares.RoleFrontDesk)
+	w := serve(f.router, http.MethodPost, "/admin/bookings/bulk", gin.H{"action": "reassign_hall", "hallId": "terrace", "ids": []string{f.id(0)}})
+	if w.Code != http.StatusForbidden {
+		t.Errorf("got %d %s, want 403", w.Code, w.Body.String())
+	}
+}
+
+func TestBulkTags(t *testing.T) {
+	f := newBulkFixture(t, middlewares.RoleFrontDesk)
+
+	f.bulk(t, gin.H{"action": "tag", "tags": []string{" VIP", "vip", "Corporate"}, "ids": []string{f.id(0), f.id(1)}})
+	f.bulk(t, gin.H{"action": "untag", "tags": []string{"vip"}, "ids": []string{f.id(1)}})
+
+	var first, second models.Booking
+	f.db.First(&first, f.bookings[0].ID)
+	f.db.First(&second, f.bookings[1].ID)
+	if !reflect.DeepEqual([]string(first.Tags), []string{"corporate", "vip"}) || !reflect.DeepEqual([]string(second.Tags), []string{"corporate"}) {
+		t.Errorf("tags = %v and %v, want [corporate vip] and [corporate]", first.Tags, second.Tags)
+	}
+
+	// Tagging again changes nothing, so nothing more is audited
+	f.bulk(t, gin.H{"action": "tag", "tags": []string{"vip"}, "ids": []string{f.id(0)}})
+	var audits int64
+	f.db.Model(&models.AuditLog{}).Where("action IN ?", []string{"booking.tagged", "booking.untagged"}).Count(&audits)
+	if audits != 3 {
+		t.Errorf("%d audit entries, want 3", audits)
+	}
+}
+
+func TestBulkRejectsBadRequests(t *testing.T) {
+	f := newBulkFixture(t, middlewares.RoleOwner)
+	tooMany := make([]string, maxBulkBookings+1)
+	for i := range tooMany {
+		tooMany[i] = strconv.Itoa(i + 1)
+	}
+	for name, body := range map[string]gin.H{
+		"no ids":         {"action": "confirm", "ids": []string{" "}},
+		"too many":       {"action": "confirm", "ids": tooMany},
+		"unknown action": {"action": "archive", "ids": []string{f.id(0)}},
+		"no hall":        {"action": "reassign_hall", "ids": []string{f.id(0)}},
+		"no tags":        {"action": "tag", "tags": []string{" "}, "ids": []string{f.id(0)}},
+		"comma in tag":   {"action": "tag", "tags": []string{"a,b"}, "ids": []string{f.id(0)}},
+	} {
+		if w := serve(f.router, http.MethodPost, "/admin/bookings/bulk", body); w.Code != http.StatusBadRequest {
+			t.Errorf("%s: got %d %s, want 400", name, w.Code, w.Body.String())
+		}
+	}
+	if w := serve(f.router, http.MethodPost, "/admin/bookings/bulk", gin.H{"action": "reassign_hall", "hallId": "attic", "ids": []string{f.id(0)}}); w.Code != http.StatusNotFound {
+		t.Errorf("unknown hall: got %d %s, want 404", w.Code, w.Body.String())
+	}
+}
@@ -0,0 +1,78 @@
+package controllers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"event-booking-backend/models"
+)
+
+var errOverpaidCorrection = errors.New("a correction cannot take the amount paid below zero")
+
+// RecordPayment adds a payment taken from the customer to the booking's
+// amount paid (admin only). A negative amount corrects a payment entered
+// by mistake. The payment is logged in the booking history with the
+// balance still due.
+func (c *BookingController) RecordPayment(ctx *gin.Context) {
+	bookingID, err := strconv.ParseUint(ctx.Param("id"), 10, 64)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid booking ID"})
+		return
+	}
+
+	var request struct {
+		Amount float64 `json:"amount" binding:"required"`
+		Note   string  `json:"note"`
+	}
+	if err := ctx.ShouldBindJSON(&request); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	var booking models.Booking
+	err = c.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&booking, bookingID).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return errBookingNotFound
+			}
+			return err
+		}
+		if booking.AmountPaid+request.Amount < 0 {
+			return errOverpaidCorrection
+		}
+
+		before := booking
+		booking.AmountPaid += request.Amount
+		if err := tx.Model(&booking).Update("amount_paid", booking.AmountPaid).Error; err != nil {
+			return err
+		}
+		if err := tx.Create(&models.BookingHistory{
+			BookingID:     booking.ID,
+			Action:        models.HistoryPaid,
+			Actor:         "admin",
+			PreviousPrice: booking.TotalPrice,
+			NewPrice:      booking.TotalPrice,
+			AmountPaid:    booking.AmountPaid,
+			BalanceDue:    booking.BalanceDue(),
+			Note:          request.Note,
+		}).Error; err != nil {
+			return err
+		}
+		return recordAudit(tx, ctx, "booking.payment_recorded", "booking", strconv.FormatUint(booking.ID, 10), before, booking)
+	})
+	switch {
+	case errors.Is(err, errBookingNotFound):
+		ctx.JSON(http.StatusNotFound, gin.H{"error": "Booking not found"})
+	case errors.Is(err, errOverpaidCorrection):
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case err != nil:
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to record payment"})
+	default:
+		ctx.JSON(http.StatusOK, gin.H{"booking": booking, "balanceDue": booking.BalanceDue()})
+	}
+}
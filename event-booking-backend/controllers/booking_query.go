@@ -0,0 +1,249 @@
+package controllers
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+
+	"event-booking-backend/models"
+)
+
+const (
+	bookingPageSize    = 50
+	bookingMaxPageSize = 200
+)
+
+// bookingSorts maps the sort parameter to the columns it orders by. The ID
+// is always appended as a tie-breaker so the order is total and the cursor
+// is unambiguous.
+var bookingSorts = map[string][]string{
+	"createdAt":    {"created_at"},
+	"eventDate":    {"event_date", "start_time"},
+	"totalPrice":   {"total_price"},
+	"customerName": {"customer_name"},
+}
+
+var nonDigits = regexp.MustCompile(`\D`)
+
+// bookingPage is the response of the admin booking list.
+type bookingPage struct {
+	Bookings   []models.Booking               `json:"bookings"`
+	NextCursor string                         `json:"nextCursor,omitempty"`
+	Total      int64                          `json:"total"`
+	Counts     map[models.BookingStatus]int64 `json:"counts"`
+}
+
+// bookingListQuery is a parsed booking list request.
+type bookingListQuery struct {
+	sort     string
+	desc     bool
+	limit    int
+	cursorID uint64
+}
+
+// filterBookings applies the list filters from the query string to query.
+// The status filter is left to the caller so per-status counts can share
+// the rest. Supported: hallId, dateFrom/dateTo (event date),
+// createdFrom/createdTo, q (name, email or phone), minPrice/maxPrice and
+// tag.
+func filterBookings(ctx *gin.Context, query *gorm.DB) (*gorm.DB, bool) {
+	if v := ctx.Query("hallId"); v != "" {
+		query = query.Where("hall_id IN ?", strings.Split(v, ","))
+	}
+	if v := strings.ToLower(strings.TrimSpace(ctx.Query("tag"))); v != "" {
+		tag, _ := json.Marshal([]string{v})
+		query = query.Where("tags @> CAST(? AS jsonb)", string(tag))
+	}
+
+	var ok bool
+	if query, ok = whereTimeRange(ctx, query, "dateFrom", "dateTo", "event_date"); !ok {
+		return nil, false
+	}
+	if query, ok = whereTimeRange(ctx, query, "createdFrom", "createdTo", "created_at"); !ok {
+		return nil, false
+	}
+
+	if q := strings.TrimSpace(ctx.Query("q")); q != "" {
+		pattern := "%" + escapeLike(q) + "%"
+		cond := "customer_name ILIKE ? OR customer_email ILIKE ? OR customer_phone ILIKE ?"
+		args := []interface{}{pattern, pattern, pattern}
+		// Match phone numbers regardless of how they were punctuated.
+		if digits := nonDigits.ReplaceAllString(q, ""); len(digits) >= 3 {
+			cond += ` OR regexp_replace(customer_phone, '\D', '', 'g') LIKE ?`
+			args = append(args, "%"+digits+"%")
+		}
+		query = query.Where("("+cond+")", args...)
+	}
+
+	for _, bound := range []struct{ param, cond string }{
+		{"minPrice", "total_price >= ?"},
+		{"maxPrice", "total_price <= ?"},
+	} {
+		v := ctx.Query(bound.param)
+		if v == "" {
+			continue
+		}
+		price, err := strconv.ParseFloat(v, 64)
+		if err != nil {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid " + bound.param})
+			return nil, false
+		}
+		query = query.Where(bound.cond, price)
+	}
+	return query, true
+}
+
+// bookingStatuses parses the comma-separated status filter.
+func bookingStatuses(ctx *gin.Context) ([]models.BookingStatus, bool) {
+	v := ctx.Query("status")
+	if v == "" {
+		return nil, true
+	}
+	var statuses []models.BookingStatus
+	for _, s := range strings.Split(v, ",") {
+		status := models.BookingStatus(strings.TrimSpace(s))
+		switch status {
+		case models.StatusPending, models.StatusConfirmed, models.StatusCancelled:
+			statuses = append(statuses, status)
+		default:
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("Invalid status %q", s)})
+			return nil, false
+		}
+	}
+	return statuses, true
+}
+
+// parseBookingListQuery reads sort, order, limit and cursor.
+func parseBookingListQuery(ctx *gin.Context) (*bookingListQuery, bool) {
+	q := &bookingListQuery{
+		sort:  ctx.DefaultQuery("sort", "createdAt"),
+		desc:  ctx.DefaultQuery("order", "desc") == "desc",
+		limit: bookingPageSize,
+	}
+	if _, ok := bookingSorts[q.sort]; !ok {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid sort"})
+		return nil, false
+	}
+	if order := ctx.Query("order"); order != "" && order != "asc" && order != "desc" {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "order must be asc or desc"})
+		return nil, false
+	}
+	if v := ctx.Query("limit"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 || n > bookingMaxPageSize {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("limit must be between 1 and %d", bookingMaxPageSize)})
+			return nil, false
+		}
+		q.limit = n
+	}
+	if v := ctx.Query("cursor"); v != "" {
+		id, ok := decodeBookingCursor(v, q)
+		if !ok {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid cursor"})
+			return nil, false
+		}
+		q.cursorID = id
+	}
+	return q, true
+}
+
+// page orders query and restricts it to the rows after the cursor. The
+// cursor row's own values are read in a subquery, so a page boundary stays
+// put even when rows are inserted before it.
+func (q *bookingListQuery) page(query *gorm.DB) *gorm.DB {
+	if q.cursorID != 0 {
+		cmp := ">"
+		if q.desc {
+			cmp = "<"
+		}
+		list := strings.Join(q.columns(), ", ")
+		query = query.Where(fmt.Sprintf("(%s) %s (SELECT %s FROM bookings WHERE id = ?)", list, cmp, list), q.cursorID)
+	}
+	return q.order(query).Limit(q.limit + 1)
+}
+
+// order sorts query without paging it, for exports.
+func (q *bookingListQuery) order(query *gorm.DB) *gorm.DB {
+	direction := "ASC"
+	if q.desc {
+		direction = "DESC"
+	}
+	for _, column := range q.columns() {
+		query = query.Order(column + " " + direction)
+	}
+	return query
+}
+
+func (q *bookingListQuery) columns() []string {
+	return append(append([]string{}, bookingSorts[q.sort]...), "id")
+}
+
+// cursor encodes the position after the last booking of a page. It is tied
+// to the sort so it cannot be replayed against a different order.
+func (q *bookingListQuery) cursor(last *models.Booking) string {
+	order := "asc"
+	if q.desc {
+		order = "desc"
+	}
+	raw := fmt.Sprintf("%s:%s:%d", q.sort, order, last.ID)
+	return base64.RawURLEncoding.EncodeToString([]byte(raw))
+}
+
+func decodeBookingCursor(cursor string, q *bookingListQuery) (uint64, bool) {
+	raw, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return 0, false
+	}
+	parts := strings.Split(string(raw), ":")
+	if len(parts) != 3 || parts[0] != q.sort || (parts[1] == "desc") != q.desc {
+		return 0, false
+	}
+	id, err := strconv.ParseUint(parts[2], 10, 64)
+	return id, err == nil && id != 0
+}
+
+// whereTimeRange filters column by the from and to query parameters, which
+// take RFC 3339 timestamps or dates. A date as the upper bound includes
+// that whole day.
+func whereTimeRange(ctx *gin.Context, query *gorm.DB, fromParam, toParam, column string) (*gorm.DB, bool) {
+	for _, bound := range []struct{ param, cond string }{
+		{fromParam, column + " >= ?"},
+		{toParam, column + " < ?"},
+	} {
+		v := ctx.Query(bound.param)
+		if v == "" {
+			continue
+		}
+		t, err := parseQueryTime(v)
+		if err != nil {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid " + bound.param + " date"})
+			return nil, false
+		}
+		if bound.param == toParam && len(v) == len("2006-01-02") {
+			t = t.AddDate(0, 0, 1)
+		}
+		query = query.Where(bound.cond, t)
+	}
+	return query, true
+}
+
+// parseQueryTime accepts RFC 3339 timestamps and plain dates.
+func parseQueryTime(v string) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339, v); err == nil {
+		return t, nil
+	}
+	return time.Parse("2006-01-02", v)
+}
+
+// escapeLike escapes the LIKE wildcards in s.
+func escapeLike(s string) string {
+	return strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(s)
+}
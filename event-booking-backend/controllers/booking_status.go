@@ -0,0 +1,116 @@
+package controllers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"event-booking-backend/models"
+)
+
+// statusTransitions lists the statuses an admin may move a booking to from
+// each status. Cancelled is final.
+var statusTransitions = map[models.BookingStatus][]models.BookingStatus{
+	models.StatusPending:   {models.StatusConfirmed, models.StatusCancelled},
+	models.StatusConfirmed: {models.StatusCancelled},
+}
+
+// statusTransitionError is returned when the status rules refuse a change.
+type statusTransitionError struct {
+	from, to models.BookingStatus
+}
+
+func (e statusTransitionError) Error() string {
+	if e.from == e.to {
+		return fmt.Sprintf("Booking is already %s", e.to)
+	}
+	return fmt.Sprintf("Cannot change a %s booking to %s", e.from, e.to)
+}
+
+func validStatus(status models.BookingStatus) bool {
+	_, ok := statusTransitions[status]
+	return ok || status == models.StatusCancelled
+}
+
+func canTransition(from, to models.BookingStatus) bool {
+	return containsStatus(statusTransitions[from], to)
+}
+
+// changeBookingStatus moves the booking to status under the status rules,
+// logging cancellations in the booking history and the change in the audit
+// log. The customer's email is queued with the change.
+func (c *BookingController) changeBookingStatus(ctx *gin.Context, bookingID uint64, status models.BookingStatus) (*models.Booking, error) {
+	var booking models.Booking
+	err := c.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&booking, bookingID).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return errBookingNotFound
+			}
+			return err
+		}
+		if !canTransition(booking.Status, status) {
+			return statusTransitionError{from: booking.Status, to: status}
+		}
+
+		before := booking
+		booking.Status = status
+		if status == models.StatusCancelled {
+			now := time.Now()
+			booking.CancelledAt = &now
+		}
+		if err := tx.Save(&booking).Error; err != nil {
+			return err
+		}
+		if status == models.StatusCancelled {
+			if err := tx.Create(&models.BookingHistory{
+				BookingID:     booking.ID,
+				Action:        models.HistoryCancelled,
+				Actor:         "admin",
+				PreviousPrice: booking.TotalPrice,
+				NewPrice:      booking.TotalPrice,
+				Note:          "Cancelled by staff",
+			}).Error; err != nil {
+				return err
+			}
+		}
+		if err := recordAudit(tx, ctx, "booking.status_updated", "booking", strconv.FormatUint(booking.ID, 10), before, booking); err != nil {
+			return err
+		}
+		return c.notifyStatusChange(tx, &booking)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &booking, nil
+}
+
+// notifyStatusChange queues the customer's email about the booking's new
+// status in tx.
+func (c *BookingController) notifyStatusChange(tx *gorm.DB, booking *models.Booking) error {
+	switch booking.Status {
+	case models.StatusConfirmed:
+		return c.email.In(tx).SendStatusConfirmed(booking)
+	case models.StatusCancelled:
+		// Staff cancellations decide no refund, so the email states none
+		return c.email.In(tx).SendCancellationConfirmation(booking, nil)
+	}
+	return nil
+}
+
+func writeStatusError(ctx *gin.Context, err error) {
+	var transitionErr statusTransitionError
+	switch {
+	case errors.As(err, &transitionErr):
+		ctx.JSON(http.StatusConflict, gin.H{"error": transitionErr.Error()})
+	case errors.Is(err, errBookingNotFound):
+		ctx.JSON(http.StatusNotFound, gin.H{"error": "Booking not found"})
+	default:
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update booking"})
+	}
+}
@@ -1,112 +1,249 @@
 package controllers
 
 import (
-	"log"
+	"encoding/json"
 	"net/http"
-	"os"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"gorm.io/gorm"
 
 	"event-booking-backend/models"
-	"event-booking-backend/utils"
+	"event-booking-backend/services"
 )
 
-// ContactController handles all contact form related operations
+const (
+	contactPageSize    = 50
+	contactMaxPageSize = 200
+)
+
+// ContactController handles all contact form related operations: the
+// public form and the ticket inbox staff work through.
 type ContactController struct {
-	DB *gorm.DB
+	DB    *gorm.DB
+	SLA   services.TicketSLA
+	Email *services.Mailer
+	Guard *services.SpamGuard
+}
+
+func NewContactController(db *gorm.DB, sla services.TicketSLA, email *services.Mailer, guard *services.SpamGuard) *ContactController {
+	return &ContactController{DB: db, SLA: sla, Email: email, Guard: guard}
+}
+
+// contactRequest is what the public contact form may set; the ID, status
+// and timestamps are the server's.
+type contactRequest struct {
+	Name    string `json:"name" binding:"required,max=200"`
+	Email   string `json:"email" binding:"required,email,max=320"`
+	Phone   string `json:"phone" binding:"max=50"`
+	Subject string `json:"subject" binding:"required,max=200"`
+	Message string `json:"message" binding:"required,max=5000"`
+	// Locale picks the language of the replies; the Accept-Language
+	// header is used when it is not given.
+	Locale string `json:"locale"`
+	// Website is a honeypot: the form hides it, so only bots fill it in.
+	Website      string `json:"website"`
+	CaptchaToken string `json:"captchaToken"`
+}
+
+// contactPage is the response of the admin contact list.
+type contactPage struct {
+	Contacts     []models.Contact `json:"contacts"`
+	NextBeforeID uint             `json:"nextBeforeId,omitempty"`
+	Total        int64            `json:"total"`
+	Counts       map[string]int64 `json:"counts"`
 }
 
 // CreateContact handles new contact form submissions
 func (cc *ContactController) CreateContact(c *gin.Context) {
-	var contact models.Contact
-	if err := c.ShouldBindJSON(&contact); err != nil {
+	var request contactRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
-
-	// Create the contact entry
-	if err := cc.DB.Create(&contact).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create contact entry"})
+	// Refuse bots and hold anything suspicious for review
+	reasons, ok := screenSubmission(c, cc.Guard, services.Submission{
+		Kind:         services.SubmissionContact,
+		Email:        request.Email,
+		Honeypot:     request.Website,
+		CaptchaToken: request.CaptchaToken,
+		Text:         []string{request.Name, request.Subject, request.Message},
+	})
+	if !ok {
 		return
 	}
-
-	// Send acknowledgment email
-	emailConfig := utils.EmailConfig{
-		SMTPHost:     os.Getenv("SMTP_HOST"),
-		SMTPPort:     os.Getenv("SMTP_PORT"),
-		SMTPUsername: os.Getenv("SMTP_USERNAME"),
-		SMTPPassword: os.Getenv("SMTP_PASSWORD"),
-		FromEmail:    os.Getenv("FROM_EMAIL"),
+	now := time.Now()
+	firstResponseDue, resolutionDue := now.Add(cc.SLA.FirstResponse), now.Add(cc.SLA.Resolution)
+	contact := models.Contact{
+		Name:               strings.TrimSpace(request.Name),
+		Email:              normalizeEmail(request.Email),
+		Phone:              strings.TrimSpace(request.Phone),
+		Subject:            strings.TrimSpace(request.Subject),
+		Message:            strings.TrimSpace(request.Message),
+		Status:             models.ContactOpen,
+		Tags:               models.StringList{},
+		Locale:             requestLocale(c, request.Locale),
+		FirstResponseDueAt: &firstResponseDue,
+		ResolutionDueAt:    &resolutionDue,
 	}
 
-	if err := utils.SendContactFormAcknowledgment(contact.Email, contact.Name, emailConfig); err != nil {
-		// Log the error but don't return it to the client
-		log.Printf("Failed to send acknowledgment email: %v", err)
+	// Create the contact entry
+	err := cc.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&contact).Error; err != nil {
+			return err
+		}
+		if len(reasons) > 0 {
+			return holdForReview(tx, c, models.ReviewContact, strconv.FormatUint(uint64(contact.ID), 10), contact.Email, reasons)
+		}
+		// Queue the acknowledgment; a message held for review is
+		// acknowledged once staff approve it
+		return cc.Email.In(tx).SendContactAcknowledgment(&contact)
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create contact entry"})
+		return
 	}
 
 	c.JSON(http.StatusCreated, contact)
 }
 
-// GetContacts retrieves all contact form submissions
+// GetContacts returns tickets newest first, with the total and the count
+// per status (admin only). Filters: status and those of filterContacts.
+// Pass nextBeforeId as beforeId to get the next page.
 func (cc *ContactController) GetContacts(c *gin.Context) {
-	var contacts []models.Contact
-	if err := cc.DB.Find(&contacts).Error; err != nil {
+	limit := contactPageSize
+	if v := c.Query("limit"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 || n > contactMaxPageSize {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "limit must be between 1 and 200"})
+			return
+		}
+		limit = n
+	}
+	filtered, ok := filterContacts(c, cc.DB.Model(&models.Contact{}))
+	if !ok {
+		return
+	}
+	filtered = filtered.Session(&gorm.Session{})
+
+	// Counts ignore the status filter so every status tab can show its own
+	var counts []struct {
+		Status string
+		Count  int64
+	}
+	if err := filtered.Select("status, COUNT(*) AS count").Group("status").Scan(&counts).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to count contacts"})
+		return
+	}
+	page := contactPage{Contacts: []models.Contact{}, Counts: map[string]int64{}}
+	for _, row := range counts {
+		page.Counts[row.Status] = row.Count
+	}
+
+	query := filtered
+	if v := c.Query("status"); v != "" {
+		query = query.Where("status IN ?", strings.Split(v, ","))
+	}
+	if err := query.Count(&page.Total).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to count contacts"})
+		return
+	}
+	if v := c.Query("beforeId"); v != "" {
+		id, err := strconv.ParseUint(v, 10, 64)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid beforeId"})
+			return
+		}
+		query = query.Where("id < ?", id)
+	}
+	// One extra row tells whether there is a next page
+	if err := query.Order("id DESC").Limit(limit + 1).Find(&page.Contacts).Error; err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch contacts"})
 		return
 	}
-	c.JSON(http.StatusOK, contacts)
+	if len(page.Contacts) > limit {
+		page.Contacts = page.Contacts[:limit]
+		page.NextBeforeID = page.Contacts[limit-1].ID
+	}
+	c.JSON(http.StatusOK, page)
+}
+
+// filterContacts applies the contact filters from the query string, except
+// status, which GetContacts applies after counting: q (name, email or
+// subject), from and to (received date), assigneeId (an admin ID, "me" or
+// "none"), bookingId, tag, and overdue=true for unresolved tickets past an
+// SLA deadline.
+func filterContacts(c *gin.Context, query *gorm.DB) (*gorm.DB, bool) {
+	if q := strings.TrimSpace(c.Query("q")); q != "" {
+		pattern := "%" + escapeLike(q) + "%"
+		query = query.Where("(name ILIKE ? OR email ILIKE ? OR subject ILIKE ?)", pattern, pattern, pattern)
+	}
+	switch v := c.Query("assigneeId"); v {
+	case "":
+	case "none":
+		query = query.Where("assignee_id IS NULL")
+	case "me":
+		query = query.Where("assignee_id = ?", c.GetUint("user_id"))
+	default:
+		id, err := strconv.ParseUint(v, 10, 64)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid assigneeId"})
+			return nil, false
+		}
+		query = query.Where("assignee_id = ?", id)
+	}
+	if v := c.Query("bookingId"); v != "" {
+		id, err := strconv.ParseUint(v, 10, 64)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid bookingId"})
+			return nil, false
+		}
+		query = query.Where("booking_id = ?", id)
+	}
+	if v := c.Query("tag"); v != "" {
+		tag, _ := json.Marshal([]string{strings.ToLower(v)})
+		query = query.Where("tags @> CAST(? AS jsonb)", string(tag))
+	}
+	if c.Query("overdue") == "true" {
+		now := time.Now()
+		query = query.Where(`status != ? AND ((first_response_at IS NULL AND first_response_due_at < ?)
+	OR resolution_due_at < ?)`, models.ContactResolved, now, now)
+	}
+	return whereTimeRange(c, query, "from", "to", "created_at")
 }
 
-// GetContact retrieves a specific contact by ID
+// GetContact returns a ticket with its thread of replies and notes (admin
+// only)
 func (cc *ContactController) GetContact(c *gin.Context) {
-	id := c.Param("id")
 	var contact models.Contact
-	if err := cc.DB.First(&contact, id).Error; err != nil {
+	if err := cc.DB.Preload("Messages", func(db *gorm.DB) *gorm.DB {
+		return db.Order("created_at, id")
+	}).First(&contact, "id = ?", c.Param("id")).Error; err != nil {
 		c.JSON(http.StatusNotFound, gin.H{"error": "Contact not found"})
 		return
 	}
 	c.JSON(http.StatusOK, contact)
 }
 
-// UpdateContactStatus updates the status of a contact form submission
+// UpdateContactStatus moves a ticket to open, pending or resolved (admin
+// only)
 func (cc *ContactController) UpdateContactStatus(c *gin.Context) {
-	id := c.Param("id")
-	var status struct {
+	var request struct {
 		Status string `json:"status" binding:"required"`
 	}
-
-	if err := c.ShouldBindJSON(&status); err != nil {
+	if err := c.ShouldBindJSON(&request); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
-
-	// Validate status value
-	if status.Status != "unread" && status.Status != "read" && status.Status != "replied" {
+	if !validContactStatus(request.Status) {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid status value"})
 		return
 	}
 
-	// Update contact status
-	var contact models.Contact
-	if err := cc.DB.First(&contact, id).Error; err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "Contact not found"})
-		return
-	}
-
-	if err := cc.DB.Model(&contact).Update("status", status.Status).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update contact status"})
-		return
-	}
-
-	c.JSON(http.StatusOK, contact)
-
-	if err := c.ShouldBindJSON(&status); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
-		return
-	}
-
-	// TODO: Add database update
-
-	c.JSON(http.StatusOK, gin.H{"message": "Status updated", "id": id, "status": status.Status})
+	cc.updateContact(c, "contact.status_updated", func(tx *gorm.DB, contact *models.Contact) error {
+		setContactStatus(contact, request.Status, time.Now())
+		return nil
+	})
 }
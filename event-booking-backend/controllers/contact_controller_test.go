@@ -0,0 +1,228 @@
+package controllers
+
+import (
+	"net/http"
+	"strconv"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+
+	"event-booking-backend/models"
+	"event-booking-backend/services"
+)
+
+// newContactRouter serves the contact routes over db, mailing through the
+// returned log notifier.
+func newContactRouter(t *testing.T, db *gorm.DB) (*gin.Engine, *services.LogNotifier) {
+	t.Helper()
+	return newGuardedContactRouter(t, db, services.NewSpamGuard(db, services.LoadSpamPolicy(), nil))
+}
+
+// newGuardedContactRouter is newContactRouter screening through guard.
+func newGuardedContactRouter(t *testing.T, db *gorm.DB, guard *services.SpamGuard) (*gin.Engine, *services.LogNotifier) {
+	t.Helper()
+	notifier, err := services.NewLogNotifier("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	cc := NewContactController(db, services.LoadTicketSLA(), services.NewMailer(notifier, nil), guard)
+
+	router := gin.New()
+	router.POST("/contacts", cc.CreateContact)
+	router.GET("/admin/contacts", cc.GetContacts)
+	router.GET("/admin/contacts/:id", cc.GetContact)
+	router.PUT("/admin/contacts/:id/status", cc.UpdateContactStatus)
+	return router, notifier
+}
+
+// seedContacts stores n open tickets, IDs 1 to n.
+func seedContacts(t *testing.T, db *gorm.DB, n int) {
+	t.Helper()
+	for i := 1; i <= n; i++ {
+		contact := models.Contact{
+			Name:    "Customer " + strconv.Itoa(i),
+			Email:   "customer" + strconv.Itoa(i) + "@example.com",
+			Subject: "Question " + strconv.Itoa(i),
+			Message: "Hello",
+			Status:  models.ContactOpen,
+			Tags:    models.StringList{},
+		}
+		if err := db.Create(&contact).Error; err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestCreateContact(t *testing.T) {
+	db := newTestDB(t)
+	router, notifier := newContactRouter(t, db)
+
+	w := serve(router, http.MethodPost, "/contacts", gin.H{
+		"name":    " Meena ",
+		"email":   "Meena@Example.com",
+		"subject": "Parking",
+		"message": "Is there parking for 40 cars?",
+	})
+	if w.Code != http.StatusCreated {
+		t.Fatalf("create: got %d %s, want 201", w.Code, w.Body.String())
+	}
+	var contact models.Contact
+	if err := db.First(&contact).Error; err != nil {
+		t.Fatal(err)
+	}
+	if contact.Name != "Meena" || contact.Email != "meena@example.com" || contact.Status != models.ContactOpen {
+		t.Errorf("stored contact = %+v", contact)
+	}
+	if contact.FirstResponseDueAt == nil || contact.ResolutionDueAt == nil {
+		t.Error("SLA due dates not set")
+	}
+
+	sent := notifier.Sent()
+	if len(sent) != 1 || sent[0].Template != services.TemplateContactAcknowledgment {
+		t.Fatalf("sent = %+v, want one acknowledgment", sent)
+	}
+	if sent[0].To[0].Email != "meena@example.com" {
+		t.Errorf("acknowledgment to %s", sent[0].To[0].Email)
+	}
+}
+
+func TestCreateContactRejects(t *testing.T) {
+	tests := []struct {
+		name string
+		body gin.H
+	}{
+		{"honeypot", gin.H{"name": "Bot", "email": "bot@example.com", "subject": "Hi", "message": "Buy now", "website": "http://spam.example"}},
+		{"missing message", gin.H{"name": "Meena", "email": "meena@example.com", "subject": "Parking"}},
+		{"invalid email", gin.H{"name": "Meena", "email": "not-an-email", "subject": "Parking", "message": "Hello"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db := newTestDB(t)
+			router, notifier := newContactRouter(t, db)
+
+			w := serve(router, http.MethodPost, "/contacts", tt.body)
+			if w.Code != http.StatusBadRequest {
+				t.Fatalf("got %d %s, want 400", w.Code, w.Body.String())
+			}
+			var count int64
+			db.Model(&models.Contact{}).Count(&count)
+			if count != 0 {
+				t.Errorf("%d contacts stored, want none", count)
+			}
+			if sent := notifier.Sent(); len(sent) != 0 {
+				t.Errorf("sent %d messages, want none", len(sent))
+			}
+		})
+	}
+}
+
+func TestGetContactsPagination(t *testing.T) {
+	db := newTestDB(t)
+	router, _ := newContactRouter(t, db)
+	seedContacts(t, db, 5)
+
+	var ids []uint
+	path := "/admin/contacts?limit=2"
+	for pages := 0; pages < 5; pages++ {
+		w := serve(router, http.MethodGet, path, nil)
+		if w.Code != http.StatusOK {
+			t.Fatalf("GET %s: got %d %s", path, w.Code, w.Body.String())
+		}
+		var page contactPage
+		decode(t, w, &page)
+		if page.Total != 5 || page.Counts[models.ContactOpen] != 5 {
+			t.Errorf("GET %s: total %d, counts %v", path, page.Total, page.Counts)
+		}
+		for _, contact := range page.Contacts {
+			ids = append(ids, contact.ID)
+		}
+		if page.NextBeforeID == 0 {
+			break
+		}
+		path = "/admin/contacts?limit=2&beforeId=" + strconv.FormatUint(uint64(page.NextBeforeID), 10)
+	}
+
+	want := []uint{5, 4, 3, 2, 1}
+	if len(ids) != len(want) {
+		t.Fatalf("paged through %v, want %v", ids, want)
+	}
+	for i := range want {
+		if ids[i] != want[i] {
+			t.Fatalf("paged through %v, want %v", ids, want)
+		}
+	}
+}
+
+func TestGetContactsBadQuery(t *testing.T) {
+	db := newTestDB(t)
+	router, _ := newContactRouter(t, db)
+
+	for _, query := range []string{"limit=0", "limit=201", "limit=ten", "beforeId=x", "assigneeId=x"} {
+		if w := serve(router, http.MethodGet, "/admin/contacts?"+query, nil); w.Code != http.StatusBadRequest {
+			t.Errorf("?%s: got %d, want 400", query, w.Code)
+		}
+	}
+	if w := serve(router, http.MethodGet, "/admin/contacts?limit=200", nil); w.Code != http.StatusOK {
+		t.Errorf("?limit=200: got %d, want 200", w.Code)
+	}
+}
+
+func TestGetContact(t *testing.T) {
+	db := newTestDB(t)
+	router, _ := newContactRouter(t, db)
+	seedContacts(t, db, 1)
+	db.Create(&models.ContactMessage{ContactID: 1, Kind: models.ContactNote, Body: "Called back"})
+
+	w := serve(router, http.MethodGet, "/admin/contacts/1", nil)
+	if w.Code != http.StatusOK {
+		t.Fatalf("got %d %s, want 200", w.Code, w.Body.String())
+	}
+	var contact models.Contact
+	decode(t, w, &contact)
+	if contact.ID != 1 || len(contact.Messages) != 1 || contact.Messages[0].Body != "Called back" {
+		t.Errorf("contact = %+v", contact)
+	}
+
+	if w := serve(router, http.MethodGet, "/admin/contacts/99", nil); w.Code != http.StatusNotFound {
+		t.Errorf("missing contact: got %d, want 404", w.Code)
+	}
+}
+
+func TestUpdateContactStatus(t *testing.T) {
+	db := newTestDB(t)
+	router, _ := newContactRouter(t, db)
+	seedContacts(t, db, 1)
+
+	w := serve(router, http.MethodPut, "/admin/contacts/1/status", gin.H{"status": models.ContactResolved})
+	if w.Code != http.StatusOK {
+		t.Fatalf("resolve: got %d %s, want 200", w.Code, w.Body.String())
+	}
+	var contact models.Contact
+	db.First(&contact, 1)
+	if contact.Status != models.ContactResolved || contact.ResolvedAt == nil {
+		t.Errorf("after resolving: status %s, resolvedAt %v", contact.Status, contact.ResolvedAt)
+	}
+	var audits int64
+	db.Model(&models.AuditLog{}).Where("action = ? AND entity_id = ?", "contact.status_updated", "1").Count(&audits)
+	if audits != 1 {
+		t.Errorf("%d audit entries, want 1", audits)
+	}
+
+	// Reopening clears the resolution time
+	if w := serve(router, http.MethodPut, "/admin/contacts/1/status", gin.H{"status": models.ContactOpen}); w.Code != http.StatusOK {
+		t.Fatalf("reopen: got %d %s", w.Code, w.Body.String())
+	}
+	contact = models.Contact{}
+	db.First(&contact, 1)
+	if contact.Status != models.ContactOpen || contact.ResolvedAt != nil {
+		t.Errorf("after reopening: status %s, resolvedAt %v", contact.Status, contact.ResolvedAt)
+	}
+
+	if w := serve(router, http.MethodPut, "/admin/contacts/1/status", gin.H{"status": "closed"}); w.Code != http.StatusBadRequest {
+		t.Errorf("invalid status: got %d, want 400", w.Code)
+	}
+	if w := serve(router, http.MethodPut, "/admin/contacts/99/status", gin.H{"status": models.ContactResolved}); w.Code != http.StatusNotFound {
+		t.Errorf("missing contact: got %d, want 404", w.Code)
+	}
+}
@@ -0,0 +1,264 @@
+package controllers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"event-booking-backend/middlewares"
+	"event-booking-backend/models"
+)
+
+const maxContactMessageLength = 10000
+
+var errContactNotFound = errors.New("contact not found")
+
+// contactInputError is a request that names something that cannot be used,
+// such as an inactive assignee.
+type contactInputError string
+
+func (e contactInputError) Error() string { return string(e) }
+
+func validContactStatus(status string) bool {
+	switch status {
+	case models.ContactOpen, models.ContactPending, models.ContactResolved:
+		return true
+	}
+	return false
+}
+
+// setContactStatus moves contact to status, stamping ResolvedAt when it is
+// resolved and clearing it when it is reopened.
+func setContactStatus(contact *models.Contact, status string, now time.Time) {
+	if status == models.ContactResolved && contact.ResolvedAt == nil {
+		contact.ResolvedAt = &now
+	} else if status != models.ContactResolved {
+		contact.ResolvedAt = nil
+	}
+	contact.Status = status
+}
+
+// contactSubject is the subject of mail about contact. The ticket number
+// is for people to refer to; replies are matched by their signed reply
+// address, not by it.
+func contactSubject(contact *models.Contact) string {
+	return "Re: " + contact.Subject + " [#" + strconv.FormatUint(uint64(contact.ID), 10) + "]"
+}
+
+// updateContact applies change to the ticket named in the path while
+// holding its row lock, saves it and audits it under action.
+func (cc *ContactController) updateContact(c *gin.Context, action string, change func(tx *gorm.DB, contact *models.Contact) error) {
+	var contact models.Contact
+	err := cc.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&contact, "id = ?", c.Param("id")).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return errContactNotFound
+			}
+			return err
+		}
+		before := contact
+		if err := change(tx, &contact); err != nil {
+			return err
+		}
+		if err := tx.Omit(clause.Associations).Save(&contact).Error; err != nil {
+			return err
+		}
+		id := strconv.FormatUint(uint64(contact.ID), 10)
+		return recordAudit(tx, c, action, "contact", id, before, contact)
+	})
+	writeContactResult(c, err, http.StatusOK, contact)
+}
+
+func writeContactResult(c *gin.Context, err error, status int, body interface{}) {
+	var inputErr contactInputError
+	switch {
+	case errors.Is(err, errContactNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "Contact not found"})
+	case errors.As(err, &inputErr):
+		c.JSON(http.StatusBadRequest, gin.H{"error": inputErr.Error()})
+	case err != nil:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update contact"})
+	default:
+		c.JSON(status, body)
+	}
+}
+
+// contactMessageBody reads the body of a reply or note, writing a 400 and
+// returning false when it is missing or too long.
+func contactMessageBody(c *gin.Context, body string) (string, bool) {
+	body = strings.TrimSpace(body)
+	if body == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "body is required"})
+		return "", false
+	}
+	if len(body) > maxContactMessageLength {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "body must be at most 10000 characters"})
+		return "", false
+	}
+	return body, true
+}
+
+// newContactMessage starts a thread entry written by the admin behind c.
+func newContactMessage(c *gin.Context, contactID uint, kind models.ContactMessageKind, body string) models.ContactMessage {
+	message := models.ContactMessage{ContactID: contactID, Kind: kind, Body: body}
+	if admin := middlewares.CurrentAdmin(c); admin != nil {
+		message.AuthorID = &admin.UserID
+		message.AuthorName = admin.Email
+	}
+	return message
+}
+
+// ReplyToContact adds a reply to the thread and queues it to be emailed to
+// the customer (admin only). The ticket then waits on the customer
+// (pending) unless status says otherwise; the first reply meets the
+// first-response SLA.
+func (cc *ContactController) ReplyToContact(c *gin.Context) {
+	var request struct {
+		Body   string `json:"body"`
+		Status string `json:"status"`
+	}
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	body, ok := contactMessageBody(c, request.Body)
+	if !ok {
+		return
+	}
+	if request.Status == "" {
+		request.Status = models.ContactPending
+	}
+	if !validContactStatus(request.Status) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid status value"})
+		return
+	}
+
+	cc.updateContact(c, "contact.replied", func(tx *gorm.DB, contact *models.Contact) error {
+		message := newContactMessage(c, contact.ID, models.ContactReply, body)
+		if err := tx.Create(&message).Error; err != nil {
+			return err
+		}
+		if err := cc.Email.In(tx).SendContactReply(contact, contactSubject(contact), body); err != nil {
+			return err
+		}
+		now := time.Now()
+		if contact.FirstResponseAt == nil {
+			contact.FirstResponseAt = &now
+		}
+		setContactStatus(contact, request.Status, now)
+		contact.Messages = []models.ContactMessage{message}
+		return nil
+	})
+}
+
+// AddContactNote adds an internal note to the thread (admin only). The
+// customer is not told.
+func (cc *ContactController) AddContactNote(c *gin.Context) {
+	var request struct {
+		Body string `json:"body"`
+	}
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	body, ok := contactMessageBody(c, request.Body)
+	if !ok {
+		return
+	}
+
+	var message models.ContactMessage
+	err := cc.DB.Transaction(func(tx *gorm.DB) error {
+		var contact models.Contact
+		if err := tx.First(&contact, "id = ?", c.Param("id")).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return errContactNotFound
+			}
+			return err
+		}
+		message = newContactMessage(c, contact.ID, models.ContactNote, body)
+		if err := tx.Create(&message).Error; err != nil {
+			return err
+		}
+		return recordAudit(tx, c, "contact.note_added", "contact", strconv.FormatUint(uint64(contact.ID), 10), nil, message)
+	})
+	writeContactResult(c, err, http.StatusCreated, message)
+}
+
+// AssignContact hands a ticket to a staff member, or back to nobody when
+// assigneeId is null (admin only).
+func (cc *ContactController) AssignContact(c *gin.Context) {
+	var request struct {
+		AssigneeID *uint `json:"assigneeId"`
+	}
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	cc.updateContact(c, "contact.assigned", func(tx *gorm.DB, contact *models.Contact) error {
+		if request.AssigneeID != nil {
+			var count int64
+			if err := tx.Model(&models.AdminUser{}).Where("id = ? AND active", *request.AssigneeID).Count(&count).Error; err != nil {
+				return err
+			}
+			if count == 0 {
+				return contactInputError("The assignee must be an active staff member")
+			}
+		}
+		contact.AssigneeID = request.AssigneeID
+		return nil
+	})
+}
+
+// LinkContactBooking ties a ticket to the booking it is about, or unlinks
+// it when bookingId is null (admin only).
+func (cc *ContactController) LinkContactBooking(c *gin.Context) {
+	var request struct {
+		BookingID *uint64 `json:"bookingId,string"`
+	}
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	cc.updateContact(c, "contact.booking_linked", func(tx *gorm.DB, contact *models.Contact) error {
+		if request.BookingID != nil {
+			var count int64
+			if err := tx.Model(&models.Booking{}).Where("id = ?", *request.BookingID).Count(&count).Error; err != nil {
+				return err
+			}
+			if count == 0 {
+				return contactInputError("Booking not found")
+			}
+		}
+		contact.BookingID = request.BookingID
+		return nil
+	})
+}
+
+// SetContactTags replaces a ticket's tags (admin only).
+func (cc *ContactController) SetContactTags(c *gin.Context) {
+	var request struct {
+		Tags []string `json:"tags"`
+	}
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	tags, problem := normalizeTags(request.Tags)
+	if problem != "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": problem})
+		return
+	}
+
+	cc.updateContact(c, "contact.tagged", func(tx *gorm.DB, contact *models.Contact) error {
+		contact.Tags = tags
+		return nil
+	})
+}
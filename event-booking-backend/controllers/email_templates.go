@@ -0,0 +1,64 @@
+package controllers
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"event-booking-backend/services"
+)
+
+// EmailTemplateController lets staff see the emails the system sends.
+type EmailTemplateController struct{}
+
+func NewEmailTemplateController() *EmailTemplateController {
+	return &EmailTemplateController{}
+}
+
+// ListEmailTemplates returns the template names and the locales they can
+// be rendered in.
+func (c *EmailTemplateController) ListEmailTemplates(ctx *gin.Context) {
+	ctx.JSON(http.StatusOK, gin.H{
+		"templates":     services.EmailTemplates,
+		"locales":       services.Locales,
+		"defaultLocale": services.DefaultLocale,
+	})
+}
+
+// PreviewEmailTemplate renders a template with sample booking data. The
+// locale defaults to English; format=html or format=text returns just that
+// part, so it can be opened in a browser, otherwise the subject and both
+// parts come back as JSON.
+func (c *EmailTemplateController) PreviewEmailTemplate(ctx *gin.Context) {
+	locale := ctx.DefaultQuery("locale", services.DefaultLocale)
+	if services.MatchLocale(locale) != locale {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Unsupported locale"})
+		return
+	}
+
+	msg, err := services.PreviewEmail(ctx.Param("name"), locale)
+	if errors.Is(err, services.ErrUnknownEmailTemplate) {
+		ctx.JSON(http.StatusNotFound, gin.H{"error": "Email template not found"})
+		return
+	}
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to render email template: " + err.Error()})
+		return
+	}
+
+	switch ctx.Query("format") {
+	case "html":
+		ctx.Data(http.StatusOK, "text/html; charset=utf-8", []byte(msg.HTML))
+	case "text":
+		ctx.Data(http.StatusOK, "text/plain; charset=utf-8", []byte(msg.Text))
+	default:
+		ctx.JSON(http.StatusOK, gin.H{
+			"template": msg.Template,
+			"locale":   msg.Locale,
+			"subject":  msg.Subject,
+			"html":     msg.HTML,
+			"text":     msg.Text,
+		})
+	}
+}
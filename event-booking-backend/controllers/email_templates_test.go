@@ -0,0 +1,56 @@
+package controllers
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+
+	"event-booking-backend/services"
+)
+
+func TestPreviewEmailTemplate(t *testing.T) {
+	router := gin.New()
+	router.GET("/admin/email-templates/:name/preview", NewEmailTemplateController().PreviewEmailTemplate)
+	path := "/admin/email-templates/"
+
+	w := serve(router, http.MethodGet, path+services.TemplateBookingConfirmation+"/preview?locale=ta", nil)
+	if w.Code != http.StatusOK {
+		t.Fatalf("got %d %s, want 200", w.Code, w.Body.String())
+	}
+	var preview struct{ Template, Locale, Subject, HTML, Text string }
+	decode(t, w, &preview)
+	if preview.Template != services.TemplateBookingConfirmation || preview.Locale != "ta" || preview.Subject == "" || preview.Text == "" {
+		t.Errorf("preview = %+v", preview)
+	}
+
+	// Templates without a translation come back in English
+	w = serve(router, http.MethodGet, path+services.TemplateAdminNewBooking+"/preview?locale=hi", nil)
+	decode(t, w, &preview)
+	if w.Code != http.StatusOK || preview.Locale != services.DefaultLocale {
+		t.Errorf("untranslated template: got %d in %q, want 200 in English", w.Code, preview.Locale)
+	}
+
+	w = serve(router, http.MethodGet, path+services.TemplateBookingConfirmation+"/preview?format=html", nil)
+	if w.Code != http.StatusOK || !strings.HasPrefix(w.Header().Get("Content-Type"), "text/html") || !strings.Contains(w.Body.String(), "</html>") {
+		t.Errorf("format=html: got %d %s", w.Code, w.Header().Get("Content-Type"))
+	}
+	w = serve(router, http.MethodGet, path+services.TemplateBookingConfirmation+"/preview?format=text", nil)
+	if w.Code != http.StatusOK || !strings.HasPrefix(w.Header().Get("Content-Type"), "text/plain") {
+		t.Errorf("format=text: got %d %s", w.Code, w.Header().Get("Content-Type"))
+	}
+
+	for _, tt := range []struct {
+		path string
+		want int
+	}{
+		{path + services.TemplateBookingConfirmation + "/preview?locale=fr", http.StatusBadRequest},
+		{path + services.TemplateBookingConfirmation + "/preview?locale=ta-IN", http.StatusBadRequest},
+		{path + "no_such_template/preview", http.StatusNotFound},
+	} {
+		if w := serve(router, http.MethodGet, tt.path, nil); w.Code != tt.want {
+			t.Errorf("%s: got %d %s, want %d", tt.path, w.Code, w.Body.String(), tt.want)
+		}
+	}
+}
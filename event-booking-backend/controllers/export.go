@@ -0,0 +1,186 @@
+package controllers
+
+import (
+	"database/sql"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+
+	"event-booking-backend/models"
+	"event-booking-backend/services"
+)
+
+// ExportController streams bookings, payments and contact messages as CSV
+// or XLSX. Rows are read with a cursor and written as they arrive, so the
+// size of an export does not matter for memory.
+type ExportController struct {
+	db *gorm.DB
+}
+
+func NewExportController(db *gorm.DB) *ExportController {
+	return &ExportController{db: db}
+}
+
+// ExportBookings exports the bookings matching the admin list filters, in
+// the list's sort order
+func (c *ExportController) ExportBookings(ctx *gin.Context) {
+	query, list, ok := c.bookingExportQuery(ctx)
+	if !ok {
+		return
+	}
+	halls := c.hallNames()
+
+	c.stream(ctx, "bookings", list.order(query), func(w services.TableWriter) error {
+		return w.WriteHeader("ID", "Created", "Hall", "Customer", "Email", "Phone", "Guests",
+			"Layout", "Event date", "Start", "End", "Status", "Total price", "Fees", "Refund", "Special requests")
+	}, func(w services.TableWriter, rows *sql.Rows) error {
+		var b models.Booking
+		if err := c.db.ScanRows(rows, &b); err != nil {
+			return err
+		}
+		return w.WriteRow(b.ID, b.CreatedAt, halls[b.HallID], b.CustomerName, b.CustomerEmail, b.CustomerPhone,
+			b.GuestCount, string(b.Layout), b.EventDate.Format("2006-01-02"), b.StartTime, b.EndTime, string(b.Status),
+			b.TotalPrice, b.FeeTotal, b.RefundAmount, b.SpecialRequests)
+	})
+}
+
+// ExportPayments exports what each matching booking was charged, the fees
+// added since, what staff recorded as paid and what was refunded. There is
+// no separate payment record; these amounts live on the booking.
+func (c *ExportController) ExportPayments(ctx *gin.Context) {
+	query, list, ok := c.bookingExportQuery(ctx)
+	if !ok {
+		return
+	}
+	halls := c.hallNames()
+
+	var charged, fees, paid, refunded float64
+	c.stream(ctx, "payments", list.order(query), func(w services.TableWriter) error {
+		return w.WriteHeader("Booking ID", "Booked", "Event date", "Hall", "Customer", "Email", "Status",
+			"Charged", "Fees", "Paid", "Refunded", "Net", "Cancelled")
+	}, func(w services.TableWriter, rows *sql.Rows) error {
+		var b models.Booking
+		if err := c.db.ScanRows(rows, &b); err != nil {
+			return err
+		}
+		charged += b.TotalPrice
+		fees += b.FeeTotal
+		paid += b.AmountPaid
+		refunded += b.RefundAmount
+		return w.WriteRow(b.ID, b.CreatedAt, b.EventDate.Format("2006-01-02"), halls[b.HallID], b.CustomerName,
+			b.CustomerEmail, string(b.Status), b.TotalPrice, b.FeeTotal, b.AmountPaid,
+			b.RefundAmount, b.TotalPrice+b.FeeTotal-b.RefundAmount, b.CancelledAt)
+	}, func(w services.TableWriter) error {
+		return w.WriteRow(nil, nil, nil, nil, "Total", nil, nil, charged, fees, paid, refunded, charged+fees-refunded, nil)
+	})
+}
+
+// ExportContacts exports contact form messages. Filters: status, from and
+// to (received date) and q (name, email or subject).
+func (c *ExportController) ExportContacts(ctx *gin.Context) {
+	query := c.db.Model(&models.Contact{})
+	if v := ctx.Query("status"); v != "" {
+		query = query.Where("status IN ?", strings.Split(v, ","))
+	}
+	query, ok := filterContacts(ctx, query)
+	if !ok {
+		return
+	}
+
+	c.stream(ctx, "contacts", query.Order("created_at DESC, id DESC"), func(w services.TableWriter) error {
+		return w.WriteHeader("ID", "Received", "Name", "Email", "Phone", "Subject", "Message", "Status")
+	}, func(w services.TableWriter, rows *sql.Rows) error {
+		var m models.Contact
+		if err := c.db.ScanRows(rows, &m); err != nil {
+			return err
+		}
+		return w.WriteRow(m.ID, m.CreatedAt, m.Name, m.Email, m.Phone, m.Subject, m.Message, m.Status)
+	})
+}
+
+// bookingExportQuery applies the same filters and sort as GetBookings.
+// Paging parameters are ignored.
+func (c *ExportController) bookingExportQuery(ctx *gin.Context) (*gorm.DB, *bookingListQuery, bool) {
+	list, ok := parseBookingListQuery(ctx)
+	if !ok {
+		return nil, nil, false
+	}
+	statuses, ok := bookingStatuses(ctx)
+	if !ok {
+		return nil, nil, false
+	}
+	query, ok := filterBookings(ctx, c.db.Model(&models.Booking{}))
+	if !ok {
+		return nil, nil, false
+	}
+	if len(statuses) > 0 {
+		query = query.Where("status IN ?", statuses)
+	}
+	return query, list, true
+}
+
+func (c *ExportController) hallNames() map[string]string {
+	var halls []models.Hall
+	c.db.Select("id", "name").Find(&halls)
+	names := make(map[string]string, len(halls))
+	for _, h := range halls {
+		names[h.ID] = h.Name
+	}
+	return names
+}
+
+// stream writes the rows of query to the response in the format asked for
+// by the format parameter (csv, the default, or xlsx). footer, if given,
+// runs after the last row.
+func (c *ExportController) stream(ctx *gin.Context, name string, query *gorm.DB,
+	header func(services.TableWriter) error,
+	row func(services.TableWriter, *sql.Rows) error,
+	footer ...func(services.TableWriter) error) {
+	format := ctx.DefaultQuery("format", services.ExportCSV)
+	if format != services.ExportCSV && format != services.ExportXLSX {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "format must be csv or xlsx"})
+		return
+	}
+
+	rows, err := query.Rows()
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to export " + name})
+		return
+	}
+	defer rows.Close()
+
+	w, err := services.NewTableWriter(format, ctx.Writer, name)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to export " + name})
+		return
+	}
+
+	filename := name + "-" + time.Now().Format("2006-01-02") + "." + format
+	ctx.Header("Content-Type", services.ExportContentType(format))
+	ctx.Header("Content-Disposition", `attachment; filename="`+filename+`"`)
+	ctx.Status(http.StatusOK)
+
+	err = header(w)
+	for err == nil && rows.Next() {
+		err = row(w, rows)
+	}
+	if err == nil {
+		err = rows.Err()
+	}
+	for _, f := range footer {
+		if err == nil {
+			err = f(w)
+		}
+	}
+	if closeErr := w.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		// The response has started; the client gets a truncated file.
+		log.Printf("Failed to export %s: %v", name, err)
+	}
+}
@@ -0,0 +1,144 @@
+package controllers
+
+import (
+	"bytes"
+	"encoding/csv"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/xuri/excelize/v2"
+	"gorm.io/gorm"
+
+	"event-booking-backend/models"
+)
+
+func newExportRouter(t *testing.T) (*gin.Engine, *gorm.DB) {
+	t.Helper()
+	db := newTestDB(t)
+	db.Create(&models.Hall{ID: "garden", Name: "Garden", Capacity: 100, BasePrice: 1000, Status: models.HallActive})
+	date := time.Date(2026, 6, 1, 0, 0, 0, 0, time.UTC)
+	cancelledAt := time.Date(2026, 5, 2, 9, 0, 0, 0, time.UTC)
+	for _, b := range []models.Booking{
+		{CustomerName: "Asha", TotalPrice: 1000, FeeTotal: 50, AmountPaid: 1050, Status: models.StatusConfirmed},
+		{CustomerName: "=HYPERLINK(\"http://evil\")", TotalPrice: 2000, RefundAmount: 1000, Status: models.StatusCancelled, CancelledAt: &cancelledAt},
+	} {
+		b.HallID, b.CustomerEmail, b.CustomerPhone, b.GuestCount = "garden", "guest@example.com", "555", 40
+		b.EventDate, b.StartTime, b.EndTime, b.Tags = date, "10:00", "14:00", models.StringList{}
+		if err := db.Create(&b).Error; err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	ec := NewExportController(db)
+	router := gin.New()
+	router.GET("/exports/bookings", ec.ExportBookings)
+	router.GET("/exports/payments", ec.ExportPayments)
+	router.GET("/exports/contacts", ec.ExportContacts)
+	return router, db
+}
+
+func readCSV(t *testing.T, w *httptest.ResponseRecorder) [][]string {
+	t.Helper()
+	if w.Code != http.StatusOK {
+		t.Fatalf("got %d %s, want 200", w.Code, w.Body.String())
+	}
+	if ct := w.Header().Get("Content-Type"); !strings.HasPrefix(ct, "text/csv") {
+		t.Errorf("Content-Type = %q, want text/csv", ct)
+	}
+	records, err := csv.NewReader(w.Body).ReadAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return records
+}
+
+func TestExportBookingsCSV(t *testing.T) {
+	router, _ := newExportRouter(t)
+
+	records := readCSV(t, serve(router, http.MethodGet, "/exports/bookings?sort=totalPrice&order=asc", nil))
+	if len(records) != 3 || records[0][0] != "ID" {
+		t.Fatalf("records = %v, want a header and two bookings", records)
+	}
+	asha := records[1]
+	if asha[2] != "Garden" || asha[3] != "Asha" || asha[8] != "2026-06-01" || asha[11] != "confirmed" || asha[12] != "1000.00" {
+		t.Errorf("first row = %v", asha)
+	}
+	// Text that a spreadsheet would run as a formula is defused
+	if records[2][3] != `'=HYPERLINK("http://evil")` {
+		t.Errorf("customer cell = %q, want it prefixed with a quote", records[2][3])
+	}
+
+	records = readCSV(t, serve(router, http.MethodGet, "/exports/bookings?status=cancelled", nil))
+	if len(records) != 2 || records[1][11] != "cancelled" {
+		t.Errorf("status filter: records = %v, want the cancelled booking only", records)
+	}
+}
+
+func TestExportPaymentsTotals(t *testing.T) {
+	router, _ := newExportRouter(t)
+
+	records := readCSV(t, serve(router, http.MethodGet, "/exports/payments?sort=totalPrice&order=asc", nil))
+	if len(records) != 4 {
+		t.Fatalf("records = %v, want a header, two bookings and the totals", records)
+	}
+	header := records[0]
+	if want := []string{"Charged", "Fees", "Paid", "Refunded", "Net", "Cancelled"}; !reflect.DeepEqual(header[7:], want) {
+		t.Errorf("header = %v", header)
+	}
+	if got := records[2][7:]; !reflect.DeepEqual(got, []string{"2000.00", "0.00", "0.00", "1000.00", "1000.00", "2026-05-02T09:00:00Z"}) {
+		t.Errorf("cancelled booking = %v", got)
+	}
+	if got := records[3][4:]; !reflect.DeepEqual(got, []string{"Total", "", "", "3000.00", "50.00", "1050.00", "1000.00", "2050.00", ""}) {
+		t.Errorf("totals = %v", got)
+	}
+}
+
+func TestExportXLSX(t *testing.T) {
+	router, _ := newExportRouter(t)
+
+	w := serve(router, http.MethodGet, "/exports/payments?format=xlsx&sort=totalPrice&order=asc", nil)
+	if w.Code != http.StatusOK {
+		t.Fatalf("got %d %s, want 200", w.Code, w.Body.String())
+	}
+	if cd := w.Header().Get("Content-Disposition"); !strings.Contains(cd, ".xlsx") {
+		t.Errorf("Content-Disposition = %q", cd)
+	}
+	f, err := excelize.OpenReader(bytes.NewReader(w.Body.Bytes()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	rows, err := f.GetRows("payments")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(rows) != 4 || rows[0][0] != "Booking ID" || rows[1][4] != "Asha" || rows[3][4] != "Total" {
+		t.Errorf("rows = %v", rows)
+	}
+}
+
+func TestExportContacts(t *testing.T) {
+	router, db := newExportRouter(t)
+	for _, status := range []string{models.ContactOpen, models.ContactResolved} {
+		db.Create(&models.Contact{Name: "Meena", Email: "meena@example.com", Subject: "Parking", Message: "+1 for parking",
+			Status: status, Tags: models.StringList{}})
+	}
+
+	records := readCSV(t, serve(router, http.MethodGet, "/exports/contacts?status=open", nil))
+	if len(records) != 2 || records[1][2] != "Meena" || records[1][6] != "'+1 for parking" || records[1][7] != "open" {
+		t.Errorf("records = %v, want the open message", records)
+	}
+}
+
+func TestExportRejectsBadQuery(t *testing.T) {
+	router, _ := newExportRouter(t)
+	for _, path := range []string{"/exports/bookings?format=pdf", "/exports/payments?sort=hall", "/exports/bookings?dateFrom=june"} {
+		if w := serve(router, http.MethodGet, path, nil); w.Code != http.StatusBadRequest {
+			t.Errorf("%s: got %d %s, want 400", path, w.Code, w.Body.String())
+		}
+	}
+}
@@ -0,0 +1,313 @@
+package controllers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+
+	"event-booking-backend/models"
+	"event-booking-backend/services"
+)
+
+// Operating hours of halls created without them.
+const (
+	defaultOpensAt  = "09:00"
+	defaultClosesAt = "23:00"
+)
+
+// hallAcceptsBookings is Hall.AcceptsBookings as a query condition.
+const hallAcceptsBookings = "status = 'active' OR (status = 'inactive' AND inactive_until <= ?)"
+
+// maxConflictIDs caps how many blocking bookings are listed when a hall
+// cannot be retired.
+const maxConflictIDs = 50
+
+// HallController lists halls publicly and lets managers maintain them and
+// their galleries.
+type HallController struct {
+	db      *gorm.DB
+	storage services.MediaStorage
+}
+
+func NewHallController(db *gorm.DB, storage services.MediaStorage) *HallController {
+	return &HallController{db: db, storage: storage}
+}
+
+// ListHalls returns the halls currently taking bookings, with their
+// galleries, amenities and layouts, narrowed by the filters of filterHalls.
+func (c *HallController) ListHalls(ctx *gin.Context) {
+	query, _, ok := filterHalls(ctx, c.db.Where(hallAcceptsBookings, time.Now()))
+	if !ok {
+		return
+	}
+	var halls []models.Hall
+	if err := preloadHallDetails(query).Find(&halls).Error; err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch halls"})
+		return
+	}
+	c.resolveMediaURLs(halls)
+	ctx.JSON(http.StatusOK, halls)
+}
+
+// CreateHall adds a hall (admin only)
+func (c *HallController) CreateHall(ctx *gin.Context) {
+	var hall models.Hall
+	if err := ctx.ShouldBindJSON(&hall); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	hall.Media, hall.Amenities, hall.Layouts = nil, nil, nil
+	hall.Status = models.HallActive
+	hall.InactiveUntil, hall.InactiveReason, hall.ArchivedAt = nil, "", nil
+	if hall.OpensAt == "" {
+		hall.OpensAt = defaultOpensAt
+	}
+	if hall.ClosesAt == "" {
+		hall.ClosesAt = defaultClosesAt
+	}
+	if _, err := hall.OpenHours(); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	err := c.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&hall).Error; err != nil {
+			return err
+		}
+		return recordAudit(tx, ctx, "hall.created", "hall", hall.ID, nil, hall)
+	})
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create hall"})
+		return
+	}
+	ctx.JSON(http.StatusCreated, hall)
+}
+
+// UpdateHall changes the fields present in the request body (admin only)
+func (c *HallController) UpdateHall(ctx *gin.Context) {
+	var hall models.Hall
+	if err := c.db.First(&hall, "id = ?", ctx.Param("id")).Error; err != nil {
+		ctx.JSON(http.StatusNotFound, gin.H{"error": "Hall not found"})
+		return
+	}
+
+	updated := hall
+	if err := ctx.ShouldBindJSON(&updated); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	// The ID comes from the path; a different one in the body would
+	// otherwise save as a new hall.
+	updated.ID = hall.ID
+	updated.CreatedAt = hall.CreatedAt
+	// Status changes go through the lifecycle endpoints, which check
+	// bookings first.
+	updated.Status = hall.Status
+	updated.InactiveUntil = hall.InactiveUntil
+	updated.InactiveReason = hall.InactiveReason
+	updated.ArchivedAt = hall.ArchivedAt
+	// The gallery, amenities and layouts have their own endpoints.
+	updated.Media, updated.Amenities, updated.Layouts = nil, nil, nil
+	if _, err := updated.OpenHours(); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	err := c.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Save(&updated).Error; err != nil {
+			return err
+		}
+		return recordAudit(tx, ctx, "hall.updated", "hall", hall.ID, hall, updated)
+	})
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update hall"})
+		return
+	}
+	ctx.JSON(http.StatusOK, updated)
+}
+
+// AdminListHalls returns every hall, whatever its status (admin only).
+// status filters by a comma-separated list of statuses.
+func (c *HallController) AdminListHalls(ctx *gin.Context) {
+	query := c.db.Order("created_at, id")
+	if v := ctx.Query("status"); v != "" {
+		query = query.Where("status IN ?", strings.Split(v, ","))
+	}
+	var halls []models.Hall
+	if err := preloadHallDetails(query).Find(&halls).Error; err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch halls"})
+		return
+	}
+	c.resolveMediaURLs(halls)
+	ctx.JSON(http.StatusOK, halls)
+}
+
+// DeactivateHall closes a hall to new bookings, until a date or until it
+// is reactivated (admin only). It is refused while live bookings fall in
+// the closed period; move or cancel them first.
+func (c *HallController) DeactivateHall(ctx *gin.Context) {
+	var request struct {
+		Until  *time.Time `json:"until"`
+		Reason string     `json:"reason"`
+	}
+	if err := ctx.ShouldBindJSON(&request); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if request.Until != nil && !request.Until.After(time.Now()) {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "until must be in the future"})
+		return
+	}
+
+	c.changeStatus(ctx, request.Until, func(hall *models.Hall) (string, error) {
+		if hall.Status == models.HallArchived {
+			return "", errHallArchived
+		}
+		hall.Status = models.HallInactive
+		hall.InactiveUntil = request.Until
+		hall.InactiveReason = strings.TrimSpace(request.Reason)
+		return "hall.deactivated", nil
+	})
+}
+
+// ActivateHall reopens an inactive hall or restores an archived one
+// (admin only).
+func (c *HallController) ActivateHall(ctx *gin.Context) {
+	c.changeStatus(ctx, nil, func(hall *models.Hall) (string, error) {
+		action := "hall.activated"
+		if hall.Status == models.HallArchived {
+			action = "hall.restored"
+		}
+		hall.Status = models.HallActive
+		hall.InactiveUntil, hall.InactiveReason, hall.ArchivedAt = nil, "", nil
+		return action, nil
+	})
+}
+
+// ArchiveHall retires a hall (admin only). The row is kept so past
+// bookings still point at it; the hall disappears from the public list and
+// takes no more bookings. Halls with upcoming bookings cannot be archived.
+func (c *HallController) ArchiveHall(ctx *gin.Context) {
+	now := time.Now()
+	c.changeStatus(ctx, nil, func(hall *models.Hall) (string, error) {
+		if hall.Status == models.HallArchived {
+			return "", errHallArchived
+		}
+		hall.Status = models.HallArchived
+		hall.InactiveUntil, hall.InactiveReason = nil, ""
+		hall.ArchivedAt = &now
+		return "hall.archived", nil
+	})
+}
+
+var errHallArchived = errors.New("hall is archived")
+
+// hallConflictError lists the live bookings that stop a hall being closed.
+type hallConflictError struct {
+	count int64
+	ids   []string
+}
+
+func (e hallConflictError) Error() string {
+	return "hall has upcoming bookings"
+}
+
+// write responds 409 with the blocking bookings; what says how they
+// block, e.g. "use this hall".
+func (e hallConflictError) write(ctx *gin.Context, what string) {
+	ctx.JSON(http.StatusConflict, gin.H{
+		"error":    strconv.FormatInt(e.count, 10) + " upcoming bookings " + what + "; move or cancel them first",
+		"bookings": e.ids,
+	})
+}
+
+// newHallConflict lists ids, capped at maxConflictIDs, out of count.
+func newHallConflict(count int64, ids []uint64) hallConflictError {
+	if len(ids) > maxConflictIDs {
+		ids = ids[:maxConflictIDs]
+	}
+	conflict := hallConflictError{count: count, ids: make([]string, len(ids))}
+	for i, id := range ids {
+		conflict.ids[i] = strconv.FormatUint(id, 10)
+	}
+	return conflict
+}
+
+// changeStatus applies change to the hall named in the path while holding
+// its lock, so no booking can be taken in between, and audits it under the
+// action change returns. If the hall ends up closed, upcoming bookings
+// before until (any upcoming booking when until is nil) block the change.
+func (c *HallController) changeStatus(ctx *gin.Context, until *time.Time, change func(*models.Hall) (string, error)) {
+	var hall models.Hall
+	err := c.db.Transaction(func(tx *gorm.DB) error {
+		locked, err := lockHall(tx, ctx.Param("id"))
+		if err != nil {
+			return err
+		}
+		before := *locked
+		hall = *locked
+		action, err := change(&hall)
+		if err != nil {
+			return err
+		}
+		if hall.Status != models.HallActive {
+			if err := ensureNoUpcomingBookings(tx, hall.ID, until); err != nil {
+				return err
+			}
+		}
+		if err := tx.Save(&hall).Error; err != nil {
+			return err
+		}
+		return recordAudit(tx, ctx, action, "hall", hall.ID, before, hall)
+	})
+
+	var conflict hallConflictError
+	switch {
+	case errors.Is(err, errHallNotFound):
+		ctx.JSON(http.StatusNotFound, gin.H{"error": "Hall not found"})
+	case errors.Is(err, errHallArchived):
+		ctx.JSON(http.StatusConflict, gin.H{"error": "Hall is archived"})
+	case errors.As(err, &conflict):
+		conflict.write(ctx, "use this hall")
+	case err != nil:
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update hall"})
+	default:
+		ctx.JSON(http.StatusOK, hall)
+	}
+}
+
+// ensureNoUpcomingBookings fails with hallConflictError when live bookings
+// for the hall take place from today until until (or any time after today
+// when until is nil).
+func ensureNoUpcomingBookings(tx *gorm.DB, hallID string, until *time.Time) error {
+	query := tx.Model(&models.Booking{}).
+		Where("hall_id = ? AND status != ? AND event_date >= ?", hallID, models.StatusCancelled, startOfToday())
+	if until != nil {
+		query = query.Where("event_date < ?", *until)
+	}
+
+	var count int64
+	if err := query.Count(&count).Error; err != nil {
+		return err
+	}
+	if count == 0 {
+		return nil
+	}
+	var ids []uint64
+	if err := query.Order("event_date, id").Limit(maxConflictIDs).Pluck("id", &ids).Error; err != nil {
+		return err
+	}
+	return newHallConflict(count, ids)
+}
+
+// startOfToday is midnight today in the venue's time zone.
+func startOfToday() time.Time {
+	loc := services.VenueLocation()
+	now := time.Now().In(loc)
+	return time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, loc)
+}
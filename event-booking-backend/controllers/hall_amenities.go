@@ -0,0 +1,170 @@
+package controllers
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+
+	"event-booking-backend/models"
+)
+
+// preloadHallDetails loads what the hall listings show with each hall.
+func preloadHallDetails(db *gorm.DB) *gorm.DB {
+	return db.Preload("Media", orderMedia).
+		Preload("Amenities", func(db *gorm.DB) *gorm.DB { return db.Order("amenities.category, amenities.name") }).
+		Preload("Layouts", func(db *gorm.DB) *gorm.DB { return db.Order("capacity DESC, layout") })
+}
+
+// SetAmenities replaces the amenities a hall offers (admin only). ids
+// lists catalogue entries; an empty list clears them.
+func (c *HallController) SetAmenities(ctx *gin.Context) {
+	var request struct {
+		IDs []string `json:"ids"`
+	}
+	if err := ctx.ShouldBindJSON(&request); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	ids := uniqueStrings(request.IDs)
+
+	var hall *models.Hall
+	err := c.db.Transaction(func(tx *gorm.DB) error {
+		var err error
+		if hall, err = lockHall(tx, ctx.Param("id")); err != nil {
+			return err
+		}
+		var before []string
+		if err := tx.Table("hall_amenities").Where("hall_id = ?", hall.ID).
+			Order("amenity_id").Pluck("amenity_id", &before).Error; err != nil {
+			return err
+		}
+		amenities := []models.Amenity{}
+		if len(ids) > 0 {
+			if err := tx.Where("id IN ?", ids).Order("category, name").Find(&amenities).Error; err != nil {
+				return err
+			}
+		}
+		if len(amenities) != len(ids) {
+			return errUnknownAmenity
+		}
+		if err := tx.Model(hall).Association("Amenities").Replace(amenities); err != nil {
+			return err
+		}
+		hall.Amenities = amenities
+		return recordAudit(tx, ctx, "hall.amenities_updated", "hall", hall.ID,
+			gin.H{"amenities": before}, gin.H{"amenities": ids})
+	})
+	switch {
+	case errors.Is(err, errHallNotFound):
+		ctx.JSON(http.StatusNotFound, gin.H{"error": "Hall not found"})
+	case errors.Is(err, errUnknownAmenity):
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case err != nil:
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update amenities"})
+	default:
+		ctx.JSON(http.StatusOK, hall.Amenities)
+	}
+}
+
+var errUnknownAmenity = errors.New("ids must name amenities from the catalogue")
+
+// SetLayouts replaces the seating layouts a hall offers and their
+// capacities (admin only). Removing a layout or shrinking it below the
+// guest count of an upcoming booking that uses it is refused, listing the
+// bookings, since they could no longer be honoured.
+func (c *HallController) SetLayouts(ctx *gin.Context) {
+	var request struct {
+		Layouts []struct {
+			Layout   models.SeatingLayout `json:"layout"`
+			Capacity int                  `json:"capacity"`
+		} `json:"layouts"`
+	}
+	if err := ctx.ShouldBindJSON(&request); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	capacities := make(map[models.SeatingLayout]int, len(request.Layouts))
+	for _, l := range request.Layouts {
+		if !l.Layout.Valid() {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": "Unknown seating layout " + string(l.Layout)})
+			return
+		}
+		if _, ok := capacities[l.Layout]; ok {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": "Layout " + string(l.Layout) + " is listed twice"})
+			return
+		}
+		if l.Capacity < 1 {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": "Layout capacities must be positive"})
+			return
+		}
+		capacities[l.Layout] = l.Capacity
+	}
+
+	var layouts []models.HallLayout
+	err := c.db.Transaction(func(tx *gorm.DB) error {
+		hall, err := lockHall(tx, ctx.Param("id"))
+		if err != nil {
+			return err
+		}
+		if err := ensureLayoutsFit(tx, hall.ID, capacities); err != nil {
+			return err
+		}
+		var before []models.HallLayout
+		if err := tx.Where("hall_id = ?", hall.ID).Order("layout").Find(&before).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("hall_id = ?", hall.ID).Delete(&models.HallLayout{}).Error; err != nil {
+			return err
+		}
+		layouts = []models.HallLayout{}
+		for _, layout := range models.SeatingLayouts {
+			if capacity, ok := capacities[layout]; ok {
+				layouts = append(layouts, models.HallLayout{HallID: hall.ID, Layout: layout, Capacity: capacity})
+			}
+		}
+		if len(layouts) > 0 {
+			if err := tx.Create(&layouts).Error; err != nil {
+				return err
+			}
+		}
+		return recordAudit(tx, ctx, "hall.layouts_updated", "hall", hall.ID,
+			gin.H{"layouts": before}, gin.H{"layouts": layouts})
+	})
+
+	var conflict hallConflictError
+	switch {
+	case errors.Is(err, errHallNotFound):
+		ctx.JSON(http.StatusNotFound, gin.H{"error": "Hall not found"})
+	case errors.As(err, &conflict):
+		conflict.write(ctx, "need a layout that would be removed or made too small")
+	case err != nil:
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update layouts"})
+	default:
+		ctx.JSON(http.StatusOK, layouts)
+	}
+}
+
+// ensureLayoutsFit fails with hallConflictError when live upcoming
+// bookings of the hall use a layout missing from capacities or have more
+// guests than its new capacity.
+func ensureLayoutsFit(tx *gorm.DB, hallID string, capacities map[models.SeatingLayout]int) error {
+	var bookings []models.Booking
+	if err := tx.Select("id", "layout", "guest_count").
+		Where("hall_id = ? AND status != ? AND event_date >= ? AND layout <> ''",
+			hallID, models.StatusCancelled, startOfToday()).
+		Order("event_date, id").Find(&bookings).Error; err != nil {
+		return err
+	}
+	var ids []uint64
+	for _, b := range bookings {
+		if capacity, ok := capacities[b.Layout]; !ok || b.GuestCount > capacity {
+			ids = append(ids, b.ID)
+		}
+	}
+	if len(ids) == 0 {
+		return nil
+	}
+	return newHallConflict(int64(len(ids)), ids)
+}
@@ -0,0 +1,345 @@
+package controllers
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"log"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+
+	"event-booking-backend/models"
+	"event-booking-backend/services"
+)
+
+// Upload limits for hall media.
+const (
+	maxImageUpload   = 15 << 20
+	maxVideoUpload   = 200 << 20
+	maxMediaPerHall  = 50
+	maxCaptionLength = 300
+)
+
+// mediaTypes are the accepted uploads, by sniffed content type, with the
+// extension they are stored under.
+var mediaTypes = map[string]struct {
+	kind models.MediaKind
+	ext  string
+}{
+	"image/jpeg": {models.MediaImage, "jpg"},
+	"image/png":  {models.MediaImage, "png"},
+	"image/gif":  {models.MediaImage, "gif"},
+	"image/webp": {models.MediaImage, "webp"},
+	"video/mp4":  {models.MediaVideo, "mp4"},
+	"video/webm": {models.MediaVideo, "webm"},
+}
+
+// ListMedia returns a hall's gallery in display order (admin only).
+func (c *HallController) ListMedia(ctx *gin.Context) {
+	var media []models.HallMedia
+	if err := orderMedia(c.db.Where("hall_id = ?", ctx.Param("id"))).Find(&media).Error; err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch media"})
+		return
+	}
+	for i := range media {
+		media[i].ResolveURLs(c.storage.URL)
+	}
+	ctx.JSON(http.StatusOK, media)
+}
+
+// UploadMedia adds a photo or video to the end of a hall's gallery (admin
+// only). The multipart form carries the file and an optional caption. The
+// type is taken from the file's contents, not its name; images are
+// resized into the services.ImageVariants sizes.
+func (c *HallController) UploadMedia(ctx *gin.Context) {
+	var hall models.Hall
+	if err := c.db.First(&hall, "id = ?", ctx.Param("id")).Error; err != nil {
+		ctx.JSON(http.StatusNotFound, gin.H{"error": "Hall not found"})
+		return
+	}
+
+	ctx.Request.Body = http.MaxBytesReader(ctx.Writer, ctx.Request.Body, maxVideoUpload+1<<20)
+	header, err := ctx.FormFile("file")
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Upload a file in the file field (at most 200 MB)"})
+		return
+	}
+	caption := strings.TrimSpace(ctx.PostForm("caption"))
+	if len(caption) > maxCaptionLength {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Caption is too long"})
+		return
+	}
+	file, err := header.Open()
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Cannot read the uploaded file"})
+		return
+	}
+	defer file.Close()
+
+	sniff := make([]byte, 512)
+	n, _ := io.ReadFull(file, sniff)
+	contentType := http.DetectContentType(sniff[:n])
+	mediaType, ok := mediaTypes[contentType]
+	if !ok {
+		ctx.JSON(http.StatusUnsupportedMediaType, gin.H{"error": "Upload a JPEG, PNG, GIF or WebP image, or an MP4 or WebM video"})
+		return
+	}
+	if mediaType.kind == models.MediaImage && header.Size > maxImageUpload {
+		ctx.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": "Images can be at most 15 MB"})
+		return
+	}
+	if _, err := file.Seek(0, io.SeekStart); err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to upload media"})
+		return
+	}
+
+	id, _, err := services.NewOpaqueToken()
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to upload media"})
+		return
+	}
+	media := models.HallMedia{
+		ID:          id,
+		HallID:      hall.ID,
+		Kind:        mediaType.kind,
+		Caption:     caption,
+		ContentType: contentType,
+		Size:        header.Size,
+		StorageKey:  "halls/" + id + "/original." + mediaType.ext,
+		VariantKeys: models.StringMap{},
+	}
+
+	var variants []services.ResizedImage
+	if media.Kind == models.MediaImage {
+		media.Width, media.Height, variants, err = services.ResizeImage(file)
+		if errors.Is(err, services.ErrImageTooLarge) {
+			ctx.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": "Image dimensions are too large"})
+			return
+		}
+		if err != nil {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": "The image could not be read"})
+			return
+		}
+		if _, err := file.Seek(0, io.SeekStart); err != nil {
+			ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to upload media"})
+			return
+		}
+	}
+
+	// Store the files first; the row only appears once they are all there.
+	reqCtx := ctx.Request.Context()
+	err = c.storage.Put(reqCtx, media.StorageKey, file, header.Size, contentType)
+	for _, v := range variants {
+		if err != nil {
+			break
+		}
+		key := "halls/" + id + "/" + v.Variant.Name + ".jpg"
+		media.VariantKeys[v.Variant.Name] = key
+		err = c.storage.Put(reqCtx, key, bytes.NewReader(v.Data), int64(len(v.Data)), "image/jpeg")
+	}
+	if err != nil {
+		log.Printf("Failed to store hall media %s: %v", media.StorageKey, err)
+		c.deleteMediaFiles(media)
+		ctx.JSON(http.StatusBadGateway, gin.H{"error": "Failed to store media"})
+		return
+	}
+
+	err = c.db.Transaction(func(tx *gorm.DB) error {
+		if _, err := lockHall(tx, hall.ID); err != nil {
+			return err
+		}
+		var count int64
+		if err := tx.Model(&models.HallMedia{}).Where("hall_id = ?", hall.ID).Count(&count).Error; err != nil {
+			return err
+		}
+		if count >= maxMediaPerHall {
+			return errMediaLimit
+		}
+		if err := tx.Model(&models.HallMedia{}).Where("hall_id = ?", hall.ID).
+			Select("COALESCE(MAX(position) + 1, 0)").Scan(&media.Position).Error; err != nil {
+			return err
+		}
+		if err := tx.Create(&media).Error; err != nil {
+			return err
+		}
+		return recordAudit(tx, ctx, "hall.media_added", "hall", hall.ID, nil, media)
+	})
+	if err != nil {
+		c.deleteMediaFiles(media)
+		if errors.Is(err, errMediaLimit) {
+			ctx.JSON(http.StatusConflict, gin.H{"error": "A hall can have at most 50 photos and videos"})
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to upload media"})
+		return
+	}
+
+	media.ResolveURLs(c.storage.URL)
+	ctx.JSON(http.StatusCreated, media)
+}
+
+var errMediaLimit = errors.New("too many media items")
+
+// UpdateMedia changes the caption of a media item (admin only).
+func (c *HallController) UpdateMedia(ctx *gin.Context) {
+	var request struct {
+		Caption string `json:"caption"`
+	}
+	if err := ctx.ShouldBindJSON(&request); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	request.Caption = strings.TrimSpace(request.Caption)
+	if len(request.Caption) > maxCaptionLength {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Caption is too long"})
+		return
+	}
+
+	var media models.HallMedia
+	if err := c.db.First(&media, "id = ? AND hall_id = ?", ctx.Param("mediaId"), ctx.Param("id")).Error; err != nil {
+		ctx.JSON(http.StatusNotFound, gin.H{"error": "Media not found"})
+		return
+	}
+	before := media
+	media.Caption = request.Caption
+	err := c.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&media).Update("caption", media.Caption).Error; err != nil {
+			return err
+		}
+		return recordAudit(tx, ctx, "hall.media_updated", "hall", media.HallID, before, media)
+	})
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update media"})
+		return
+	}
+	media.ResolveURLs(c.storage.URL)
+	ctx.JSON(http.StatusOK, media)
+}
+
+// ReorderMedia sets the gallery order (admin only). ids must list every
+// media item of the hall exactly once, first to last.
+func (c *HallController) ReorderMedia(ctx *gin.Context) {
+	var request struct {
+		IDs []string `json:"ids" binding:"required"`
+	}
+	if err := ctx.ShouldBindJSON(&request); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	hallID := ctx.Param("id")
+	var media []models.HallMedia
+	err := c.db.Transaction(func(tx *gorm.DB) error {
+		if _, err := lockHall(tx, hallID); err != nil {
+			return err
+		}
+		var current []string
+		if err := tx.Model(&models.HallMedia{}).Where("hall_id = ?", hallID).
+			Order("position, created_at").Pluck("id", &current).Error; err != nil {
+			return err
+		}
+		if !sameSet(current, request.IDs) {
+			return errMediaOrder
+		}
+		for position, id := range request.IDs {
+			if err := tx.Model(&models.HallMedia{}).Where("id = ?", id).Update("position", position).Error; err != nil {
+				return err
+			}
+		}
+		if err := recordAudit(tx, ctx, "hall.media_reordered", "hall", hallID,
+			gin.H{"order": current}, gin.H{"order": request.IDs}); err != nil {
+			return err
+		}
+		return tx.Where("hall_id = ?", hallID).Order("position").Find(&media).Error
+	})
+	switch {
+	case errors.Is(err, errHallNotFound):
+		ctx.JSON(http.StatusNotFound, gin.H{"error": "Hall not found"})
+		return
+	case errors.Is(err, errMediaOrder):
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "ids must list every media item of the hall exactly once"})
+		return
+	case err != nil:
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to reorder media"})
+		return
+	}
+
+	for i := range media {
+		media[i].ResolveURLs(c.storage.URL)
+	}
+	ctx.JSON(http.StatusOK, media)
+}
+
+var errMediaOrder = errors.New("media order does not match the gallery")
+
+// DeleteMedia removes a media item and its files (admin only).
+func (c *HallController) DeleteMedia(ctx *gin.Context) {
+	var media models.HallMedia
+	err := c.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.First(&media, "id = ? AND hall_id = ?", ctx.Param("mediaId"), ctx.Param("id")).Error; err != nil {
+			return err
+		}
+		if err := tx.Delete(&media).Error; err != nil {
+			return err
+		}
+		return recordAudit(tx, ctx, "hall.media_deleted", "hall", media.HallID, media, nil)
+	})
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		ctx.JSON(http.StatusNotFound, gin.H{"error": "Media not found"})
+		return
+	}
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete media"})
+		return
+	}
+
+	// The row is gone, so a failure here only leaves an unreferenced file.
+	c.deleteMediaFiles(media)
+	ctx.JSON(http.StatusOK, gin.H{"message": "Media deleted"})
+}
+
+func (c *HallController) deleteMediaFiles(media models.HallMedia) {
+	for _, key := range media.Keys() {
+		if key == "" {
+			continue
+		}
+		if err := c.storage.Delete(context.Background(), key); err != nil {
+			log.Printf("Failed to delete hall media file %s: %v", key, err)
+		}
+	}
+}
+
+func orderMedia(db *gorm.DB) *gorm.DB {
+	return db.Order("position, created_at")
+}
+
+// resolveMediaURLs fills in the media URLs of every hall.
+func (c *HallController) resolveMediaURLs(halls []models.Hall) {
+	for i := range halls {
+		for j := range halls[i].Media {
+			halls[i].Media[j].ResolveURLs(c.storage.URL)
+		}
+	}
+}
+
+// sameSet reports whether a and b hold the same strings, each once.
+func sameSet(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	seen := make(map[string]bool, len(a))
+	for _, s := range a {
+		seen[s] = true
+	}
+	for _, s := range b {
+		if !seen[s] {
+			return false
+		}
+		delete(seen, s)
+	}
+	return true
+}
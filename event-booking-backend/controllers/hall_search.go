@@ -0,0 +1,193 @@
+package controllers
+
+import (
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+
+	"event-booking-backend/models"
+	"event-booking-backend/services"
+)
+
+// hallFilter is what filterHalls read from the query string.
+type hallFilter struct {
+	Guests int
+	Layout models.SeatingLayout
+}
+
+// filterHalls narrows query by the hall filters in the query string:
+// amenity keeps halls offering every amenity in a comma-separated list,
+// layout those offering that seating layout, and guests those that fit
+// that many guests (in the layout, when one is given). It writes a 400 and
+// returns false for malformed filters.
+func filterHalls(ctx *gin.Context, query *gorm.DB) (*gorm.DB, hallFilter, bool) {
+	var filter hallFilter
+	if v := ctx.Query("amenity"); v != "" {
+		amenities := uniqueStrings(strings.Split(v, ","))
+		query = query.Where(`id IN (SELECT hall_id FROM hall_amenities WHERE amenity_id IN ?
+	GROUP BY hall_id HAVING COUNT(*) = ?)`, amenities, len(amenities))
+	}
+	if v := ctx.Query("guests"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": "guests must be a positive number"})
+			return nil, filter, false
+		}
+		filter.Guests = n
+	}
+	if v := ctx.Query("layout"); v != "" {
+		filter.Layout = models.SeatingLayout(v)
+		if !filter.Layout.Valid() {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": "Unknown seating layout"})
+			return nil, filter, false
+		}
+		query = query.Where(`EXISTS (SELECT 1 FROM hall_layouts l
+	WHERE l.hall_id = halls.id AND l.layout = ? AND l.capacity >= ?)`, filter.Layout, filter.Guests)
+	} else if filter.Guests > 0 {
+		query = query.Where("capacity >= ?", filter.Guests)
+	}
+	return query, filter, true
+}
+
+// hallSearchResult is a hall that can host the searched-for event.
+type hallSearchResult struct {
+	models.Hall
+	// Price is what a booking starting at the searched time costs.
+	Price float64 `json:"price"`
+	// SeatingCapacity is how many guests the hall holds in the layout
+	// searched for, or without one, its plain capacity.
+	SeatingCapacity int `json:"seatingCapacity"`
+}
+
+// SearchHalls finds the halls that can host an event: taking bookings,
+// open and free for the whole time window, big enough and within budget.
+// Each comes with its price for the slot, cheapest first.
+//
+// date (YYYY-MM-DD) and start (HH:MM, venue time) are required; end
+// defaults to the end of a standard slot, and 00:00 means midnight.
+// guests, layout and amenity filter as for ListHalls, and maxPrice drops
+// halls that would cost more.
+func (c *HallController) SearchHalls(ctx *gin.Context) {
+	date, err := time.Parse("2006-01-02", ctx.Query("date"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "date must be YYYY-MM-DD"})
+		return
+	}
+	start := ctx.Query("start")
+	if !validStartTime(start) {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "start must be in HH:MM format"})
+		return
+	}
+	end := ctx.DefaultQuery("end", calculateEndTime(start))
+	if !validStartTime(end) {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "end must be in HH:MM format"})
+		return
+	}
+	from, to := clockMinutes(start, false), clockMinutes(end, true)
+	if to <= from {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "end must be after start"})
+		return
+	}
+	y, m, d := date.Date()
+	startsAt := time.Date(y, m, d, from/60, from%60, 0, 0, services.VenueLocation())
+	if startsAt.Before(time.Now()) {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "The time window must be in the future"})
+		return
+	}
+	maxPrice := -1.0
+	if v := ctx.Query("maxPrice"); v != "" {
+		if maxPrice, err = strconv.ParseFloat(v, 64); err != nil || maxPrice < 0 {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": "maxPrice must be a non-negative number"})
+			return
+		}
+	}
+
+	// A hall closed until next week can still host an event the week after
+	query, filter, ok := filterHalls(ctx, c.db.Where(hallAcceptsBookings, startsAt))
+	if !ok {
+		return
+	}
+	var halls []models.Hall
+	if err := preloadHallDetails(query).Find(&halls).Error; err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to search halls"})
+		return
+	}
+	busy, err := c.busyHalls(date, from, to)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to search halls"})
+		return
+	}
+	c.resolveMediaURLs(halls)
+
+	results := []hallSearchResult{}
+	for _, hall := range halls {
+		if busy[hall.ID] || from < clockMinutes(hall.OpensAt, false) || to > clockMinutes(hall.ClosesAt, true) {
+			continue
+		}
+		result := hallSearchResult{
+			Hall:            hall,
+			Price:           calculatePrice(&hall, date, start),
+			SeatingCapacity: hall.Capacity,
+		}
+		if maxPrice >= 0 && result.Price > maxPrice {
+			continue
+		}
+		for _, layout := range hall.Layouts {
+			if layout.Layout == filter.Layout {
+				result.SeatingCapacity = layout.Capacity
+			}
+		}
+		results = append(results, result)
+	}
+	// Cheapest first, then the smallest hall that fits, so the best match
+	// leads.
+	sort.SliceStable(results, func(i, j int) bool {
+		if results[i].Price != results[j].Price {
+			return results[i].Price < results[j].Price
+		}
+		return results[i].SeatingCapacity < results[j].SeatingCapacity
+	})
+	ctx.JSON(http.StatusOK, results)
+}
+
+// busyHalls returns the halls with a live booking on date that overlaps
+// the window from-to, in minutes since midnight. A booking running past
+// midnight counts as ending at midnight.
+func (c *HallController) busyHalls(date time.Time, from, to int) (map[string]bool, error) {
+	var bookings []models.Booking
+	if err := c.db.Select("hall_id", "start_time", "end_time").
+		Where("DATE(event_date) = DATE(?) AND status != ?", date, models.StatusCancelled).
+		Find(&bookings).Error; err != nil {
+		return nil, err
+	}
+	busy := make(map[string]bool)
+	for _, b := range bookings {
+		bookedFrom, bookedTo := clockMinutes(b.StartTime, false), clockMinutes(b.EndTime, true)
+		if bookedTo <= bookedFrom {
+			bookedTo = 24 * 60
+		}
+		if bookedFrom < to && from < bookedTo {
+			busy[b.HallID] = true
+		}
+	}
+	return busy, nil
+}
+
+// clockMinutes turns HH:MM into minutes since midnight. For the end of a
+// period (end true), 00:00 is the midnight that closes the day.
+func clockMinutes(clock string, end bool) int {
+	t, err := time.Parse("15:04", clock)
+	if err != nil {
+		return 0
+	}
+	minutes := t.Hour()*60 + t.Minute()
+	if end && minutes == 0 {
+		return 24 * 60
+	}
+	return minutes
+}
@@ -0,0 +1,87 @@
+package controllers
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"event-booking-backend/models"
+)
+
+func TestArchiveHallWithUpcomingBooking(t *testing.T) {
+	db := newTestDB(t)
+	hall := models.Hall{ID: "garden", Name: "Garden", Capacity: 100, BasePrice: 1000, Status: models.HallActive}
+	if err := db.Create(&hall).Error; err != nil {
+		t.Fatal(err)
+	}
+	booking := models.Booking{
+		HallID:        hall.ID,
+		CustomerName:  "Asha",
+		CustomerEmail: "asha@example.com",
+		CustomerPhone: "555",
+		GuestCount:    50,
+		EventDate:     time.Now().AddDate(0, 0, 10),
+		StartTime:     "18:00",
+		EndTime:       "20:00",
+		Status:        models.StatusConfirmed,
+		Tags:          models.StringList{},
+	}
+	if err := db.Create(&booking).Error; err != nil {
+		t.Fatal(err)
+	}
+
+	router := gin.New()
+	router.DELETE("/halls/:id", NewHallController(db, nil).ArchiveHall)
+
+	w := serve(router, http.MethodDelete, "/halls/garden", nil)
+	if w.Code != http.StatusConflict {
+		t.Fatalf("archive: got %d %s, want 409", w.Code, w.Body.String())
+	}
+	var body struct {
+		Bookings []string `json:"bookings"`
+	}
+	decode(t, w, &body)
+	if len(body.Bookings) != 1 || body.Bookings[0] != "1" {
+		t.Errorf("conflicting bookings = %v, want [1]", body.Bookings)
+	}
+	if err := db.First(&hall, "id = ?", "garden").Error; err != nil {
+		t.Fatal(err)
+	}
+	if hall.Status != models.HallActive {
+		t.Errorf("hall status = %s, want it left active", hall.Status)
+	}
+
+	// With the booking cancelled the hall can go
+	db.Model(&booking).Update("status", models.StatusCancelled)
+	if w := serve(router, http.MethodDelete, "/halls/garden", nil); w.Code != http.StatusOK {
+		t.Fatalf("archive after cancelling: got %d %s, want 200", w.Code, w.Body.String())
+	}
+}
+
+func TestSearchHallsReopeningHall(t *testing.T) {
+	db := newTestDB(t)
+	reopens := time.Now().AddDate(0, 0, 7)
+	hall := models.Hall{ID: "garden", Name: "Garden", Capacity: 100, BasePrice: 1000,
+		Status: models.HallInactive, InactiveUntil: &reopens, OpensAt: "08:00", ClosesAt: "23:00"}
+	if err := db.Create(&hall).Error; err != nil {
+		t.Fatal(err)
+	}
+	router := gin.New()
+	router.GET("/halls/search", NewHallController(db, nil).SearchHalls)
+
+	// Closed for an event tomorrow, open for one after it reopens
+	for days, want := range map[int]int{1: 0, 14: 1} {
+		date := time.Now().AddDate(0, 0, days).Format("2006-01-02")
+		w := serve(router, http.MethodGet, "/halls/search?date="+date+"&start=10:00", nil)
+		if w.Code != http.StatusOK {
+			t.Fatalf("%s: got %d %s, want 200", date, w.Code, w.Body.String())
+		}
+		var results []hallSearchResult
+		decode(t, w, &results)
+		if len(results) != want {
+			t.Errorf("%s: %d halls, want %d", date, len(results), want)
+		}
+	}
+}
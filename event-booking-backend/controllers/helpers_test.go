@@ -0,0 +1,71 @@
+package controllers
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/glebarez/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+
+	"event-booking-backend/models"
+)
+
+func init() {
+	gin.SetMode(gin.TestMode)
+}
+
+// newTestDB opens an in-memory database with the tables the handlers use.
+// It is SQLite, not Postgres, so handlers that use Postgres-only SQL
+// (ILIKE, jsonb operators) are only tested on their portable paths.
+func newTestDB(t *testing.T) *gorm.DB {
+	t.Helper()
+	dsn := fmt.Sprintf("file:%s?mode=memory&cache=shared", strings.ReplaceAll(t.Name(), "/", "_"))
+	db, err := gorm.Open(sqlite.Open(dsn), &gorm.Config{TranslateError: true, Logger: logger.Default.LogMode(logger.Silent)})
+	if err != nil {
+		t.Fatalf("open test database: %v", err)
+	}
+	sqlDB, err := db.DB()
+	if err != nil {
+		t.Fatalf("open test database: %v", err)
+	}
+	// One connection, so the in-memory database lives as long as the test
+	sqlDB.SetMaxOpenConns(1)
+	t.Cleanup(func() { sqlDB.Close() })
+
+	if err := db.AutoMigrate(&models.Hall{}, &models.Booking{}, &models.BookingHistory{}, &models.AuditLog{},
+		&models.Contact{}, &models.ContactMessage{}, &models.AdminUser{}, &models.RateLimitCounter{},
+		&models.SubmissionReview{}, &models.OutboxMessage{}, &models.RescheduleRequest{}, &models.HallLayout{}, &models.LoginThrottle{}, &models.SecurityEvent{}, &models.MFAPolicy{}, &models.AdminRecoveryCode{}, &models.Customer{}, &models.CustomerToken{}, &models.ImportBatch{}, &models.HallMedia{}, &models.Amenity{}); err != nil {
+		t.Fatalf("migrate test database: %v", err)
+	}
+	return db
+}
+
+// serve sends a request with an optional JSON body through router.
+func serve(router *gin.Engine, method, path string, body interface{}) *httptest.ResponseRecorder {
+	var reader *bytes.Reader
+	if body != nil {
+		raw, _ := json.Marshal(body)
+		reader = bytes.NewReader(raw)
+	} else {
+		reader = bytes.NewReader(nil)
+	}
+	req := httptest.NewRequest(method, path, reader)
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	return w
+}
+
+// decode unmarshals a JSON response into v.
+func decode(t *testing.T, w *httptest.ResponseRecorder, v interface{}) {
+	t.Helper()
+	if err := json.Unmarshal(w.Body.Bytes(), v); err != nil {
+		t.Fatalf("decode response %q: %v", w.Body.String(), err)
+	}
+}
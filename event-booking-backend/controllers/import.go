@@ -0,0 +1,621 @@
+package controllers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/mail"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/xuri/excelize/v2"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"event-booking-backend/middlewares"
+	"event-booking-backend/models"
+	"event-booking-backend/services"
+)
+
+const (
+	importMaxRows  = 10000
+	importMaxBytes = 10 << 20
+	importBatchIns = 500
+)
+
+var (
+	errDryRun           = errors.New("dry run")
+	errImportNotFound   = errors.New("import batch not found")
+	errImportRolledBack = errors.New("import batch has already been rolled back")
+)
+
+// importFileError means the file as a whole cannot be imported, as opposed
+// to individual rows being invalid.
+type importFileError string
+
+func (e importFileError) Error() string { return string(e) }
+
+// importColumns maps each booking field to the header names accepted for
+// it. Headers are matched case-insensitively, with spaces and dashes read
+// as underscores.
+var importColumns = map[string][]string{
+	"hall":             {"hall", "hall_id", "hall_name"},
+	"customer_name":    {"customer_name", "name"},
+	"customer_email":   {"customer_email", "email"},
+	"customer_phone":   {"customer_phone", "phone"},
+	"guest_count":      {"guest_count", "guests"},
+	"layout":           {"layout", "seating_layout", "seating"},
+	"event_date":       {"event_date", "date"},
+	"start_time":       {"start_time", "start", "time"},
+	"status":           {"status"},
+	"total_price":      {"total_price", "price"},
+	"special_requests": {"special_requests", "notes"},
+	"created_at":       {"created_at", "booked_at"},
+}
+
+var importRequired = []string{"hall", "customer_name", "customer_email", "customer_phone",
+	"guest_count", "event_date", "start_time"}
+
+// ImportOptions says where an import comes from and whether to keep it.
+type ImportOptions struct {
+	DryRun   bool
+	Filename string
+	Source   string // api or cli
+	ActorID  *uint
+	// Audit, when set, is the admin request the import is audited under,
+	// in the import's transaction.
+	Audit *gin.Context
+}
+
+// ImportIssue is a row that cannot be imported. Rows are numbered as in a
+// spreadsheet, with the header as row 1.
+type ImportIssue struct {
+	Row     int    `json:"row"`
+	Field   string `json:"field,omitempty"`
+	Message string `json:"message"`
+}
+
+// ImportCollision is a row whose slot is already taken, either by an
+// existing booking or by an earlier row of the same file.
+type ImportCollision struct {
+	Row       int    `json:"row"`
+	HallID    string `json:"hallId"`
+	EventDate string `json:"eventDate"`
+	StartTime string `json:"startTime"`
+	BookingID string `json:"bookingId,omitempty"`
+	OtherRow  int    `json:"otherRow,omitempty"`
+}
+
+// ImportReport describes what an import did, or would do for a dry run.
+type ImportReport struct {
+	BatchID    string            `json:"batchId,omitempty"`
+	DryRun     bool              `json:"dryRun"`
+	Rows       int               `json:"rows"`
+	Valid      int               `json:"valid"`
+	Imported   int               `json:"imported"`
+	Errors     []ImportIssue     `json:"errors"`
+	Collisions []ImportCollision `json:"collisions"`
+}
+
+type importRow struct {
+	row     int
+	booking models.Booking
+}
+
+// BookingImporter loads historical and offline bookings from a table. It is
+// used by the admin API and the import command.
+type BookingImporter struct {
+	db *gorm.DB
+}
+
+func NewBookingImporter(db *gorm.DB) *BookingImporter {
+	return &BookingImporter{db: db}
+}
+
+// Import validates every row of table, whose first row is the header, and
+// creates the valid ones in a single transaction under a new import batch.
+// Invalid and colliding rows are skipped and reported. A dry run does all
+// the same checks, inside the same locks, and then rolls back.
+func (i *BookingImporter) Import(table [][]string, opts ImportOptions) (*ImportReport, error) {
+	if len(table) < 2 {
+		return nil, importFileError("The file has no rows to import")
+	}
+	if len(table)-1 > importMaxRows {
+		return nil, importFileError(fmt.Sprintf("The file has more than %d rows", importMaxRows))
+	}
+	columns, err := importHeader(table[0])
+	if err != nil {
+		return nil, err
+	}
+
+	var halls []models.Hall
+	if err := i.db.Preload("Layouts").Find(&halls).Error; err != nil {
+		return nil, err
+	}
+
+	report := &ImportReport{DryRun: opts.DryRun, Errors: []ImportIssue{}, Collisions: []ImportCollision{}}
+	var rows []importRow
+	for n, record := range table[1:] {
+		if blankRecord(record) {
+			continue
+		}
+		report.Rows++
+		booking, issues := parseImportRow(n+2, record, columns, halls)
+		if len(issues) > 0 {
+			report.Errors = append(report.Errors, issues...)
+			continue
+		}
+		rows = append(rows, importRow{row: n + 2, booking: *booking})
+	}
+
+	err = i.db.Transaction(func(tx *gorm.DB) error {
+		// Lock every hall involved, in a fixed order, so live bookings
+		// cannot take a slot between the collision check and the insert.
+		hallIDs := map[string]bool{}
+		for _, r := range rows {
+			hallIDs[r.booking.HallID] = true
+		}
+		ids := make([]string, 0, len(hallIDs))
+		for id := range hallIDs {
+			ids = append(ids, id)
+		}
+		sort.Strings(ids)
+		for _, id := range ids {
+			if _, err := lockHall(tx, id); err != nil {
+				return err
+			}
+		}
+
+		var valid []models.Booking
+		taken := map[string][]importSlot{}
+		for _, r := range rows {
+			b := r.booking
+			if b.Status != models.StatusCancelled {
+				collision, err := findCollision(tx, &b, r.row, taken)
+				if err != nil {
+					return err
+				}
+				if collision != nil {
+					report.Collisions = append(report.Collisions, *collision)
+					continue
+				}
+			}
+			valid = append(valid, b)
+		}
+		report.Valid = len(valid)
+		if len(valid) == 0 {
+			return nil
+		}
+
+		batchID, _, err := services.NewOpaqueToken()
+		if err != nil {
+			return err
+		}
+		batch := models.ImportBatch{
+			ID:           batchID[:16],
+			Filename:     opts.Filename,
+			Source:       opts.Source,
+			ActorID:      opts.ActorID,
+			Status:       models.ImportBatchImported,
+			RowCount:     len(valid),
+			SkippedCount: report.Rows - len(valid),
+		}
+		if err := tx.Create(&batch).Error; err != nil {
+			return err
+		}
+		for n := range valid {
+			valid[n].ImportBatchID = &batch.ID
+		}
+		if err := tx.CreateInBatches(valid, importBatchIns).Error; err != nil {
+			return err
+		}
+
+		if opts.DryRun {
+			return errDryRun
+		}
+		report.BatchID = batch.ID
+		report.Imported = len(valid)
+		if opts.Audit != nil {
+			summary := gin.H{"filename": opts.Filename, "imported": report.Imported, "skipped": report.Rows - report.Imported}
+			return recordAudit(tx, opts.Audit, "import.created", "import_batch", batch.ID, nil, summary)
+		}
+		return nil
+	})
+	if err != nil && !errors.Is(err, errDryRun) {
+		return nil, err
+	}
+	return report, nil
+}
+
+// Rollback deletes the bookings of an import batch along with their
+// history, and marks the batch rolled back. audit, when set, is the admin
+// request the rollback is audited under.
+func (i *BookingImporter) Rollback(batchID string, audit *gin.Context) (*models.ImportBatch, int64, error) {
+	var batch models.ImportBatch
+	var deleted int64
+	err := i.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&batch, "id = ?", batchID).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return errImportNotFound
+			}
+			return err
+		}
+		if batch.Status == models.ImportBatchRolledBack {
+			return errImportRolledBack
+		}
+
+		bookings := tx.Model(&models.Booking{}).Select("id").Where("import_batch_id = ?", batch.ID)
+		if err := tx.Where("booking_id IN (?)", bookings).Delete(&models.BookingHistory{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("booking_id IN (?)", bookings).Delete(&models.RescheduleRequest{}).Error; err != nil {
+			return err
+		}
+		result := tx.Where("import_batch_id = ?", batch.ID).Delete(&models.Booking{})
+		if result.Error != nil {
+			return result.Error
+		}
+		deleted = result.RowsAffected
+
+		now := time.Now()
+		batch.Status = models.ImportBatchRolledBack
+		batch.RolledBackAt = &now
+		if err := tx.Save(&batch).Error; err != nil {
+			return err
+		}
+		if audit != nil {
+			return recordAudit(tx, audit, "import.rolled_back", "import_batch", batch.ID, nil, gin.H{"deleted": deleted})
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, 0, err
+	}
+	return &batch, deleted, nil
+}
+
+// importSlot is the time a row of the file holds in a hall on a day.
+type importSlot struct {
+	row        int
+	start, end string
+}
+
+// findCollision reports whether b's slot overlaps a live booking or an
+// earlier row of the file, and otherwise claims it in taken, which holds
+// the slots of the file by hall and day.
+func findCollision(tx *gorm.DB, b *models.Booking, row int, taken map[string][]importSlot) (*ImportCollision, error) {
+	date := b.EventDate.Format("2006-01-02")
+	collision := &ImportCollision{Row: row, HallID: b.HallID, EventDate: date, StartTime: b.StartTime}
+
+	key := b.HallID + "|" + date
+	slot := importSlot{row: row, start: b.StartTime, end: slotEnd(b.StartTime)}
+	for _, other := range taken[key] {
+		if other.start < slot.end && slot.start < other.end {
+			collision.OtherRow = other.row
+			return collision, nil
+		}
+	}
+
+	var existing models.Booking
+	query := tx.Select("id").
+		Where("hall_id = ? AND DATE(event_date) = DATE(?) AND status != ?", b.HallID, b.EventDate, models.StatusCancelled)
+	err := whereOverlaps(query, b.StartTime).Take(&existing).Error
+	if err == nil {
+		collision.BookingID = strconv.FormatUint(existing.ID, 10)
+		return collision, nil
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
+
+	taken[key] = append(taken[key], slot)
+	return nil, nil
+}
+
+// importHeader maps booking fields to column positions.
+func importHeader(header []string) (map[string]int, error) {
+	aliases := map[string]string{}
+	for field, names := range importColumns {
+		for _, name := range names {
+			aliases[name] = field
+		}
+	}
+
+	columns := map[string]int{}
+	for n, name := range header {
+		name = strings.NewReplacer(" ", "_", "-", "_").Replace(strings.ToLower(strings.TrimSpace(name)))
+		if field, ok := aliases[name]; ok {
+			if _, dup := columns[field]; !dup {
+				columns[field] = n
+			}
+		}
+	}
+
+	var missing []string
+	for _, field := range importRequired {
+		if _, ok := columns[field]; !ok {
+			missing = append(missing, field)
+		}
+	}
+	if len(missing) > 0 {
+		return nil, importFileError("Missing columns: " + strings.Join(missing, ", "))
+	}
+	return columns, nil
+}
+
+// parseImportRow turns one record into a booking, or lists what is wrong
+// with it.
+func parseImportRow(row int, record []string, columns map[string]int, halls []models.Hall) (*models.Booking, []ImportIssue) {
+	var issues []ImportIssue
+	fail := func(field, format string, args ...interface{}) {
+		issues = append(issues, ImportIssue{Row: row, Field: field, Message: fmt.Sprintf(format, args...)})
+	}
+	value := func(field string) string {
+		n, ok := columns[field]
+		if !ok || n >= len(record) {
+			return ""
+		}
+		return strings.TrimSpace(record[n])
+	}
+	for _, field := range importRequired {
+		if value(field) == "" {
+			fail(field, "%s is required", field)
+		}
+	}
+	if len(issues) > 0 {
+		return nil, issues
+	}
+
+	b := &models.Booking{
+		CustomerName:    value("customer_name"),
+		CustomerEmail:   normalizeEmail(value("customer_email")),
+		CustomerPhone:   value("customer_phone"),
+		SpecialRequests: value("special_requests"),
+		Status:          models.StatusConfirmed,
+	}
+
+	hall := findImportHall(halls, value("hall"))
+	if hall == nil {
+		fail("hall", "unknown hall %q", value("hall"))
+	} else {
+		b.HallID = hall.ID
+	}
+	if _, err := mail.ParseAddress(b.CustomerEmail); err != nil {
+		fail("customer_email", "invalid email address")
+	}
+
+	capacity := 0
+	if hall != nil {
+		capacity = hall.Capacity
+		if v := value("layout"); v != "" {
+			layout := models.SeatingLayout(strings.NewReplacer(" ", "_", "-", "_").Replace(strings.ToLower(v)))
+			if hallLayout := findImportLayout(hall, layout); hallLayout == nil {
+				fail("layout", "%s does not offer the %q layout", hall.Name, v)
+			} else {
+				b.Layout = layout
+				capacity = hallLayout.Capacity
+			}
+		}
+	}
+
+	guests, err := strconv.Atoi(value("guest_count"))
+	switch {
+	case err != nil || guests < 1:
+		fail("guest_count", "guest count must be a positive whole number")
+	case hall != nil && guests > capacity:
+		fail("guest_count", "%d guests exceed the capacity of %s (%d)", guests, hall.Name, capacity)
+	default:
+		b.GuestCount = guests
+	}
+
+	if date, err := parseImportTime(value("event_date")); err != nil {
+		fail("event_date", "event date must be YYYY-MM-DD")
+	} else {
+		y, m, d := date.Date()
+		b.EventDate = time.Date(y, m, d, 0, 0, 0, 0, time.UTC)
+	}
+
+	if start, ok := parseImportClock(value("start_time")); !ok {
+		fail("start_time", "start time must be HH:MM")
+	} else {
+		b.StartTime = start
+		b.EndTime = calculateEndTime(start)
+	}
+
+	if v := value("status"); v != "" {
+		switch status := models.BookingStatus(strings.ToLower(v)); status {
+		case models.StatusPending, models.StatusConfirmed, models.StatusCancelled:
+			b.Status = status
+		default:
+			fail("status", "status must be pending, confirmed or cancelled")
+		}
+	}
+
+	if v := value("created_at"); v != "" {
+		if created, err := parseImportTime(v); err != nil {
+			fail("created_at", "created at must be a date or timestamp")
+		} else {
+			b.CreatedAt = created
+		}
+	}
+
+	if len(issues) > 0 {
+		return nil, issues
+	}
+	// History can be imported for any hall, but new bookings only for halls
+	// that are taking them.
+	if startsAt := b.StartsAt(services.VenueLocation()); b.Status != models.StatusCancelled && startsAt.After(time.Now()) && !hall.AcceptsBookings(startsAt) {
+		fail("hall", "%s is not taking bookings", hall.Name)
+		return nil, issues
+	}
+
+	if v := value("total_price"); v != "" {
+		price, err := strconv.ParseFloat(strings.TrimPrefix(v, "$"), 64)
+		if err != nil || price < 0 {
+			fail("total_price", "total price must be a number")
+			return nil, issues
+		}
+		b.TotalPrice = price
+	} else {
+		b.TotalPrice = calculatePrice(hall, b.EventDate, b.StartTime)
+	}
+	return b, nil
+}
+
+func findImportHall(halls []models.Hall, v string) *models.Hall {
+	for n := range halls {
+		if strings.EqualFold(halls[n].ID, v) || strings.EqualFold(halls[n].Name, v) {
+			return &halls[n]
+		}
+	}
+	return nil
+}
+
+func findImportLayout(hall *models.Hall, layout models.SeatingLayout) *models.HallLayout {
+	for n := range hall.Layouts {
+		if hall.Layouts[n].Layout == layout {
+			return &hall.Layouts[n]
+		}
+	}
+	return nil
+}
+
+// parseImportTime accepts ISO dates and timestamps, and the serial numbers
+// XLSX files store dates as.
+func parseImportTime(v string) (time.Time, error) {
+	for _, layout := range []string{"2006-01-02", time.RFC3339, "2006-01-02 15:04:05", "2006-01-02 15:04"} {
+		if t, err := time.Parse(layout, v); err == nil {
+			return t, nil
+		}
+	}
+	if serial, err := strconv.ParseFloat(v, 64); err == nil && serial > 0 {
+		return excelize.ExcelDateToTime(serial, false)
+	}
+	return time.Time{}, errors.New("unrecognised date")
+}
+
+// parseImportClock accepts HH:MM, HH:MM:SS and the day fractions XLSX files
+// store times as.
+func parseImportClock(v string) (string, bool) {
+	if validStartTime(v) {
+		t, _ := time.Parse("15:04", v)
+		return t.Format("15:04"), true
+	}
+	if t, err := time.Parse("15:04:05", v); err == nil {
+		return t.Format("15:04"), true
+	}
+	if fraction, err := strconv.ParseFloat(v, 64); err == nil && fraction >= 0 && fraction < 1 {
+		minutes := int(fraction*24*60 + 0.5)
+		return fmt.Sprintf("%02d:%02d", minutes/60, minutes%60), true
+	}
+	return "", false
+}
+
+func blankRecord(record []string) bool {
+	for _, v := range record {
+		if strings.TrimSpace(v) != "" {
+			return false
+		}
+	}
+	return true
+}
+
+// ImportController exposes bulk booking imports to managers.
+type ImportController struct {
+	db       *gorm.DB
+	importer *BookingImporter
+}
+
+func NewImportController(db *gorm.DB) *ImportController {
+	return &ImportController{
+		db:       db,
+		importer: NewBookingImporter(db),
+	}
+}
+
+// ImportBookings imports the CSV or XLSX file uploaded as "file". With
+// dryRun=true nothing is saved and the report shows what would happen.
+func (c *ImportController) ImportBookings(ctx *gin.Context) {
+	header, err := ctx.FormFile("file")
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Upload the file as \"file\""})
+		return
+	}
+	if header.Size > importMaxBytes {
+		ctx.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": "File is larger than 10 MB"})
+		return
+	}
+	format, err := services.TableFormat(header.Filename)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	file, err := header.Open()
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Failed to read upload"})
+		return
+	}
+	defer file.Close()
+	table, err := services.ReadTable(format, file)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	dryRun, _ := strconv.ParseBool(ctx.DefaultQuery("dryRun", ctx.PostForm("dryRun")))
+	admin := middlewares.CurrentAdmin(ctx)
+	report, err := c.importer.Import(table, ImportOptions{
+		DryRun:   dryRun,
+		Filename: header.Filename,
+		Source:   "api",
+		ActorID:  &admin.UserID,
+		Audit:    ctx,
+	})
+	var fileErr importFileError
+	switch {
+	case errors.As(err, &fileErr):
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": fileErr.Error()})
+		return
+	case err != nil:
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to import bookings"})
+		return
+	}
+
+	if report.BatchID == "" {
+		ctx.JSON(http.StatusOK, report)
+		return
+	}
+	ctx.JSON(http.StatusCreated, report)
+}
+
+// ListImports returns import batches, newest first
+func (c *ImportController) ListImports(ctx *gin.Context) {
+	var batches []models.ImportBatch
+	if err := c.db.Order("created_at DESC").Limit(200).Find(&batches).Error; err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch imports"})
+		return
+	}
+	ctx.JSON(http.StatusOK, batches)
+}
+
+// RollbackImport deletes every booking created by an import batch
+func (c *ImportController) RollbackImport(ctx *gin.Context) {
+	batch, deleted, err := c.importer.Rollback(ctx.Param("id"), ctx)
+	switch {
+	case errors.Is(err, errImportNotFound):
+		ctx.JSON(http.StatusNotFound, gin.H{"error": "Import not found"})
+		return
+	case errors.Is(err, errImportRolledBack):
+		ctx.JSON(http.StatusConflict, gin.H{"error": "Import has already been rolled back"})
+		return
+	case err != nil:
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to roll back import"})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"batch": batch, "deleted": deleted})
+}
@@ -0,0 +1,121 @@
+package controllers
+
+import (
+	"testing"
+	"time"
+
+	"gorm.io/gorm"
+
+	"event-booking-backend/models"
+)
+
+// newImportDB holds the Garden hall, with a confirmed booking at 10:00 on
+// the day the returned date is.
+func newImportDB(t *testing.T) (*gorm.DB, string) {
+	t.Helper()
+	db := newTestDB(t)
+	if err := db.Create(&models.Hall{ID: "garden", Name: "Garden", Capacity: 100, BasePrice: 1000, Status: models.HallActive}).Error; err != nil {
+		t.Fatal(err)
+	}
+	day := time.Now().AddDate(0, 1, 0)
+	date := time.Date(day.Year(), day.Month(), day.Day(), 0, 0, 0, 0, time.UTC)
+	existing := models.Booking{HallID: "garden", CustomerName: "Old", CustomerEmail: "old@example.com", CustomerPhone: "1",
+		GuestCount: 10, EventDate: date, StartTime: "10:00", EndTime: "12:00", Status: models.StatusConfirmed, Tags: models.StringList{}}
+	if err := db.Create(&existing).Error; err != nil {
+		t.Fatal(err)
+	}
+	return db, date.Format("2006-01-02")
+}
+
+var importHeaderRow = []string{"Hall", "Name", "Email", "Phone", "Guests", "Date", "Start", "Price"}
+
+func TestImportCollisions(t *testing.T) {
+	db, date := newImportDB(t)
+	table := [][]string{
+		importHeaderRow,
+		{"Garden", "A", "a@example.com", "1", "20", date, "14:00", "500"},
+		{"garden", "B", "b@example.com", "1", "20", date, "15:00", "500"}, // overlaps row 2
+		{"Garden", "C", "c@example.com", "1", "20", date, "11:00", "500"}, // overlaps the 10:00 booking
+		{"Garden", "D", "d@example.com", "1", "20", date, "16:00", "500"}, // starts as row 2 ends
+		{"Garden", "E", "not-an-email", "1", "20", date, "18:00", "500"},
+		{"Garden", "F", "f@example.com", "1", "500", date, "20:00", "500"},
+	}
+
+	report, err := NewBookingImporter(db).Import(table, ImportOptions{Filename: "bookings.csv", Source: "cli"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if report.Rows != 6 || report.Imported != 2 || report.BatchID == "" {
+		t.Errorf("report = %+v, want 2 of 6 rows imported", report)
+	}
+	if len(report.Collisions) != 2 {
+		t.Fatalf("collisions = %+v, want rows 3 and 4", report.Collisions)
+	}
+	if c := report.Collisions[0]; c.Row != 3 || c.OtherRow != 2 {
+		t.Errorf("first collision = %+v, want row 3 against row 2", c)
+	}
+	if c := report.Collisions[1]; c.Row != 4 || c.BookingID != "1" {
+		t.Errorf("second collision = %+v, want row 4 against booking 1", c)
+	}
+	fields := map[string]bool{}
+	for _, issue := range report.Errors {
+		fields[issue.Field] = true
+	}
+	if len(report.Errors) != 2 || !fields["customer_email"] || !fields["guest_count"] {
+		t.Errorf("errors = %+v, want the email of row 6 and the guests of row 7", report.Errors)
+	}
+
+	var imported []models.Booking
+	db.Where("import_batch_id = ?", report.BatchID).Order("start_time").Find(&imported)
+	if len(imported) != 2 || imported[0].StartTime != "14:00" || imported[1].StartTime != "16:00" {
+		t.Errorf("imported %+v, want the 14:00 and 16:00 rows", imported)
+	}
+}
+
+func TestImportDryRun(t *testing.T) {
+	db, date := newImportDB(t)
+	table := [][]string{importHeaderRow, {"Garden", "A", "a@example.com", "1", "20", date, "14:00", "500"}}
+
+	report, err := NewBookingImporter(db).Import(table, ImportOptions{DryRun: true, Source: "cli"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !report.DryRun || report.Valid != 1 || report.Imported != 0 || report.BatchID != "" {
+		t.Errorf("report = %+v, want one valid row and nothing imported", report)
+	}
+	var bookings, batches int64
+	db.Model(&models.Booking{}).Count(&bookings)
+	db.Model(&models.ImportBatch{}).Count(&batches)
+	if bookings != 1 || batches != 0 {
+		t.Errorf("dry run left %d bookings and %d batches, want 1 and 0", bookings, batches)
+	}
+}
+
+func TestImportRollback(t *testing.T) {
+	db, date := newImportDB(t)
+	importer := NewBookingImporter(db)
+	table := [][]string{importHeaderRow, {"Garden", "A", "a@example.com", "1", "20", date, "14:00", "500"}}
+	report, err := importer.Import(table, ImportOptions{Source: "cli"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	batch, deleted, err := importer.Rollback(report.BatchID, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if deleted != 1 || batch.Status != models.ImportBatchRolledBack {
+		t.Errorf("rollback deleted %d, batch %+v", deleted, batch)
+	}
+	var bookings int64
+	db.Model(&models.Booking{}).Count(&bookings)
+	if bookings != 1 {
+		t.Errorf("%d bookings after rollback, want only the one not imported", bookings)
+	}
+	if _, _, err := importer.Rollback(report.BatchID, nil); err != errImportRolledBack {
+		t.Errorf("second rollback: %v, want errImportRolledBack", err)
+	}
+	if _, _, err := importer.Rollback("missing", nil); err != errImportNotFound {
+		t.Errorf("unknown batch: %v, want errImportNotFound", err)
+	}
+}
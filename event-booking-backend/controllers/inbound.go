@@ -0,0 +1,263 @@
+package controllers
+
+import (
+	"crypto/subtle"
+	"errors"
+	"io"
+	"log"
+	"net/http"
+	"net/mail"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"event-booking-backend/models"
+	"event-booking-backend/services"
+)
+
+// maxInboundEmailSize caps a raw inbound message, attachments included.
+const maxInboundEmailSize = 10 << 20
+
+// InboundController takes in customer replies to our email, from the SMTP
+// listener or a provider's inbound webhook, and appends them to the
+// conversation they answer. Replies about a booking go to its ticket,
+// which is opened on the first reply.
+type InboundController struct {
+	db            *gorm.DB
+	replies       *services.ReplyAddressService
+	sla           services.TicketSLA
+	webhookSecret string
+}
+
+func NewInboundController(db *gorm.DB, replies *services.ReplyAddressService, sla services.TicketSLA) *InboundController {
+	return &InboundController{
+		db:            db,
+		replies:       replies,
+		sla:           sla,
+		webhookSecret: os.Getenv("INBOUND_WEBHOOK_SECRET"),
+	}
+}
+
+// Accept reports whether recipient is one of our reply addresses.
+func (ic *InboundController) Accept(recipient string) bool {
+	_, ok := ic.replies.Parse(recipient)
+	return ok
+}
+
+// Deliver parses a raw message and appends it to its conversation. It
+// returns services.ErrNoConversation for mail that answers nothing of ours
+// or cannot be read at all. A message already taken in, by its Message-ID,
+// is accepted again without being added twice, since mail servers retry.
+func (ic *InboundController) Deliver(recipients []string, raw []byte) error {
+	message, err := services.ParseInboundMessage(raw, recipients)
+	if err != nil {
+		return services.ErrNoConversation
+	}
+	if message.Text == "" {
+		return nil
+	}
+
+	return ic.db.Transaction(func(tx *gorm.DB) error {
+		if message.MessageID != "" {
+			var seen int64
+			if err := tx.Model(&models.ContactMessage{}).Where("email_message_id = ?", message.MessageID).Count(&seen).Error; err != nil {
+				return err
+			}
+			if seen == 0 {
+				if err := tx.Model(&models.Contact{}).Where("email_message_id = ?", message.MessageID).Count(&seen).Error; err != nil {
+					return err
+				}
+			}
+			if seen > 0 {
+				return nil
+			}
+		}
+
+		// The first recipient that is a reply address names the
+		// conversation. Only its signature is trusted: the ticket number in
+		// the subject and the From header are both easy to forge.
+		for _, recipient := range message.Recipients {
+			target, ok := ic.replies.Parse(recipient)
+			switch {
+			case !ok:
+			case target.Kind == services.ReplyToBooking:
+				return ic.appendToBooking(tx, target.ID, message)
+			default:
+				return ic.appendToContact(tx, target.ID, message)
+			}
+		}
+		return services.ErrNoConversation
+	})
+}
+
+// appendToContact adds message to ticket id and reopens it, so it shows up
+// as waiting on staff again.
+func (ic *InboundController) appendToContact(tx *gorm.DB, id uint64, message *services.InboundMessage) error {
+	var contact models.Contact
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&contact, "id = ?", id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return services.ErrNoConversation
+		}
+		return err
+	}
+
+	entry := models.ContactMessage{
+		ContactID:      contact.ID,
+		Kind:           models.ContactCustomer,
+		AuthorName:     inboundAuthor(message),
+		Body:           message.Text,
+		EmailMessageID: message.MessageID,
+	}
+	if err := tx.Create(&entry).Error; err != nil {
+		return err
+	}
+	setContactStatus(&contact, models.ContactOpen, time.Now())
+	return tx.Model(&contact).Omit(clause.Associations).Updates(map[string]interface{}{
+		"status":      contact.Status,
+		"resolved_at": contact.ResolvedAt,
+	}).Error
+}
+
+// appendToBooking adds message to the newest unresolved ticket about
+// booking id, opening one in the booking customer's name when there is
+// none.
+func (ic *InboundController) appendToBooking(tx *gorm.DB, id uint64, message *services.InboundMessage) error {
+	var booking models.Booking
+	if err := tx.First(&booking, "id = ?", id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return services.ErrNoConversation
+		}
+		return err
+	}
+
+	var contact models.Contact
+	err := tx.Where("booking_id = ? AND status != ?", booking.ID, models.ContactResolved).
+		Order("id DESC").First(&contact).Error
+	if err == nil {
+		return ic.appendToContact(tx, uint64(contact.ID), message)
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return err
+	}
+
+	now := time.Now()
+	firstResponseDue, resolutionDue := now.Add(ic.sla.FirstResponse), now.Add(ic.sla.Resolution)
+	subject := message.Subject
+	if subject == "" {
+		subject = "Reply about booking " + strconv.FormatUint(booking.ID, 10)
+	}
+	contact = models.Contact{
+		Name:               booking.CustomerName,
+		Email:              normalizeEmail(booking.CustomerEmail),
+		Phone:              booking.CustomerPhone,
+		Subject:            truncateRunes(subject, 200),
+		Message:            message.Text,
+		Status:             models.ContactOpen,
+		BookingID:          &booking.ID,
+		Tags:               models.StringList{},
+		Locale:             booking.Locale,
+		FirstResponseDueAt: &firstResponseDue,
+		ResolutionDueAt:    &resolutionDue,
+		EmailMessageID:     message.MessageID,
+	}
+	return tx.Create(&contact).Error
+}
+
+// inboundAuthor names the sender of message in the thread.
+func inboundAuthor(message *services.InboundMessage) string {
+	if message.FromName != "" {
+		return message.FromName + " <" + message.From + ">"
+	}
+	return message.From
+}
+
+// truncateRunes shortens s to at most n characters.
+func truncateRunes(s string, n int) string {
+	runes := []rune(s)
+	if len(runes) <= n {
+		return s
+	}
+	return string(runes[:n])
+}
+
+// Receive is the inbound webhook for mail providers that post replies
+// rather than relay them over SMTP. The body is the raw message, either as
+// message/rfc822 or as a form field: "body-mime" (Mailgun) or "email"
+// (SendGrid), with the envelope recipient in "recipient" or "to". The
+// shared secret comes in the X-Inbound-Secret header or the secret query
+// parameter, since not every provider can set headers.
+func (ic *InboundController) Receive(ctx *gin.Context) {
+	if ic.webhookSecret == "" {
+		ctx.JSON(http.StatusNotFound, gin.H{"error": "Inbound email is not enabled"})
+		return
+	}
+	secret := ctx.GetHeader("X-Inbound-Secret")
+	if secret == "" {
+		secret = ctx.Query("secret")
+	}
+	if subtle.ConstantTimeCompare([]byte(secret), []byte(ic.webhookSecret)) != 1 {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid inbound secret"})
+		return
+	}
+
+	ctx.Request.Body = http.MaxBytesReader(ctx.Writer, ctx.Request.Body, maxInboundEmailSize)
+	var raw []byte
+	var recipients []string
+	if strings.HasPrefix(ctx.ContentType(), "multipart/") || ctx.ContentType() == "application/x-www-form-urlencoded" {
+		if err := ctx.Request.ParseMultipartForm(maxInboundEmailSize); err != nil && !errors.Is(err, http.ErrNotMultipart) {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid form body"})
+			return
+		}
+		for _, field := range []string{"body-mime", "email"} {
+			if v := ctx.PostForm(field); v != "" {
+				raw = []byte(v)
+				break
+			}
+		}
+		for _, field := range []string{"recipient", "to"} {
+			if v := ctx.PostForm(field); v != "" {
+				addresses, err := mail.ParseAddressList(v)
+				if err != nil {
+					recipients = strings.Split(v, ",")
+					break
+				}
+				for _, address := range addresses {
+					recipients = append(recipients, address.Address)
+				}
+				break
+			}
+		}
+	} else {
+		body, err := io.ReadAll(ctx.Request.Body)
+		if err != nil {
+			ctx.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": "Message is too large"})
+			return
+		}
+		raw = body
+	}
+	if len(raw) == 0 {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "No message in the request"})
+		return
+	}
+	for i, recipient := range recipients {
+		recipients[i] = strings.TrimSpace(recipient)
+	}
+
+	err := ic.Deliver(recipients, raw)
+	switch {
+	case errors.Is(err, services.ErrNoConversation):
+		// A 2xx would tell the provider the reply was taken; 406 is what
+		// Mailgun reads as "reject, do not retry".
+		ctx.JSON(http.StatusNotAcceptable, gin.H{"error": err.Error()})
+	case err != nil:
+		log.Printf("Failed to take in inbound email for %v: %v", recipients, err)
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to take in the message"})
+	default:
+		ctx.JSON(http.StatusOK, gin.H{"message": "Message received"})
+	}
+}
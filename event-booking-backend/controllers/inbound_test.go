@@ -0,0 +1,142 @@
+package controllers
+
+import (
+	"errors"
+	"net"
+	"net/smtp"
+	"net/textproto"
+	"testing"
+	"time"
+
+	"gorm.io/gorm"
+
+	"event-booking-backend/models"
+	"event-booking-backend/services"
+)
+
+type inboundFixture struct {
+	db      *gorm.DB
+	inbound *InboundController
+	replies *services.ReplyAddressService
+	addr    string
+}
+
+// newInboundFixture serves the inbound controller over SMTP on a local
+// port, as main does.
+func newInboundFixture(t *testing.T) *inboundFixture {
+	t.Helper()
+	t.Setenv("INBOUND_DOMAIN", "replies.example.com")
+	t.Setenv("INBOUND_REPLY_SECRET", "test-reply-secret")
+	replies, err := services.NewReplyAddressService()
+	if err != nil {
+		t.Fatal(err)
+	}
+	db := newTestDB(t)
+	inbound := NewInboundController(db, replies, services.TicketSLA{FirstResponse: time.Hour, Resolution: 24 * time.Hour})
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	server := &services.InboundSMTPServer{
+		Hostname: "replies.example.com",
+		MaxSize:  1 << 20,
+		Accept:   inbound.Accept,
+		Deliver:  inbound.Deliver,
+	}
+	go server.Serve(listener)
+	t.Cleanup(func() { listener.Close() })
+	return &inboundFixture{db: db, inbound: inbound, replies: replies, addr: listener.Addr().String()}
+}
+
+func inboundMessage(to, messageID, subject, text string) []byte {
+	return []byte("From: Asha <asha@example.com>\r\n" +
+		"To: " + to + "\r\n" +
+		"Subject: " + subject + "\r\n" +
+		"Message-ID: <" + messageID + ">\r\n" +
+		"Content-Type: text/plain; charset=utf-8\r\n" +
+		"\r\n" + text + "\r\n")
+}
+
+func (f *inboundFixture) send(to, messageID, subject, text string) error {
+	return smtp.SendMail(f.addr, nil, "asha@example.com", []string{to}, inboundMessage(to, messageID, subject, text))
+}
+
+func smtpCode(err error) int {
+	var protoErr *textproto.Error
+	if errors.As(err, &protoErr) {
+		return protoErr.Code
+	}
+	return 0
+}
+
+func TestInboundSMTPRefusesUnknownRecipients(t *testing.T) {
+	f := newInboundFixture(t)
+	signed := f.replies.Address(services.ReplyToContact, 1)
+	forged := signed[:len("reply+c1.")] + "0000" + signed[len("reply+c1.")+4:]
+
+	for _, to := range []string{"support@replies.example.com", "reply+c1@replies.example.com", forged} {
+		if err := f.send(to, "refused@example.com", "Hello", "Hi"); smtpCode(err) != 550 {
+			t.Errorf("mail to %s: got %v, want 550 at RCPT", to, err)
+		}
+	}
+}
+
+func TestInboundSMTPThreadsReplies(t *testing.T) {
+	f := newInboundFixture(t)
+	contact := models.Contact{Name: "Asha", Email: "asha@example.com", Subject: "Parking", Message: "Is there parking?",
+		Status: models.ContactResolved, Tags: models.StringList{}}
+	f.db.Create(&contact)
+	booking := models.Booking{HallID: "garden", CustomerName: "Asha", CustomerEmail: "asha@example.com", CustomerPhone: "555",
+		GuestCount: 50, EventDate: time.Now().AddDate(0, 1, 0), StartTime: "10:00", EndTime: "14:00",
+		Status: models.StatusConfirmed, TotalPrice: 1000, Tags: models.StringList{}}
+	f.db.Create(&booking)
+
+	toContact := f.replies.Address(services.ReplyToContact, uint64(contact.ID))
+	toBooking := f.replies.Address(services.ReplyToBooking, booking.ID)
+	for _, m := range []struct{ to, id string }{
+		{toContact, "one@example.com"},
+		{toContact, "one@example.com"}, // retried by the sender's server
+		{toBooking, "two@example.com"},
+		{toBooking, "three@example.com"},
+	} {
+		if err := f.send(m.to, m.id, "Re: your booking", "Thanks"); err != nil {
+			t.Fatalf("mail %s: %v", m.id, err)
+		}
+	}
+
+	var replies int64
+	f.db.Model(&models.ContactMessage{}).Where("contact_id = ?", contact.ID).Count(&replies)
+	f.db.First(&contact, contact.ID)
+	if replies != 1 || contact.Status != models.ContactOpen {
+		t.Errorf("ticket has %d replies and is %s; want 1 and open", replies, contact.Status)
+	}
+
+	var tickets []models.Contact
+	f.db.Where("booking_id = ?", booking.ID).Find(&tickets)
+	if len(tickets) != 1 {
+		t.Fatalf("%d tickets about the booking, want 1", len(tickets))
+	}
+	f.db.Model(&models.ContactMessage{}).Where("contact_id = ?", tickets[0].ID).Count(&replies)
+	if tickets[0].EmailMessageID != "two@example.com" || replies != 1 {
+		t.Errorf("booking ticket opened by %q with %d replies; want two@example.com and 1", tickets[0].EmailMessageID, replies)
+	}
+}
+
+func TestInboundIgnoresTicketNumberInSubject(t *testing.T) {
+	f := newInboundFixture(t)
+	contact := models.Contact{Name: "Asha", Email: "asha@example.com", Subject: "Parking", Message: "Is there parking?",
+		Status: models.ContactOpen, Tags: models.StringList{}}
+	f.db.Create(&contact)
+
+	// Webhook deliveries may name no reply address at all
+	raw := inboundMessage("support@example.com", "forged@example.com", contactSubject(&contact), "Cancel everything")
+	if err := f.inbound.Deliver(nil, raw); !errors.Is(err, services.ErrNoConversation) {
+		t.Errorf("got %v, want ErrNoConversation", err)
+	}
+	var replies int64
+	f.db.Model(&models.ContactMessage{}).Count(&replies)
+	if replies != 0 {
+		t.Errorf("%d replies stored, want none", replies)
+	}
+}
@@ -0,0 +1,18 @@
+package controllers
+
+import (
+	"github.com/gin-gonic/gin"
+
+	"event-booking-backend/services"
+)
+
+// requestLocale is the language to email the sender of a request in: the
+// locale they asked for, else the first supported one in their
+// Accept-Language header. It is "" when neither is supported, which means
+// the default.
+func requestLocale(ctx *gin.Context, locale string) string {
+	if matched := services.MatchLocale(locale); matched != "" {
+		return matched
+	}
+	return services.MatchLocale(ctx.GetHeader("Accept-Language"))
+}
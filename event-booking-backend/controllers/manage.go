@@ -0,0 +1,236 @@
+package controllers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"event-booking-backend/models"
+	"event-booking-backend/services"
+)
+
+// ManageController serves the customer self-service endpoints reached
+// through the signed link in the confirmation email.
+type ManageController struct {
+	db     *gorm.DB
+	email  *services.Mailer
+	links  *services.ManageLinkService
+	policy services.BookingPolicy
+}
+
+func NewManageController(db *gorm.DB, email *services.Mailer, links *services.ManageLinkService, policy services.BookingPolicy) *ManageController {
+	return &ManageController{
+		db:     db,
+		email:  email,
+		links:  links,
+		policy: policy,
+	}
+}
+
+// GetBooking returns the booking behind the link together with what the
+// cancellation policy currently allows.
+func (c *ManageController) GetBooking(ctx *gin.Context) {
+	booking, ok := c.loadBooking(ctx)
+	if !ok {
+		return
+	}
+
+	var hall models.Hall
+	c.db.First(&hall, "id = ?", booking.HallID)
+
+	ctx.JSON(http.StatusOK, gin.H{
+		"booking":      booking,
+		"hall":         hall,
+		"cancellation": c.cancellationFor(booking),
+	})
+}
+
+// CancelBooking cancels the booking if the policy still allows it. The row
+// is locked while the policy is checked, so a repeated submit is refused
+// rather than cancelling and emailing twice.
+func (c *ManageController) CancelBooking(ctx *gin.Context) {
+	link, ok := c.loadBooking(ctx)
+	if !ok {
+		return
+	}
+
+	var booking models.Booking
+	err := c.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&booking, link.ID).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return errBookingNotFound
+			}
+			return err
+		}
+		if !canTransition(booking.Status, models.StatusCancelled) {
+			return statusTransitionError{from: booking.Status, to: models.StatusCancelled}
+		}
+		decision := c.cancellationFor(&booking)
+		if !decision.Allowed {
+			return policyError(decision.Reason)
+		}
+
+		now := time.Now()
+		booking.Status = models.StatusCancelled
+		booking.RefundAmount = decision.RefundAmount
+		booking.CancelledAt = &now
+		if err := tx.Model(&booking).Select("status", "refund_amount", "cancelled_at").Updates(&booking).Error; err != nil {
+			return err
+		}
+		if err := tx.Create(&models.BookingHistory{
+			BookingID:     booking.ID,
+			Action:        models.HistoryCancelled,
+			Actor:         "customer",
+			PreviousPrice: booking.TotalPrice,
+			NewPrice:      booking.TotalPrice,
+			Note:          fmt.Sprintf("Refund of %.2f under cancellation policy", decision.RefundAmount),
+		}).Error; err != nil {
+			return err
+		}
+		return c.email.In(tx).SendCancellationConfirmation(&booking, &booking.RefundAmount)
+	})
+	var transitionErr statusTransitionError
+	var policyErr policyError
+	switch {
+	case errors.As(err, &transitionErr):
+		ctx.JSON(http.StatusConflict, gin.H{"error": transitionErr.Error()})
+	case errors.As(err, &policyErr):
+		ctx.JSON(http.StatusUnprocessableEntity, gin.H{"error": policyErr.Error()})
+	case errors.Is(err, errBookingNotFound):
+		ctx.JSON(http.StatusNotFound, gin.H{"error": "Booking not found"})
+	case err != nil:
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to cancel booking"})
+	default:
+		ctx.JSON(http.StatusOK, booking)
+	}
+}
+
+// RequestReschedule records the slot the customer would like to move to and
+// lets staff know.
+func (c *ManageController) RequestReschedule(ctx *gin.Context) {
+	booking, ok := c.loadBooking(ctx)
+	if !ok {
+		return
+	}
+
+	var request struct {
+		EventDate time.Time `json:"eventDate" binding:"required"`
+		StartTime string    `json:"startTime" binding:"required"`
+		Note      string    `json:"note"`
+	}
+	if err := ctx.ShouldBindJSON(&request); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if booking.Status == models.StatusCancelled {
+		ctx.JSON(http.StatusConflict, gin.H{"error": "Cancelled bookings cannot be rescheduled"})
+		return
+	}
+	if request.EventDate.Before(time.Now()) {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Event date must be in the future"})
+		return
+	}
+	if !validStartTime(request.StartTime) {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Start time must be in HH:MM format"})
+		return
+	}
+
+	reschedule := &models.RescheduleRequest{
+		BookingID:          booking.ID,
+		RequestedDate:      request.EventDate,
+		RequestedStartTime: request.StartTime,
+		Note:               request.Note,
+		Status:             models.RescheduleRequestOpen,
+	}
+	err := c.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(reschedule).Error; err != nil {
+			return err
+		}
+		return c.email.In(tx).SendRescheduleRequestNotification(booking, reschedule)
+	})
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to record reschedule request"})
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, reschedule)
+}
+
+// DownloadConfirmation returns a printable HTML confirmation.
+func (c *ManageController) DownloadConfirmation(ctx *gin.Context) {
+	booking, hall, ok := c.loadBookingWithHall(ctx)
+	if !ok {
+		return
+	}
+
+	doc, err := services.BookingConfirmationHTML(booking, hall)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to render confirmation"})
+		return
+	}
+
+	ctx.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="booking-%d.html"`, booking.ID))
+	ctx.Data(http.StatusOK, "text/html; charset=utf-8", doc)
+}
+
+// DownloadCalendar returns the booking as an .ics calendar entry.
+func (c *ManageController) DownloadCalendar(ctx *gin.Context) {
+	booking, hall, ok := c.loadBookingWithHall(ctx)
+	if !ok {
+		return
+	}
+
+	ctx.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="booking-%d.ics"`, booking.ID))
+	ctx.Data(http.StatusOK, "text/calendar; charset=utf-8", services.BookingCalendarICS(booking, hall))
+}
+
+// loadBooking verifies the link token and loads its booking, writing the
+// error response itself when that fails.
+func (c *ManageController) loadBooking(ctx *gin.Context) (*models.Booking, bool) {
+	claims, err := c.links.Verify(ctx.Param("token"))
+	if err != nil {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		return nil, false
+	}
+
+	var booking models.Booking
+	if err := c.db.First(&booking, claims.BookingID).Error; err != nil {
+		ctx.JSON(http.StatusNotFound, gin.H{"error": "Booking not found"})
+		return nil, false
+	}
+
+	// A link stops working if the booking is moved to another customer.
+	if booking.CustomerEmail != claims.Email {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "invalid or expired manage link"})
+		return nil, false
+	}
+
+	return &booking, true
+}
+
+func (c *ManageController) loadBookingWithHall(ctx *gin.Context) (*models.Booking, *models.Hall, bool) {
+	booking, ok := c.loadBooking(ctx)
+	if !ok {
+		return nil, nil, false
+	}
+
+	var hall models.Hall
+	if err := c.db.First(&hall, "id = ?", booking.HallID).Error; err != nil {
+		ctx.JSON(http.StatusNotFound, gin.H{"error": "Hall not found"})
+		return nil, nil, false
+	}
+	return booking, &hall, true
+}
+
+func (c *ManageController) cancellationFor(booking *models.Booking) services.CancellationDecision {
+	if booking.Status == models.StatusCancelled {
+		return services.CancellationDecision{Reason: "Booking is already cancelled"}
+	}
+	return c.policy.Cancellation(booking.StartsAt(services.VenueLocation()), booking.TotalPrice, time.Now())
+}
@@ -0,0 +1,162 @@
+package controllers
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+
+	"event-booking-backend/models"
+	"event-booking-backend/services"
+)
+
+// manageFixture is a hall with one confirmed booking, served through
+// the customer manage routes.
+type manageFixture struct {
+	db       *gorm.DB
+	router   *gin.Engine
+	notifier *services.LogNotifier
+	links    *services.ManageLinkService
+	booking  models.Booking
+}
+
+func newManageFixture(t *testing.T) *manageFixture {
+	t.Helper()
+	t.Setenv("MANAGE_LINK_SECRET", "test-manage-secret")
+	t.Setenv("RESCHEDULE_FEE", "")
+	db := newTestDB(t)
+	links, err := services.NewManageLinkService()
+	if err != nil {
+		t.Fatal(err)
+	}
+	notifier, _ := services.NewLogNotifier("")
+	mailer := services.NewMailer(notifier, nil)
+
+	hall := models.Hall{ID: "garden", Name: "Garden", Capacity: 100, BasePrice: 1000, Status: models.HallActive}
+	if err := db.Create(&hall).Error; err != nil {
+		t.Fatal(err)
+	}
+	f := &manageFixture{db: db, notifier: notifier, links: links}
+	f.booking = f.addBooking(t, time.Now().AddDate(0, 0, 10), "10:00")
+
+	manage := NewManageController(db, mailer, links, services.LoadBookingPolicy())
+	f.router = gin.New()
+	f.router.POST("/manage/:token/reschedule", manage.RescheduleBooking)
+	f.router.POST("/manage/:token/cancel", manage.CancelBooking)
+	return f
+}
+
+func (f *manageFixture) addBooking(t *testing.T, date time.Time, start string) models.Booking {
+	t.Helper()
+	booking := models.Booking{
+		HallID:        "garden",
+		CustomerName:  "Asha",
+		CustomerEmail: "asha@example.com",
+		CustomerPhone: "555",
+		GuestCount:    50,
+		EventDate:     date,
+		StartTime:     start,
+		EndTime:       calculateEndTime(start),
+		Status:        models.StatusConfirmed,
+		TotalPrice:    1000,
+		Tags:          models.StringList{},
+	}
+	if err := f.db.Create(&booking).Error; err != nil {
+		t.Fatal(err)
+	}
+	return booking
+}
+
+func (f *manageFixture) reschedule(t *testing.T, booking models.Booking, date time.Time, start string) *rescheduleResult {
+	t.Helper()
+	token, err := f.links.Token(&booking)
+	if err != nil {
+		t.Fatal(err)
+	}
+	w := serve(f.router, http.MethodPost, "/manage/"+token+"/reschedule", gin.H{"eventDate": date, "startTime": start})
+	if w.Code != http.StatusOK {
+		t.Fatalf("reschedule to %s %s: got %d %s", date.Format("2006-01-02"), start, w.Code, w.Body.String())
+	}
+	var result rescheduleResult
+	decode(t, w, &result)
+	return &result
+}
+
+func TestCancelBookingOnce(t *testing.T) {
+	f := newManageFixture(t)
+	token, err := f.links.Token(&f.booking)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	w := serve(f.router, http.MethodPost, "/manage/"+token+"/cancel", nil)
+	if w.Code != http.StatusOK {
+		t.Fatalf("cancel: got %d %s, want 200", w.Code, w.Body.String())
+	}
+	var booking models.Booking
+	decode(t, w, &booking)
+	if booking.Status != models.StatusCancelled || booking.RefundAmount != booking.TotalPrice || booking.CancelledAt == nil {
+		t.Errorf("cancelled booking = %+v", booking)
+	}
+
+	// A second submit of the same link is refused and sends nothing more
+	if w := serve(f.router, http.MethodPost, "/manage/"+token+"/cancel", nil); w.Code != http.StatusConflict {
+		t.Errorf("cancel again: got %d %s, want 409", w.Code, w.Body.String())
+	}
+	sent := f.notifier.Sent()
+	if len(sent) != 1 || sent[0].Template != services.TemplateBookingCancelled {
+		t.Fatalf("sent = %+v, want one cancellation", sent)
+	}
+	if !strings.Contains(sent[0].Text, "Hall:    Garden") || !strings.Contains(sent[0].Text, "Refund: 1000.00") {
+		t.Errorf("cancellation does not name the hall and the refund:\n%s", sent[0].Text)
+	}
+	var entries int64
+	f.db.Model(&models.BookingHistory{}).Where("booking_id = ? AND action = ?", booking.ID, models.HistoryCancelled).Count(&entries)
+	if entries != 1 {
+		t.Errorf("%d cancellation history entries, want 1", entries)
+	}
+}
+
+func TestCancelBookingPastCutoff(t *testing.T) {
+	f := newManageFixture(t)
+	start := time.Now().Add(2 * time.Hour).In(services.VenueLocation())
+	soon := f.addBooking(t, start, start.Format("15:04"))
+	token, err := f.links.Token(&soon)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if w := serve(f.router, http.MethodPost, "/manage/"+token+"/cancel", nil); w.Code != http.StatusUnprocessableEntity {
+		t.Errorf("got %d %s, want 422", w.Code, w.Body.String())
+	}
+	if sent := f.notifier.Sent(); len(sent) != 0 {
+		t.Errorf("sent %d messages, want none", len(sent))
+	}
+}
+
+func TestStaffCancellationStatesNoRefund(t *testing.T) {
+	f := newManageFixture(t)
+	notifier, _ := services.NewLogNotifier("")
+	bc := NewBookingController(f.db, services.NewMailer(notifier, nil), f.links, services.LoadBookingPolicy(), nil)
+	router := gin.New()
+	router.PUT("/admin/bookings/:id/status", bc.UpdateBookingStatus)
+
+	path := "/admin/bookings/" + strconv.FormatUint(f.booking.ID, 10) + "/status"
+	if w := serve(router, http.MethodPut, path, gin.H{"status": models.StatusCancelled}); w.Code != http.StatusOK {
+		t.Fatalf("got %d %s, want 200", w.Code, w.Body.String())
+	}
+	sent := notifier.Sent()
+	if len(sent) != 1 || sent[0].Template != services.TemplateBookingCancelled {
+		t.Fatalf("sent = %+v, want one cancellation", sent)
+	}
+	if strings.Contains(sent[0].Text, "Refund") || strings.Contains(sent[0].HTML, "Refund") {
+		t.Errorf("staff cancellation mentions a refund:\n%s", sent[0].Text)
+	}
+	if !strings.Contains(sent[0].Text, "Garden") {
+		t.Errorf("cancellation does not name the hall:\n%s", sent[0].Text)
+	}
+}
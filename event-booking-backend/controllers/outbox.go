@@ -0,0 +1,138 @@
+package controllers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+
+	"event-booking-backend/models"
+	"event-booking-backend/services"
+)
+
+// OutboxController lets staff see what email went out, what is waiting and
+// what gave up, and send it again.
+type OutboxController struct {
+	db     *gorm.DB
+	outbox *services.Outbox
+}
+
+func NewOutboxController(db *gorm.DB, outbox *services.Outbox) *OutboxController {
+	return &OutboxController{
+		db:     db,
+		outbox: outbox,
+	}
+}
+
+// outboxPage is the response of the outbox list.
+type outboxPage struct {
+	Messages     []models.OutboxMessage        `json:"messages"`
+	NextBeforeID string                        `json:"nextBeforeId,omitempty"`
+	Counts       map[models.OutboxStatus]int64 `json:"counts"`
+}
+
+// ListOutbox returns messages newest first, with the count per status.
+// Filters: status, template and recipient. Pass nextBeforeId as beforeId
+// to get the next page.
+func (c *OutboxController) ListOutbox(ctx *gin.Context) {
+	limit, err := strconv.Atoi(ctx.DefaultQuery("limit", "100"))
+	if err != nil || limit < 1 || limit > 500 {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "limit must be between 1 and 500"})
+		return
+	}
+
+	page := outboxPage{
+		Messages: []models.OutboxMessage{},
+		Counts: map[models.OutboxStatus]int64{
+			models.OutboxPending: 0,
+			models.OutboxSent:    0,
+			models.OutboxDead:    0,
+		},
+	}
+	var counts []struct {
+		Status models.OutboxStatus
+		Count  int64
+	}
+	if err := c.db.Model(&models.OutboxMessage{}).Select("status, COUNT(*) AS count").Group("status").Scan(&counts).Error; err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to count outbox messages"})
+		return
+	}
+	for _, row := range counts {
+		page.Counts[row.Status] = row.Count
+	}
+
+	query := c.db.Order("id DESC").Limit(limit + 1)
+	if status := ctx.Query("status"); status != "" {
+		query = query.Where("status = ?", status)
+	}
+	if template := ctx.Query("template"); template != "" {
+		query = query.Where("template = ?", template)
+	}
+	if recipient := ctx.Query("recipient"); recipient != "" {
+		query = query.Where("LOWER(recipient) = ?", strings.ToLower(strings.TrimSpace(recipient)))
+	}
+	if v := ctx.Query("beforeId"); v != "" {
+		id, err := strconv.ParseUint(v, 10, 64)
+		if err != nil {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid beforeId"})
+			return
+		}
+		query = query.Where("id < ?", id)
+	}
+	if err := query.Find(&page.Messages).Error; err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch outbox messages"})
+		return
+	}
+	if len(page.Messages) > limit {
+		page.Messages = page.Messages[:limit]
+		page.NextBeforeID = strconv.FormatUint(page.Messages[limit-1].ID, 10)
+	}
+	ctx.JSON(http.StatusOK, page)
+}
+
+// GetOutboxMessage returns one message with its content. The tokens of
+// password reset, verification and manage links are redacted: they are
+// only for the recipient.
+func (c *OutboxController) GetOutboxMessage(ctx *gin.Context) {
+	var row models.OutboxMessage
+	if err := c.db.First(&row, "id = ?", ctx.Param("id")).Error; err != nil {
+		ctx.JSON(http.StatusNotFound, gin.H{"error": "Outbox message not found"})
+		return
+	}
+	ctx.JSON(http.StatusOK, gin.H{
+		"message": row,
+		"content": json.RawMessage(services.RedactPayload(row.Payload)),
+	})
+}
+
+// ResendOutboxMessage queues a sent or dead message again.
+func (c *OutboxController) ResendOutboxMessage(ctx *gin.Context) {
+	id, err := strconv.ParseUint(ctx.Param("id"), 10, 64)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid outbox message ID"})
+		return
+	}
+
+	var row *models.OutboxMessage
+	err = c.db.Transaction(func(tx *gorm.DB) error {
+		var err error
+		if row, err = c.outbox.Resend(tx, id); err != nil {
+			return err
+		}
+		return recordAudit(tx, ctx, "outbox.resent", "outbox_message", strconv.FormatUint(id, 10), nil, row)
+	})
+	switch {
+	case errors.Is(err, services.ErrOutboxMessageNotFound):
+		ctx.JSON(http.StatusNotFound, gin.H{"error": "Outbox message not found"})
+	case errors.Is(err, services.ErrOutboxMessageQueued):
+		ctx.JSON(http.StatusConflict, gin.H{"error": "This message is already waiting to be sent"})
+	case err != nil:
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to resend message"})
+	default:
+		ctx.JSON(http.StatusOK, row)
+	}
+}
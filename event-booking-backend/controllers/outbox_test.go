@@ -0,0 +1,59 @@
+package controllers
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+
+	"event-booking-backend/models"
+	"event-booking-backend/services"
+)
+
+func TestGetOutboxMessageRedactsLinks(t *testing.T) {
+	db := newTestDB(t)
+	outbox := services.NewOutbox(db, nil, services.LoadOutboxPolicy())
+	mailer := services.NewMailer(outbox, nil)
+	customer := &models.Customer{ID: 3, Email: "jo@example.com", Name: "Jo"}
+	if err := mailer.SendPasswordReset(customer, "https://venue.example/?reset=s3cret-Token_1"); err != nil {
+		t.Fatal(err)
+	}
+	booking := &models.Booking{ID: 9, HallID: "garden", CustomerName: "Jo", CustomerEmail: "jo@example.com", StartTime: "10:00", EndTime: "12:00"}
+	if err := mailer.SendBookingConfirmation(booking, "https://venue.example/?manage=eyJhbGciOi.eyJib29r.c2ln"); err != nil {
+		t.Fatal(err)
+	}
+
+	oc := NewOutboxController(db, outbox)
+	router := gin.New()
+	router.GET("/admin/outbox/:id", oc.GetOutboxMessage)
+	for _, id := range []string{"1", "2"} {
+		w := serve(router, http.MethodGet, "/admin/outbox/"+id, nil)
+		if w.Code != http.StatusOK {
+			t.Fatalf("GET %s: got %d %s", id, w.Code, w.Body.String())
+		}
+		body := w.Body.String()
+		for _, secret := range []string{"s3cret", "eyJhbGciOi"} {
+			if strings.Contains(body, secret) {
+				t.Errorf("message %s shows the link token %q:\n%s", id, secret, body)
+			}
+		}
+		var got struct {
+			Content services.Message `json:"content"`
+		}
+		if err := json.Unmarshal(w.Body.Bytes(), &got); err != nil {
+			t.Fatal(err)
+		}
+		if !strings.Contains(got.Content.Text, "=REDACTED") || !strings.Contains(got.Content.HTML, "=REDACTED") {
+			t.Errorf("message %s has no redacted link:\n%s", id, got.Content.Text)
+		}
+	}
+
+	// The stored message is untouched, so a resend still works
+	var row models.OutboxMessage
+	db.First(&row, 1)
+	if !strings.Contains(row.Payload, "s3cret-Token_1") {
+		t.Error("stored payload lost the link")
+	}
+}
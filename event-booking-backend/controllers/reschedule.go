@@ -0,0 +1,368 @@
+package controllers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"event-booking-backend/models"
+	"event-booking-backend/services"
+)
+
+var (
+	errBookingNotFound  = errors.New("booking not found")
+	errHallNotFound     = errors.New("hall not found")
+	errHallUnavailable  = errors.New("this hall is not taking bookings")
+	errSlotTaken        = errors.New("this time slot is already booked")
+	errOverCapacity     = errors.New("guest count exceeds the hall's capacity")
+	errNoSuchLayout     = errors.New("the hall does not offer this seating layout")
+	errNotReschedulable = errors.New("cancelled bookings cannot be rescheduled")
+)
+
+// policyError carries the reason a customer action was refused by the
+// booking policy.
+type policyError string
+
+func (e policyError) Error() string { return string(e) }
+
+type rescheduleRequest struct {
+	HallID    string    `json:"hallId"`
+	EventDate time.Time `json:"eventDate" binding:"required"`
+	StartTime string    `json:"startTime" binding:"required"`
+	Note      string    `json:"note"`
+}
+
+// rescheduleOptions says who is moving the booking and on what terms.
+type rescheduleOptions struct {
+	Actor string
+	Fee   float64
+	// Policy, when set, is enforced before the booking is moved. Admins
+	// reschedule without it.
+	Policy *services.BookingPolicy
+	// Audit, when set, is the admin request the move is audited under.
+	Audit *gin.Context
+	// Notify, when set, queues the customer's reschedule confirmation with
+	// the move. It carries a manage link issued by Links, since the old
+	// one expires with the old date.
+	Notify *services.Mailer
+	Links  *services.ManageLinkService
+}
+
+// rescheduleResult is returned to the caller after a successful move. The
+// price change is the new price less the old one, and the cost change adds
+// the fee. The balance due is measured against what the customer has paid:
+// the new price and all fees less the amount paid, negative when the
+// customer is owed money back.
+type rescheduleResult struct {
+	Booking     models.Booking        `json:"booking"`
+	History     models.BookingHistory `json:"history"`
+	PriceChange float64               `json:"priceChange"`
+	Fee         float64               `json:"fee"`
+	CostChange  float64               `json:"costChange"`
+	AmountPaid  float64               `json:"amountPaid"`
+	BalanceDue  float64               `json:"balanceDue"`
+	// ManageToken is a manage token for the new date, for customer moves.
+	ManageToken string `json:"manageToken,omitempty"`
+}
+
+// RescheduleBooking moves a booking to another slot (admin only). The
+// reschedule fee is waived unless chargeFee is set.
+func (c *BookingController) RescheduleBooking(ctx *gin.Context) {
+	bookingID, err := strconv.ParseUint(ctx.Param("id"), 10, 64)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid booking ID"})
+		return
+	}
+
+	var request struct {
+		rescheduleRequest
+		ChargeFee bool `json:"chargeFee"`
+	}
+	if err := ctx.ShouldBindJSON(&request); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	opts := rescheduleOptions{Actor: "admin", Audit: ctx, Notify: c.email, Links: c.links}
+	if request.ChargeFee {
+		opts.Fee = c.policy.RescheduleFee
+	}
+
+	result, err := rescheduleBooking(c.db, bookingID, request.rescheduleRequest, opts)
+	if err != nil {
+		writeRescheduleError(ctx, err)
+		return
+	}
+	ctx.JSON(http.StatusOK, result)
+}
+
+// GetBookingHistory returns the change log of a booking (admin only).
+func (c *BookingController) GetBookingHistory(ctx *gin.Context) {
+	bookingID, err := strconv.ParseUint(ctx.Param("id"), 10, 64)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid booking ID"})
+		return
+	}
+
+	var history []models.BookingHistory
+	if err := c.db.Where("booking_id = ?", bookingID).Order("created_at").Find(&history).Error; err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch booking history"})
+		return
+	}
+	ctx.JSON(http.StatusOK, history)
+}
+
+// RescheduleBooking lets a customer move their booking within the policy.
+func (c *ManageController) RescheduleBooking(ctx *gin.Context) {
+	booking, ok := c.loadBooking(ctx)
+	if !ok {
+		return
+	}
+
+	var request rescheduleRequest
+	if err := ctx.ShouldBindJSON(&request); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	// Customers move the date and time; changing hall goes through staff.
+	request.HallID = ""
+
+	result, err := rescheduleBooking(c.db, booking.ID, request, rescheduleOptions{
+		Actor:  "customer",
+		Fee:    c.policy.RescheduleFee,
+		Policy: &c.policy,
+		Notify: c.email,
+		Links:  c.links,
+	})
+	if err != nil {
+		writeRescheduleError(ctx, err)
+		return
+	}
+	ctx.JSON(http.StatusOK, result)
+}
+
+// rescheduleBooking moves the booking inside one transaction: the booking
+// and target hall rows are locked, so the availability check and the move
+// cannot interleave with another booking for the same hall.
+func rescheduleBooking(db *gorm.DB, bookingID uint64, request rescheduleRequest, opts rescheduleOptions) (*rescheduleResult, error) {
+	if request.EventDate.Before(time.Now()) {
+		return nil, policyError("Event date must be in the future")
+	}
+	if !validStartTime(request.StartTime) {
+		return nil, policyError("Start time must be in HH:MM format")
+	}
+
+	var result *rescheduleResult
+	err := db.Transaction(func(tx *gorm.DB) error {
+		var booking models.Booking
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&booking, bookingID).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return errBookingNotFound
+			}
+			return err
+		}
+		if booking.Status == models.StatusCancelled {
+			return errNotReschedulable
+		}
+		if opts.Policy != nil {
+			if reason := opts.Policy.Reschedule(booking.StartsAt(services.VenueLocation()), booking.RescheduleCount, time.Now()); reason != "" {
+				return policyError(reason)
+			}
+		}
+
+		hallID := request.HallID
+		if hallID == "" {
+			hallID = booking.HallID
+		}
+		hall, err := lockHall(tx, hallID)
+		if err != nil {
+			return err
+		}
+		moved := models.Booking{EventDate: request.EventDate, StartTime: request.StartTime}
+		if !hall.AcceptsBookings(moved.StartsAt(services.VenueLocation())) {
+			return errHallUnavailable
+		}
+		if err := ensureCapacity(tx, hall, booking.Layout, booking.GuestCount); err != nil {
+			return err
+		}
+		if err := ensureSlotFree(tx, hallID, request.EventDate, request.StartTime, booking.ID); err != nil {
+			return err
+		}
+
+		before := booking
+		newPrice := calculatePrice(hall, request.EventDate, request.StartTime)
+		fromDate, toDate := booking.EventDate, request.EventDate
+		history := models.BookingHistory{
+			BookingID:     booking.ID,
+			Action:        models.HistoryRescheduled,
+			Actor:         opts.Actor,
+			FromHallID:    booking.HallID,
+			FromDate:      &fromDate,
+			FromStartTime: booking.StartTime,
+			ToHallID:      hallID,
+			ToDate:        &toDate,
+			ToStartTime:   request.StartTime,
+			PreviousPrice: booking.TotalPrice,
+			NewPrice:      newPrice,
+			PriceChange:   newPrice - booking.TotalPrice,
+			Fee:           opts.Fee,
+			Note:          request.Note,
+		}
+
+		booking.HallID = hallID
+		booking.EventDate = request.EventDate
+		booking.StartTime = request.StartTime
+		booking.EndTime = calculateEndTime(request.StartTime)
+		booking.TotalPrice = newPrice
+		booking.FeeTotal += opts.Fee
+		// Only customer moves count towards the policy limit.
+		if opts.Policy != nil {
+			booking.RescheduleCount++
+		}
+		history.AmountPaid = booking.AmountPaid
+		history.BalanceDue = booking.BalanceDue()
+		if err := tx.Save(&booking).Error; err != nil {
+			return err
+		}
+		if err := tx.Create(&history).Error; err != nil {
+			return err
+		}
+		if opts.Audit != nil {
+			id := strconv.FormatUint(booking.ID, 10)
+			if err := recordAudit(tx, opts.Audit, "booking.rescheduled", "booking", id, before, booking); err != nil {
+				return err
+			}
+		}
+
+		// Any open request from the customer is settled by the move.
+		if err := tx.Model(&models.RescheduleRequest{}).
+			Where("booking_id = ? AND status = ?", booking.ID, models.RescheduleRequestOpen).
+			Update("status", models.RescheduleRequestResolved).Error; err != nil {
+			return err
+		}
+		var manageToken string
+		if opts.Links != nil {
+			token, err := opts.Links.Token(&booking)
+			if err != nil {
+				return err
+			}
+			manageToken = token
+		}
+		if opts.Notify != nil {
+			var manageURL string
+			if manageToken != "" {
+				manageURL = opts.Links.URL(manageToken)
+			}
+			if err := opts.Notify.In(tx).SendRescheduleConfirmation(&booking, manageURL); err != nil {
+				return err
+			}
+		}
+
+		result = &rescheduleResult{
+			Booking:     booking,
+			History:     history,
+			PriceChange: history.PriceChange,
+			Fee:         opts.Fee,
+			CostChange:  history.PriceChange + opts.Fee,
+			AmountPaid:  history.AmountPaid,
+			BalanceDue:  history.BalanceDue,
+		}
+		if opts.Actor == "customer" {
+			result.ManageToken = manageToken
+		}
+		return nil
+	})
+	return result, err
+}
+
+func writeRescheduleError(ctx *gin.Context, err error) {
+	var policyErr policyError
+	switch {
+	case errors.As(err, &policyErr):
+		ctx.JSON(http.StatusUnprocessableEntity, gin.H{"error": policyErr.Error()})
+	case errors.Is(err, errBookingNotFound):
+		ctx.JSON(http.StatusNotFound, gin.H{"error": "Booking not found"})
+	case errors.Is(err, errHallNotFound):
+		ctx.JSON(http.StatusNotFound, gin.H{"error": "Hall not found"})
+	case errors.Is(err, errSlotTaken), errors.Is(err, errNotReschedulable), errors.Is(err, errHallUnavailable):
+		ctx.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	case errors.Is(err, errOverCapacity), errors.Is(err, errNoSuchLayout):
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	default:
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to reschedule booking"})
+	}
+}
+
+// lockHall loads the hall and holds a row lock on it for the rest of the
+// transaction. Every path that claims a slot takes this lock first.
+func lockHall(tx *gorm.DB, hallID string) (*models.Hall, error) {
+	var hall models.Hall
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&hall, "id = ?", hallID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errHallNotFound
+		}
+		return nil, err
+	}
+	return &hall, nil
+}
+
+// ensureCapacity fails with errOverCapacity when guests do not fit the
+// hall set up in layout, or in its plain configuration when layout is
+// empty, and with errNoSuchLayout when the hall does not offer layout.
+func ensureCapacity(tx *gorm.DB, hall *models.Hall, layout models.SeatingLayout, guests int) error {
+	capacity := hall.Capacity
+	if layout != "" {
+		var hallLayout models.HallLayout
+		if err := tx.First(&hallLayout, "hall_id = ? AND layout = ?", hall.ID, layout).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return errNoSuchLayout
+			}
+			return err
+		}
+		capacity = hallLayout.Capacity
+	}
+	if guests > capacity {
+		return errOverCapacity
+	}
+	return nil
+}
+
+// ensureSlotFree fails with errSlotTaken when another live booking in the
+// hall overlaps the slot starting at startTime. excludeID skips the booking
+// being moved.
+func ensureSlotFree(tx *gorm.DB, hallID string, date time.Time, startTime string, excludeID uint64) error {
+	var count int64
+	query := tx.Model(&models.Booking{}).
+		Where("hall_id = ? AND DATE(event_date) = DATE(?) AND status != ? AND id != ?",
+			hallID, date, models.StatusCancelled, excludeID)
+	err := whereOverlaps(query, startTime).Count(&count).Error
+	if err != nil {
+		return err
+	}
+	if count > 0 {
+		return errSlotTaken
+	}
+	return nil
+}
+
+// slotEnd is when a slot starting at startTime ends. Times are HH:MM, so
+// they compare as strings; a slot running past midnight ends at "24:00",
+// as in busyHalls.
+func slotEnd(startTime string) string {
+	end := calculateEndTime(startTime)
+	if end <= startTime {
+		return "24:00"
+	}
+	return end
+}
+
+// whereOverlaps limits query to bookings overlapping the slot starting at
+// startTime.
+func whereOverlaps(query *gorm.DB, startTime string) *gorm.DB {
+	return query.Where("start_time < ? AND (end_time > ? OR end_time <= start_time)", slotEnd(startTime), startTime)
+}
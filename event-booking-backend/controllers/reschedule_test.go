@@ -0,0 +1,121 @@
+package controllers
+
+import (
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"event-booking-backend/models"
+	"event-booking-backend/services"
+)
+
+func TestRescheduleIssuesManageLinkForNewDate(t *testing.T) {
+	f := newManageFixture(t)
+	newDate := f.booking.EventDate.AddDate(0, 3, 0)
+
+	result := f.reschedule(t, f.booking, newDate, "10:00")
+
+	sent := f.notifier.Sent()
+	if len(sent) != 1 || sent[0].Template != services.TemplateBookingRescheduled {
+		t.Fatalf("sent = %+v, want one reschedule confirmation", sent)
+	}
+	start := strings.Index(sent[0].Text, "?manage=")
+	if start < 0 {
+		t.Fatalf("confirmation carries no manage link:\n%s", sent[0].Text)
+	}
+	token, err := url.QueryUnescape(strings.Fields(sent[0].Text[start+len("?manage="):])[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, token := range []string{token, result.ManageToken} {
+		claims, err := f.links.Verify(token)
+		if err != nil {
+			t.Fatalf("new manage token: %v", err)
+		}
+		if !claims.ExpiresAt.Time.After(newDate) {
+			t.Errorf("new manage token expires %s, before the new date %s", claims.ExpiresAt.Time, newDate)
+		}
+	}
+}
+
+func TestRescheduleRejectsOverlappingSlot(t *testing.T) {
+	f := newManageFixture(t)
+	date := f.booking.EventDate
+	other := f.addBooking(t, date.AddDate(0, 0, 1), "14:00")
+	late := f.addBooking(t, date.AddDate(0, 0, 2), "22:30")
+
+	tests := []struct {
+		name  string
+		date  time.Time
+		start string
+		want  int
+	}{
+		{"same start", other.EventDate, "14:00", http.StatusConflict},
+		{"starts during", other.EventDate, "15:00", http.StatusConflict},
+		{"runs into", other.EventDate, "12:30", http.StatusConflict},
+		{"during one past midnight", late.EventDate, "23:00", http.StatusConflict},
+		{"ends as it starts", other.EventDate, "12:00", http.StatusOK},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			token, err := f.links.Token(&f.booking)
+			if err != nil {
+				t.Fatal(err)
+			}
+			w := serve(f.router, http.MethodPost, "/manage/"+token+"/reschedule", gin.H{"eventDate": tt.date, "startTime": tt.start})
+			if w.Code != tt.want {
+				t.Errorf("reschedule to %s: got %d %s, want %d", tt.start, w.Code, w.Body.String(), tt.want)
+			}
+		})
+	}
+}
+
+func TestRescheduleBalanceAgainstAmountPaid(t *testing.T) {
+	f := newManageFixture(t)
+	f.db.Model(&f.booking).Update("amount_paid", 1000)
+	var hall models.Hall
+	f.db.First(&hall, "id = ?", "garden")
+	newDate := f.booking.EventDate.AddDate(0, 0, 1)
+
+	result := f.reschedule(t, f.booking, newDate, "19:00")
+
+	newPrice := calculatePrice(&hall, newDate, "19:00")
+	if result.AmountPaid != 1000 || result.BalanceDue != newPrice+result.Fee-1000 {
+		t.Errorf("paid %.2f, balance %.2f; want 1000.00, %.2f", result.AmountPaid, result.BalanceDue, newPrice+result.Fee-1000)
+	}
+	if result.History.BalanceDue != result.BalanceDue {
+		t.Errorf("history balance %.2f, want %.2f", result.History.BalanceDue, result.BalanceDue)
+	}
+}
+
+func TestRecordPayment(t *testing.T) {
+	f := newManageFixture(t)
+	bc := NewBookingController(f.db, nil, nil, services.LoadBookingPolicy(), nil)
+	router := gin.New()
+	router.POST("/admin/bookings/:id/payments", bc.RecordPayment)
+	path := "/admin/bookings/" + strconv.FormatUint(f.booking.ID, 10) + "/payments"
+
+	for _, amount := range []float64{600, 500, -100} {
+		if w := serve(router, http.MethodPost, path, gin.H{"amount": amount}); w.Code != http.StatusOK {
+			t.Fatalf("payment of %.2f: got %d %s", amount, w.Code, w.Body.String())
+		}
+	}
+	if w := serve(router, http.MethodPost, path, gin.H{"amount": -2000}); w.Code != http.StatusBadRequest {
+		t.Errorf("correction below zero: got %d %s, want 400", w.Code, w.Body.String())
+	}
+
+	var booking models.Booking
+	f.db.First(&booking, f.booking.ID)
+	var entries, audits int64
+	f.db.Model(&models.BookingHistory{}).Where("booking_id = ? AND action = ?", booking.ID, models.HistoryPaid).Count(&entries)
+	f.db.Model(&models.AuditLog{}).Where("action = ?", "booking.payment_recorded").Count(&audits)
+	if booking.AmountPaid != 1000 || booking.BalanceDue() != 0 || entries != 3 || audits != 3 {
+		t.Errorf("paid %.2f, balance %.2f, %d history entries, %d audits; want 1000.00, 0.00, 3, 3",
+			booking.AmountPaid, booking.BalanceDue(), entries, audits)
+	}
+}
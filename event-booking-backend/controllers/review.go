@@ -0,0 +1,254 @@
+package controllers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"event-booking-backend/middlewares"
+	"event-booking-backend/models"
+)
+
+var (
+	errReviewNotFound = errors.New("review not found")
+	errReviewDecided  = errors.New("review already decided")
+)
+
+// ReviewController works through the queue of public submissions the spam
+// checks held back.
+type ReviewController struct {
+	db       *gorm.DB
+	bookings *BookingController
+	contacts *ContactController
+}
+
+func NewReviewController(db *gorm.DB, bookings *BookingController, contacts *ContactController) *ReviewController {
+	return &ReviewController{
+		db:       db,
+		bookings: bookings,
+		contacts: contacts,
+	}
+}
+
+// reviewItem is a review with the booking or contact message it holds.
+type reviewItem struct {
+	models.SubmissionReview
+	Booking *models.Booking `json:"booking,omitempty"`
+	Contact *models.Contact `json:"contact,omitempty"`
+}
+
+// ListReviews returns held submissions, newest first. Filters: status
+// (default pending, or "all") and kind (booking or contact).
+func (c *ReviewController) ListReviews(ctx *gin.Context) {
+	limit, err := strconv.Atoi(ctx.DefaultQuery("limit", "100"))
+	if err != nil || limit < 1 || limit > 500 {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "limit must be between 1 and 500"})
+		return
+	}
+
+	query := c.db.Order("created_at DESC, id DESC").Limit(limit)
+	if status := ctx.DefaultQuery("status", string(models.ReviewPending)); status != "all" {
+		query = query.Where("status = ?", status)
+	}
+	if kind := ctx.Query("kind"); kind != "" {
+		query = query.Where("kind = ?", kind)
+	}
+	var reviews []models.SubmissionReview
+	if err := query.Find(&reviews).Error; err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch reviews"})
+		return
+	}
+
+	var bookingIDs, contactIDs []string
+	for _, review := range reviews {
+		if review.Kind == models.ReviewBooking {
+			bookingIDs = append(bookingIDs, review.EntityID)
+		} else {
+			contactIDs = append(contactIDs, review.EntityID)
+		}
+	}
+	var bookings []models.Booking
+	var contacts []models.Contact
+	if len(bookingIDs) > 0 {
+		if err := c.db.Where("id IN ?", bookingIDs).Find(&bookings).Error; err != nil {
+			ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch reviews"})
+			return
+		}
+	}
+	if len(contactIDs) > 0 {
+		if err := c.db.Where("id IN ?", contactIDs).Find(&contacts).Error; err != nil {
+			ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch reviews"})
+			return
+		}
+	}
+	bookingsByID := map[string]*models.Booking{}
+	for i := range bookings {
+		bookingsByID[strconv.FormatUint(bookings[i].ID, 10)] = &bookings[i]
+	}
+	contactsByID := map[string]*models.Contact{}
+	for i := range contacts {
+		contactsByID[strconv.FormatUint(uint64(contacts[i].ID), 10)] = &contacts[i]
+	}
+
+	items := make([]reviewItem, len(reviews))
+	for i, review := range reviews {
+		items[i] = reviewItem{SubmissionReview: review}
+		if review.Kind == models.ReviewBooking {
+			items[i].Booking = bookingsByID[review.EntityID]
+		} else {
+			items[i].Contact = contactsByID[review.EntityID]
+		}
+	}
+	ctx.JSON(http.StatusOK, items)
+}
+
+// ApproveReview lets a held submission through and queues the customer
+// the email it was held back from.
+func (c *ReviewController) ApproveReview(ctx *gin.Context) {
+	review, err := c.decide(ctx, models.ReviewApproved, func(tx *gorm.DB, review *models.SubmissionReview) error {
+		if review.Kind == models.ReviewBooking {
+			var booking models.Booking
+			err := tx.First(&booking, "id = ?", review.EntityID).Error
+			if errors.Is(err, gorm.ErrRecordNotFound) || (err == nil && booking.Status == models.StatusCancelled) {
+				return nil
+			}
+			if err != nil {
+				return err
+			}
+			_, manageURL := c.bookings.manageLink(&booking)
+			return c.bookings.email.In(tx).SendBookingConfirmation(&booking, manageURL)
+		}
+		var contact models.Contact
+		if err := tx.First(&contact, "id = ?", review.EntityID).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return nil
+			}
+			return err
+		}
+		return c.contacts.Email.In(tx).SendContactAcknowledgment(&contact)
+	})
+	if err != nil {
+		writeReviewError(ctx, err)
+		return
+	}
+	ctx.JSON(http.StatusOK, review)
+}
+
+// RejectReview marks a held submission as spam: a booking is cancelled,
+// freeing its slot, and a contact message is closed with the spam tag.
+// The customer is not emailed.
+func (c *ReviewController) RejectReview(ctx *gin.Context) {
+	review, err := c.decide(ctx, models.ReviewRejected, func(tx *gorm.DB, review *models.SubmissionReview) error {
+		if review.Kind == models.ReviewBooking {
+			return rejectHeldBooking(tx, ctx, review.EntityID)
+		}
+		return rejectHeldContact(tx, ctx, review.EntityID)
+	})
+	if err != nil {
+		writeReviewError(ctx, err)
+		return
+	}
+	ctx.JSON(http.StatusOK, review)
+}
+
+// decide records the decision on the review in the path, running apply in
+// the same transaction.
+func (c *ReviewController) decide(ctx *gin.Context, status models.ReviewStatus, apply func(tx *gorm.DB, review *models.SubmissionReview) error) (*models.SubmissionReview, error) {
+	var review models.SubmissionReview
+	err := c.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&review, "id = ?", ctx.Param("id")).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return errReviewNotFound
+			}
+			return err
+		}
+		if review.Status != models.ReviewPending {
+			return errReviewDecided
+		}
+		before := review
+		if apply != nil {
+			if err := apply(tx, &review); err != nil {
+				return err
+			}
+		}
+
+		now := time.Now()
+		review.Status = status
+		review.ReviewedAt = &now
+		if admin := middlewares.CurrentAdmin(ctx); admin != nil {
+			review.ReviewedBy = &admin.UserID
+		}
+		if err := tx.Save(&review).Error; err != nil {
+			return err
+		}
+		return recordAudit(tx, ctx, "review."+string(status), "submission_review", strconv.FormatUint(review.ID, 10), before, review)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &review, nil
+}
+
+func rejectHeldBooking(tx *gorm.DB, ctx *gin.Context, id string) error {
+	var booking models.Booking
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&booking, "id = ?", id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil
+		}
+		return err
+	}
+	if booking.Status == models.StatusCancelled {
+		return nil
+	}
+	before := booking
+	now := time.Now()
+	booking.Status = models.StatusCancelled
+	booking.CancelledAt = &now
+	if err := tx.Save(&booking).Error; err != nil {
+		return err
+	}
+	if err := tx.Create(&models.BookingHistory{
+		BookingID:     booking.ID,
+		Action:        models.HistoryCancelled,
+		Actor:         "admin",
+		PreviousPrice: booking.TotalPrice,
+		NewPrice:      booking.TotalPrice,
+		Note:          "Rejected as spam",
+	}).Error; err != nil {
+		return err
+	}
+	return recordAudit(tx, ctx, "booking.status_updated", "booking", id, before, booking)
+}
+
+func rejectHeldContact(tx *gorm.DB, ctx *gin.Context, id string) error {
+	var contact models.Contact
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&contact, "id = ?", id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil
+		}
+		return err
+	}
+	before := contact
+	setContactStatus(&contact, models.ContactResolved, time.Now())
+	contact.Tags = uniqueStrings(append(contact.Tags, "spam"))
+	if err := tx.Omit(clause.Associations).Save(&contact).Error; err != nil {
+		return err
+	}
+	return recordAudit(tx, ctx, "contact.status_updated", "contact", id, before, contact)
+}
+
+func writeReviewError(ctx *gin.Context, err error) {
+	switch {
+	case errors.Is(err, errReviewNotFound):
+		ctx.JSON(http.StatusNotFound, gin.H{"error": "Review not found"})
+	case errors.Is(err, errReviewDecided):
+		ctx.JSON(http.StatusConflict, gin.H{"error": "This submission has already been reviewed"})
+	default:
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update review"})
+	}
+}
@@ -0,0 +1,146 @@
+package controllers
+
+import (
+	"net/http"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+
+	"event-booking-backend/middlewares"
+	"event-booking-backend/models"
+	"event-booking-backend/services"
+)
+
+type reviewFixture struct {
+	db       *gorm.DB
+	notifier *services.LogNotifier
+	router   *gin.Engine
+}
+
+func newReviewFixture(t *testing.T) *reviewFixture {
+	t.Helper()
+	t.Setenv("MANAGE_LINK_SECRET", "test-manage-secret")
+	db := newTestDB(t)
+	links, err := services.NewManageLinkService()
+	if err != nil {
+		t.Fatal(err)
+	}
+	notifier, _ := services.NewLogNotifier("")
+	mailer := services.NewMailer(notifier, nil)
+	guard := services.NewSpamGuard(db, services.LoadSpamPolicy(), nil)
+	rc := NewReviewController(db,
+		NewBookingController(db, mailer, links, services.LoadBookingPolicy(), guard),
+		NewContactController(db, services.LoadTicketSLA(), mailer, guard))
+
+	router := gin.New()
+	router.Use(func(ctx *gin.Context) {
+		ctx.Set("admin", &middlewares.AdminIdentity{UserID: 7, Email: "ops@example.com", Role: "manager"})
+	})
+	router.POST("/admin/reviews/:id/approve", rc.ApproveReview)
+	router.POST("/admin/reviews/:id/reject", rc.RejectReview)
+	return &reviewFixture{db: db, notifier: notifier, router: router}
+}
+
+// hold stores a review of the entity, pending.
+func (f *reviewFixture) hold(t *testing.T, kind models.ReviewKind, entityID uint64) string {
+	t.Helper()
+	review := models.SubmissionReview{Kind: kind, EntityID: strconv.FormatUint(entityID, 10), Email: "asha@example.com",
+		Reasons: models.StringList{services.ReasonLinks}, Status: models.ReviewPending}
+	if err := f.db.Create(&review).Error; err != nil {
+		t.Fatal(err)
+	}
+	return strconv.FormatUint(review.ID, 10)
+}
+
+func (f *reviewFixture) heldBooking(t *testing.T) (models.Booking, string) {
+	t.Helper()
+	f.db.Create(&models.Hall{ID: "garden", Name: "Garden", Capacity: 100, BasePrice: 1000, Status: models.HallActive})
+	booking := models.Booking{HallID: "garden", CustomerName: "Asha", CustomerEmail: "asha@example.com", CustomerPhone: "555",
+		GuestCount: 50, EventDate: time.Now().AddDate(0, 1, 0), StartTime: "10:00", EndTime: "14:00",
+		Status: models.StatusPending, TotalPrice: 1000, Tags: models.StringList{}}
+	if err := f.db.Create(&booking).Error; err != nil {
+		t.Fatal(err)
+	}
+	return booking, f.hold(t, models.ReviewBooking, booking.ID)
+}
+
+func (f *reviewFixture) heldContact(t *testing.T) (models.Contact, string) {
+	t.Helper()
+	contact := models.Contact{Name: "Asha", Email: "asha@example.com", Subject: "Parking", Message: "See http://a http://b http://c",
+		Status: models.ContactOpen, Tags: models.StringList{}}
+	if err := f.db.Create(&contact).Error; err != nil {
+		t.Fatal(err)
+	}
+	return contact, f.hold(t, models.ReviewContact, uint64(contact.ID))
+}
+
+func TestApproveReview(t *testing.T) {
+	f := newReviewFixture(t)
+	_, bookingReview := f.heldBooking(t)
+	_, contactReview := f.heldContact(t)
+
+	for _, id := range []string{bookingReview, contactReview} {
+		if w := serve(f.router, http.MethodPost, "/admin/reviews/"+id+"/approve", nil); w.Code != http.StatusOK {
+			t.Fatalf("approve %s: got %d %s, want 200", id, w.Code, w.Body.String())
+		}
+	}
+	sent := f.notifier.Sent()
+	if len(sent) != 2 || sent[0].Template != services.TemplateBookingConfirmation || sent[1].Template != services.TemplateContactAcknowledgment {
+		t.Errorf("sent = %+v, want the booking confirmation and the contact acknowledgment", sent)
+	}
+
+	var review models.SubmissionReview
+	f.db.First(&review, bookingReview)
+	if review.Status != models.ReviewApproved || review.ReviewedBy == nil || *review.ReviewedBy != 7 || review.ReviewedAt == nil {
+		t.Errorf("review = %+v, want approved by user 7", review)
+	}
+	var audits int64
+	f.db.Model(&models.AuditLog{}).Where("action = ?", "review.approved").Count(&audits)
+	if audits != 2 {
+		t.Errorf("%d audit entries, want 2", audits)
+	}
+
+	// A decision is final
+	for _, action := range []string{"approve", "reject"} {
+		if w := serve(f.router, http.MethodPost, "/admin/reviews/"+bookingReview+"/"+action, nil); w.Code != http.StatusConflict {
+			t.Errorf("%s again: got %d %s, want 409", action, w.Code, w.Body.String())
+		}
+	}
+	if w := serve(f.router, http.MethodPost, "/admin/reviews/999/approve", nil); w.Code != http.StatusNotFound {
+		t.Errorf("unknown review: got %d %s, want 404", w.Code, w.Body.String())
+	}
+}
+
+func TestRejectReview(t *testing.T) {
+	f := newReviewFixture(t)
+	booking, bookingReview := f.heldBooking(t)
+	contact, contactReview := f.heldContact(t)
+
+	for _, id := range []string{bookingReview, contactReview} {
+		if w := serve(f.router, http.MethodPost, "/admin/reviews/"+id+"/reject", nil); w.Code != http.StatusOK {
+			t.Fatalf("reject %s: got %d %s, want 200", id, w.Code, w.Body.String())
+		}
+	}
+	if sent := f.notifier.Sent(); len(sent) != 0 {
+		t.Errorf("sent %d messages, want none", len(sent))
+	}
+
+	f.db.First(&booking, booking.ID)
+	var history int64
+	f.db.Model(&models.BookingHistory{}).Where("booking_id = ? AND action = ?", booking.ID, models.HistoryCancelled).Count(&history)
+	if booking.Status != models.StatusCancelled || booking.CancelledAt == nil || history != 1 {
+		t.Errorf("booking %s with %d cancellations in history, want cancelled once", booking.Status, history)
+	}
+	f.db.First(&contact, contact.ID)
+	if contact.Status != models.ContactResolved || len(contact.Tags) != 1 || contact.Tags[0] != "spam" {
+		t.Errorf("contact %s tagged %v, want resolved as spam", contact.Status, contact.Tags)
+	}
+	var review models.SubmissionReview
+	f.db.First(&review, contactReview)
+	if review.Status != models.ReviewRejected {
+		t.Errorf("review %s, want rejected", review.Status)
+	}
+}
@@ -0,0 +1,83 @@
+package controllers
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+
+	"event-booking-backend/middlewares"
+	"event-booking-backend/models"
+	"event-booking-backend/services"
+)
+
+// SecurityController lets owners review and lift sign-in lockouts.
+type SecurityController struct {
+	db       *gorm.DB
+	throttle *services.LoginThrottle
+}
+
+func NewSecurityController(db *gorm.DB, throttle *services.LoginThrottle) *SecurityController {
+	return &SecurityController{
+		db:       db,
+		throttle: throttle,
+	}
+}
+
+// ListLockouts returns the accounts and addresses that are currently blocked
+func (c *SecurityController) ListLockouts(ctx *gin.Context) {
+	var rows []models.LoginThrottle
+	err := c.db.Where("locked_until > ?", time.Now()).Order("locked_until DESC").Find(&rows).Error
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch lockouts"})
+		return
+	}
+	ctx.JSON(http.StatusOK, rows)
+}
+
+// Unlock clears the failures recorded for a key, e.g. "customer:jo@example.com"
+func (c *SecurityController) Unlock(ctx *gin.Context) {
+	var request struct {
+		Key string `json:"key" binding:"required"`
+	}
+	if err := ctx.ShouldBindJSON(&request); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	admin := middlewares.CurrentAdmin(ctx)
+	err := c.db.Transaction(func(tx *gorm.DB) error {
+		if err := c.throttle.Unlock(tx, request.Key, admin.UserID, ctx.ClientIP()); err != nil {
+			return err
+		}
+		return recordAudit(tx, ctx, "login_throttle.unlocked", "login_throttle", request.Key, nil, nil)
+	})
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to unlock"})
+		return
+	}
+	ctx.JSON(http.StatusOK, gin.H{"message": "Unlocked"})
+}
+
+// ListEvents returns recent lockout and unlock events, newest first
+func (c *SecurityController) ListEvents(ctx *gin.Context) {
+	limit, err := strconv.Atoi(ctx.DefaultQuery("limit", "100"))
+	if err != nil || limit < 1 || limit > 500 {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "limit must be between 1 and 500"})
+		return
+	}
+
+	query := c.db.Order("created_at DESC").Limit(limit)
+	if key := ctx.Query("key"); key != "" {
+		query = query.Where("key = ?", key)
+	}
+
+	var events []models.SecurityEvent
+	if err := query.Find(&events).Error; err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch security events"})
+		return
+	}
+	ctx.JSON(http.StatusOK, events)
+}
@@ -0,0 +1,91 @@
+package controllers
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+
+	"event-booking-backend/middlewares"
+	"event-booking-backend/models"
+	"event-booking-backend/services"
+)
+
+func newSecurityRouter(db *gorm.DB) *gin.Engine {
+	sc := NewSecurityController(db, services.NewLoginThrottle(db))
+	router := gin.New()
+	router.Use(func(ctx *gin.Context) {
+		ctx.Set("admin", &middlewares.AdminIdentity{UserID: 7, Email: "ops@example.com", Role: "owner"})
+	})
+	router.POST("/admin/security/unlock", sc.Unlock)
+	return router
+}
+
+func lockOut(t *testing.T, db *gorm.DB, key string) {
+	t.Helper()
+	until := time.Now().Add(time.Hour)
+	if err := db.Create(&models.LoginThrottle{Key: key, Failures: 10, LastFailureAt: time.Now(), LockedUntil: &until}).Error; err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestUnlock(t *testing.T) {
+	db := newTestDB(t)
+	router := newSecurityRouter(db)
+	lockOut(t, db, "customer:jo@example.com")
+
+	w := serve(router, http.MethodPost, "/admin/security/unlock", gin.H{"key": "customer:jo@example.com"})
+	if w.Code != http.StatusOK {
+		t.Fatalf("got %d %s, want 200", w.Code, w.Body.String())
+	}
+	var locks, events, audits int64
+	db.Model(&models.LoginThrottle{}).Count(&locks)
+	db.Model(&models.SecurityEvent{}).Where("type = ? AND actor_id = ?", models.SecurityEventUnlock, 7).Count(&events)
+	db.Model(&models.AuditLog{}).Where("action = ? AND actor_id = ?", "login_throttle.unlocked", 7).Count(&audits)
+	if locks != 0 || events != 1 || audits != 1 {
+		t.Errorf("%d lockouts, %d unlock events, %d audit entries; want 0, 1, 1", locks, events, audits)
+	}
+}
+
+func TestUnlockFailsWithoutAudit(t *testing.T) {
+	db := newTestDB(t)
+	router := newSecurityRouter(db)
+	lockOut(t, db, "customer:jo@example.com")
+	if err := db.Migrator().DropTable(&models.AuditLog{}); err != nil {
+		t.Fatal(err)
+	}
+
+	w := serve(router, http.MethodPost, "/admin/security/unlock", gin.H{"key": "customer:jo@example.com"})
+	if w.Code != http.StatusInternalServerError {
+		t.Fatalf("got %d %s, want 500", w.Code, w.Body.String())
+	}
+	var locks, events int64
+	db.Model(&models.LoginThrottle{}).Count(&locks)
+	db.Model(&models.SecurityEvent{}).Count(&events)
+	if locks != 1 || events != 0 {
+		t.Errorf("%d lockouts, %d unlock events after a failed audit; want the lockout kept", locks, events)
+	}
+}
+
+func TestLockoutRecordedOnEveryLock(t *testing.T) {
+	db := newTestDB(t)
+	throttle := services.NewLoginThrottle(db)
+	key := services.AccountKey("admin", "ops@example.com")
+	for i := 0; i < 10; i++ {
+		throttle.RecordFailure("203.0.113.9", key)
+	}
+	// The lockout runs out, but the failures still count within the window
+	db.Model(&models.LoginThrottle{}).Where("key = ?", key).Update("locked_until", time.Now().Add(-time.Minute))
+	throttle.RecordFailure("203.0.113.9", key)
+
+	var events int64
+	db.Model(&models.SecurityEvent{}).Where("type = ? AND key = ?", models.SecurityEventLockout, key).Count(&events)
+	if events != 2 {
+		t.Errorf("%d lockout events, want 2", events)
+	}
+	if throttle.Blocked(key) <= 0 {
+		t.Error("key not locked again")
+	}
+}
@@ -0,0 +1,58 @@
+package controllers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+
+	"event-booking-backend/models"
+	"event-booking-backend/services"
+)
+
+// SigningKeyController lets owners inspect and rotate the JWT signing keys.
+type SigningKeyController struct {
+	db   *gorm.DB
+	keys *services.KeyManager
+}
+
+func NewSigningKeyController(db *gorm.DB, keys *services.KeyManager) *SigningKeyController {
+	return &SigningKeyController{
+		db:   db,
+		keys: keys,
+	}
+}
+
+// ListKeys returns key metadata, newest first. Key material is never
+// returned.
+func (c *SigningKeyController) ListKeys(ctx *gin.Context) {
+	var keys []models.SigningKey
+	if err := c.db.Order("created_at DESC").Find(&keys).Error; err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch signing keys"})
+		return
+	}
+	ctx.JSON(http.StatusOK, keys)
+}
+
+// RotateKeys retires the current signing key now rather than when it is
+// due. Tokens it signed stay valid for the grace period, unless revoke=true
+// is given after a suspected leak: then the old keys expire at once and
+// everyone signed in has to sign in again.
+func (c *SigningKeyController) RotateKeys(ctx *gin.Context) {
+	audit := func(action string) func(tx *gorm.DB) error {
+		return func(tx *gorm.DB) error {
+			return recordAudit(tx, ctx, action, "signing_key", "", nil, nil)
+		}
+	}
+	var err error
+	if ctx.Query("revoke") == "true" {
+		err = c.keys.Revoke(audit("signing_key.revoked"))
+	} else {
+		err = c.keys.Rotate(true, audit("signing_key.rotated"))
+	}
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to rotate signing keys"})
+		return
+	}
+	c.ListKeys(ctx)
+}
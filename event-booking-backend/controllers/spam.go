@@ -0,0 +1,55 @@
+package controllers
+
+import (
+	"errors"
+	"log"
+	"math"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+
+	"event-booking-backend/models"
+	"event-booking-backend/services"
+)
+
+// screenSubmission runs the spam checks on a public form post. When the
+// post is refused it writes the response and returns false; otherwise it
+// returns the reasons to hold the submission for review, if any.
+func screenSubmission(ctx *gin.Context, guard *services.SpamGuard, submission services.Submission) ([]string, bool) {
+	submission.IP = ctx.ClientIP()
+	reasons, err := guard.Screen(submission)
+	var limited *services.RateLimitedError
+	switch {
+	case err == nil:
+		return reasons, true
+	case errors.As(err, &limited):
+		seconds := int(math.Ceil(limited.RetryAfter.Seconds()))
+		ctx.Header("Retry-After", strconv.Itoa(seconds))
+		ctx.JSON(http.StatusTooManyRequests, gin.H{
+			"error":      "Too many submissions, please try again later",
+			"retryAfter": seconds,
+		})
+	case errors.Is(err, services.ErrSpamRejected):
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Submission rejected"})
+	case errors.Is(err, services.ErrCaptchaFailed):
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "CAPTCHA verification failed"})
+	default:
+		log.Printf("Failed to screen %s from %s: %v", submission.Kind, submission.IP, err)
+		ctx.JSON(http.StatusServiceUnavailable, gin.H{"error": "Could not check the submission, please try again"})
+	}
+	return nil, false
+}
+
+// holdForReview puts a suspicious submission in the review queue.
+func holdForReview(tx *gorm.DB, ctx *gin.Context, kind models.ReviewKind, entityID, email string, reasons []string) error {
+	return tx.Create(&models.SubmissionReview{
+		Kind:     kind,
+		EntityID: entityID,
+		IP:       ctx.ClientIP(),
+		Email:    email,
+		Reasons:  reasons,
+		Status:   models.ReviewPending,
+	}).Error
+}
@@ -0,0 +1,139 @@
+package controllers
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"event-booking-backend/models"
+	"event-booking-backend/services"
+)
+
+func contactForm(email string) gin.H {
+	return gin.H{"name": "Meena", "email": email, "subject": "Parking", "message": "Is there parking for 40 cars?"}
+}
+
+func TestBookingHoneypot(t *testing.T) {
+	db := newTestDB(t)
+	db.Create(&models.Hall{ID: "garden", Name: "Garden", Capacity: 100, BasePrice: 1000, Status: models.HallActive})
+	guard := services.NewSpamGuard(db, services.LoadSpamPolicy(), nil)
+	bc := NewBookingController(db, nil, nil, services.LoadBookingPolicy(), guard)
+	router := gin.New()
+	router.POST("/bookings", bc.CreateBooking)
+
+	w := serve(router, http.MethodPost, "/bookings", gin.H{
+		"hallId": "garden", "customerName": "Bot", "customerEmail": "bot@example.com", "customerPhone": "555",
+		"guestCount": 10, "eventDate": time.Now().AddDate(0, 1, 0), "startTime": "10:00",
+		"website": "http://spam.example",
+	})
+	if w.Code != http.StatusBadRequest || !strings.Contains(w.Body.String(), "Submission rejected") {
+		t.Fatalf("got %d %s, want 400 rejected", w.Code, w.Body.String())
+	}
+	var count int64
+	db.Model(&models.Booking{}).Count(&count)
+	if count != 0 {
+		t.Errorf("%d bookings stored, want none", count)
+	}
+}
+
+func TestSpamRateLimitWindow(t *testing.T) {
+	t.Setenv("SPAM_CONTACTS_PER_EMAIL", "2")
+	db := newTestDB(t)
+	router, _ := newContactRouter(t, db)
+
+	for i := 0; i < 2; i++ {
+		if w := serve(router, http.MethodPost, "/contacts", contactForm("meena@example.com")); w.Code != http.StatusCreated {
+			t.Fatalf("message %d: got %d %s, want 201", i+1, w.Code, w.Body.String())
+		}
+	}
+	w := serve(router, http.MethodPost, "/contacts", contactForm("Meena@Example.com"))
+	if w.Code != http.StatusTooManyRequests {
+		t.Fatalf("third message: got %d %s, want 429", w.Code, w.Body.String())
+	}
+	if retry, _ := strconv.Atoi(w.Header().Get("Retry-After")); retry <= 0 || retry > 3600 {
+		t.Errorf("Retry-After = %q, want up to an hour", w.Header().Get("Retry-After"))
+	}
+
+	// Once the window has run out the count starts over
+	db.Model(&models.RateLimitCounter{}).Where("1 = 1").Update("window_start", time.Now().Add(-61*time.Minute))
+	if w := serve(router, http.MethodPost, "/contacts", contactForm("meena@example.com")); w.Code != http.StatusCreated {
+		t.Fatalf("after the window: got %d %s, want 201", w.Code, w.Body.String())
+	}
+	var counter models.RateLimitCounter
+	db.First(&counter, "key = ?", "contact:email:meena@example.com")
+	if counter.Count != 1 || time.Since(counter.WindowStart) > time.Minute {
+		t.Errorf("counter = %+v, want a fresh window with one message", counter)
+	}
+}
+
+func TestDisposableEmail(t *testing.T) {
+	t.Setenv("DISPOSABLE_EMAIL_DOMAINS", " Throwaway.Test ,")
+	guard := services.NewSpamGuard(nil, services.LoadSpamPolicy(), nil)
+	tests := []struct {
+		email string
+		want  bool
+	}{
+		{"a@mailinator.com", true},
+		{"a@eu.mailinator.com", true},
+		{"a@MAIL.Yopmail.com", true},
+		{"a@throwaway.test", true},
+		{"a@notmailinator.com", false},
+		{"a@mailinator.com.example.org", false},
+		{"a@example.com", false},
+		{"not-an-email", false},
+	}
+	for _, tt := range tests {
+		if got := guard.IsDisposableEmail(tt.email); got != tt.want {
+			t.Errorf("IsDisposableEmail(%q) = %v, want %v", tt.email, got, tt.want)
+		}
+	}
+}
+
+func TestDisposableEmailHeldForReview(t *testing.T) {
+	db := newTestDB(t)
+	router, notifier := newContactRouter(t, db)
+
+	if w := serve(router, http.MethodPost, "/contacts", contactForm("meena@box.mailinator.com")); w.Code != http.StatusCreated {
+		t.Fatalf("got %d %s, want 201", w.Code, w.Body.String())
+	}
+	var review models.SubmissionReview
+	if err := db.First(&review).Error; err != nil {
+		t.Fatalf("no review held: %v", err)
+	}
+	if review.Kind != models.ReviewContact || len(review.Reasons) != 1 || review.Reasons[0] != services.ReasonDisposableEmail {
+		t.Errorf("review = %+v, want a contact held for a disposable email", review)
+	}
+	if sent := notifier.Sent(); len(sent) != 0 {
+		t.Errorf("sent %d messages before review, want none", len(sent))
+	}
+}
+
+func TestCaptchaVerifier(t *testing.T) {
+	t.Setenv("CAPTCHA_PROVIDER", "test")
+	t.Setenv("CAPTCHA_TEST_TOKEN", "")
+	captcha, err := services.NewCaptchaVerifier()
+	if err != nil {
+		t.Fatal(err)
+	}
+	db := newTestDB(t)
+	router, _ := newGuardedContactRouter(t, db, services.NewSpamGuard(db, services.LoadSpamPolicy(), captcha))
+
+	for _, tt := range []struct {
+		token string
+		want  int
+	}{
+		{"", http.StatusBadRequest},
+		{"test-fail", http.StatusBadRequest},
+		{"test-pass", http.StatusCreated},
+	} {
+		form := contactForm("meena@example.com")
+		form["captchaToken"] = tt.token
+		if w := serve(router, http.MethodPost, "/contacts", form); w.Code != tt.want {
+			t.Errorf("token %q: got %d %s, want %d", tt.token, w.Code, w.Body.String(), tt.want)
+		}
+	}
+}
@@ -0,0 +1,28 @@
+package controllers
+
+import (
+	"math"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+
+	"event-booking-backend/services"
+)
+
+// throttled responds with 429 and returns true when any of keys is blocked
+// by the login throttle.
+func throttled(ctx *gin.Context, throttle *services.LoginThrottle, keys ...string) bool {
+	wait := throttle.Blocked(keys...)
+	if wait <= 0 {
+		return false
+	}
+
+	seconds := int(math.Ceil(wait.Seconds()))
+	ctx.Header("Retry-After", strconv.Itoa(seconds))
+	ctx.JSON(http.StatusTooManyRequests, gin.H{
+		"error":      "Too many failed attempts, please try again later",
+		"retryAfter": seconds,
+	})
+	return true
+}
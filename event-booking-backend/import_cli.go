@@ -0,0 +1,79 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"gorm.io/gorm"
+
+	"event-booking-backend/controllers"
+	"event-booking-backend/services"
+)
+
+// runImportCommand implements the import subcommand:
+//
+//	event-booking-backend import [-dry-run] bookings.csv
+//	event-booking-backend import -rollback <batch id>
+//
+// Reports are printed as JSON.
+func runImportCommand(db *gorm.DB, args []string) error {
+	flags := flag.NewFlagSet("import", flag.ContinueOnError)
+	dryRun := flags.Bool("dry-run", false, "validate the file and report without importing")
+	rollback := flags.String("rollback", "", "roll back the import batch with this ID")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	importer := controllers.NewBookingImporter(db)
+	if *rollback != "" {
+		batch, deleted, err := importer.Rollback(*rollback, nil)
+		if err != nil {
+			return err
+		}
+		return printJSON(map[string]interface{}{"batch": batch, "deleted": deleted})
+	}
+
+	if flags.NArg() != 1 {
+		return errors.New("usage: import [-dry-run] <file.csv|file.xlsx> or import -rollback <batch id>")
+	}
+	path := flags.Arg(0)
+	format, err := services.TableFormat(path)
+	if err != nil {
+		return err
+	}
+	file, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	table, err := services.ReadTable(format, file)
+	if err != nil {
+		return err
+	}
+
+	report, err := importer.Import(table, controllers.ImportOptions{
+		DryRun:   *dryRun,
+		Filename: filepath.Base(path),
+		Source:   "cli",
+	})
+	if err != nil {
+		return err
+	}
+	if err := printJSON(report); err != nil {
+		return err
+	}
+	if report.BatchID != "" {
+		fmt.Fprintf(os.Stderr, "Imported %d bookings as batch %s\n", report.Imported, report.BatchID)
+	}
+	return nil
+}
+
+func printJSON(v interface{}) error {
+	encoder := json.NewEncoder(os.Stdout)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(v)
+}
@@ -1,15 +1,19 @@
 package main
 
 import (
+	"context"
 	"fmt"
 	"log"
 	"os"
+	"strings"
 
 	"github.com/gin-contrib/cors"
 	"github.com/gin-gonic/gin"
 	"github.com/joho/godotenv"
+	"golang.org/x/crypto/bcrypt"
 	"gorm.io/driver/postgres"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 
 	"event-booking-backend/controllers"
 	"event-booking-backend/middlewares"
@@ -17,6 +21,35 @@ import (
 	"event-booking-backend/services"
 )
 
+// defaultAmenities is the amenity catalogue a new installation starts with.
+var defaultAmenities = []models.Amenity{
+	{ID: "wifi", Name: "Wi-Fi", Category: "connectivity", Icon: "wifi"},
+	{ID: "sound_system", Name: "Sound system", Category: "audio_visual", Icon: "speaker"},
+	{ID: "projector", Name: "Projector and screen", Category: "audio_visual", Icon: "projector"},
+	{ID: "microphones", Name: "Wireless microphones", Category: "audio_visual", Icon: "mic"},
+	{ID: "stage", Name: "Stage", Category: "audio_visual", Icon: "stage"},
+	{ID: "dance_floor", Name: "Dance floor", Category: "space", Icon: "music"},
+	{ID: "air_conditioning", Name: "Air conditioning", Category: "comfort", Icon: "snowflake"},
+	{ID: "kitchen", Name: "Catering kitchen", Category: "catering", Icon: "utensils"},
+	{ID: "bar", Name: "Bar", Category: "catering", Icon: "glass"},
+	{ID: "decorations", Name: "Basic decorations", Category: "services", Icon: "sparkles"},
+	{ID: "wheelchair_access", Name: "Wheelchair access", Category: "accessibility", Icon: "wheelchair"},
+	{ID: "parking", Name: "Parking", Category: "accessibility", Icon: "car"},
+}
+
+func initializeAmenities(db *gorm.DB) error {
+	var count int64
+	db.Model(&models.Amenity{}).Count(&count)
+
+	if count == 0 {
+		if err := db.Create(&defaultAmenities).Error; err != nil {
+			return err
+		}
+		log.Println("Initialized amenities in database")
+	}
+	return nil
+}
+
 func initializeHalls(db *gorm.DB) error {
 	var count int64
 	db.Model(&models.Hall{}).Count(&count)
@@ -30,7 +63,12 @@ func initializeHalls(db *gorm.DB) error {
 				BasePrice:   1000,
 				WeekendRate: 200,
 				PeakRate:    300,
-				Features:    "Cozy atmosphere, Modern audio system, Comfortable seating, Basic decorations included",
+				Amenities:   []models.Amenity{{ID: "wifi"}, {ID: "sound_system"}, {ID: "air_conditioning"}, {ID: "decorations"}},
+				Layouts: []models.HallLayout{
+					{Layout: models.LayoutBanquet, Capacity: 8},
+					{Layout: models.LayoutCocktail, Capacity: 10},
+					{Layout: models.LayoutBoardroom, Capacity: 10},
+				},
 			},
 			{
 				ID:          "hall2",
@@ -39,12 +77,20 @@ func initializeHalls(db *gorm.DB) error {
 				BasePrice:   2000,
 				WeekendRate: 400,
 				PeakRate:    600,
-				Features:    "Spacious layout, Premium sound system, Projector setup, Custom decoration options",
+				Amenities: []models.Amenity{{ID: "wifi"}, {ID: "sound_system"}, {ID: "projector"}, {ID: "microphones"},
+					{ID: "dance_floor"}, {ID: "air_conditioning"}, {ID: "wheelchair_access"}},
+				Layouts: []models.HallLayout{
+					{Layout: models.LayoutTheatre, Capacity: 30},
+					{Layout: models.LayoutBanquet, Capacity: 24},
+					{Layout: models.LayoutCocktail, Capacity: 30},
+					{Layout: models.LayoutClassroom, Capacity: 18},
+				},
 			},
 		}
 
 		for _, hall := range halls {
-			if err := db.Create(&hall).Error; err != nil {
+			// The catalogue is already there; only link to it.
+			if err := db.Omit("Amenities.*").Create(&hall).Error; err != nil {
 				return err
 			}
 		}
@@ -53,6 +99,150 @@ func initializeHalls(db *gorm.DB) error {
 	return nil
 }
 
+// migrateHallFeatures moves the free-text features column halls used to
+// have into amenities: each comma-separated entry becomes an amenity in
+// the "general" category, linked to its hall. The column is dropped
+// afterwards, so this runs once.
+func migrateHallFeatures(db *gorm.DB) error {
+	if !db.Migrator().HasColumn(&models.Hall{}, "features") {
+		return nil
+	}
+	return db.Transaction(func(tx *gorm.DB) error {
+		var rows []struct {
+			ID       string
+			Features string
+		}
+		if err := tx.Table("halls").Select("id, features").
+			Where("features IS NOT NULL AND features <> ''").Scan(&rows).Error; err != nil {
+			return err
+		}
+		for _, row := range rows {
+			for _, name := range strings.Split(row.Features, ",") {
+				name = strings.TrimSpace(name)
+				id := models.Slugify(name)
+				if id == "" {
+					continue
+				}
+				amenity := models.Amenity{ID: id, Name: name, Category: "general"}
+				if err := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&amenity).Error; err != nil {
+					return err
+				}
+				if err := tx.Exec(`INSERT INTO hall_amenities (hall_id, amenity_id) VALUES (?, ?)
+	ON CONFLICT DO NOTHING`, row.ID, id).Error; err != nil {
+					return err
+				}
+			}
+		}
+		return tx.Migrator().DropColumn(&models.Hall{}, "features")
+	})
+}
+
+// initializeAdmin creates the first admin user from ADMIN_EMAIL and
+// ADMIN_INITIAL_PASSWORD when there are no admin users yet.
+func initializeAdmin(db *gorm.DB) error {
+	// Users created before roles existed had full access
+	if err := db.Model(&models.AdminUser{}).Where("role = ?", "admin").
+		Update("role", middlewares.RoleOwner).Error; err != nil {
+		return err
+	}
+
+	var count int64
+	db.Model(&models.AdminUser{}).Count(&count)
+	if count > 0 {
+		return nil
+	}
+
+	email := strings.ToLower(strings.TrimSpace(os.Getenv("ADMIN_EMAIL")))
+	password := os.Getenv("ADMIN_INITIAL_PASSWORD")
+	if email == "" || password == "" {
+		log.Println("No admin users exist; set ADMIN_EMAIL and ADMIN_INITIAL_PASSWORD to create one")
+		return nil
+	}
+
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		return err
+	}
+	admin := &models.AdminUser{
+		Email:        email,
+		Name:         "Administrator",
+		PasswordHash: string(hash),
+		Role:         middlewares.RoleOwner,
+		Active:       true,
+	}
+	if err := db.Create(admin).Error; err != nil {
+		return err
+	}
+	log.Printf("Created initial admin user %s", email)
+	return nil
+}
+
+// protectAuditLog installs a trigger that rejects updates and deletes on
+// the audit log, so entries cannot be rewritten even with database access
+// through the application.
+func protectAuditLog(db *gorm.DB) error {
+	return db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Exec(`CREATE OR REPLACE FUNCTION audit_logs_append_only() RETURNS trigger AS $$
+BEGIN
+	RAISE EXCEPTION 'audit_logs is append-only';
+END;
+$$ LANGUAGE plpgsql`).Error; err != nil {
+			return err
+		}
+		if err := tx.Exec(`DROP TRIGGER IF EXISTS audit_logs_append_only ON audit_logs`).Error; err != nil {
+			return err
+		}
+		return tx.Exec(`CREATE TRIGGER audit_logs_append_only BEFORE UPDATE OR DELETE ON audit_logs
+	FOR EACH ROW EXECUTE FUNCTION audit_logs_append_only()`).Error
+	})
+}
+
+// linkBookingsToHalls adds the foreign key from bookings to halls, so a
+// hall with bookings cannot be deleted. Bookings whose hall row is already
+// gone get an archived placeholder hall first, so the key can be added
+// without losing them.
+func linkBookingsToHalls(db *gorm.DB) error {
+	if db.Migrator().HasConstraint(&models.Booking{}, "fk_bookings_hall") {
+		return nil
+	}
+	return db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Exec(`INSERT INTO halls (id, name, capacity, base_price, status, archived_at, created_at, updated_at)
+	SELECT DISTINCT b.hall_id, 'Unknown hall (' || b.hall_id || ')', 0, 0, ?, NOW(), NOW(), NOW()
+	FROM bookings b LEFT JOIN halls h ON h.id = b.hall_id
+	WHERE h.id IS NULL`, models.HallArchived).Error; err != nil {
+			return err
+		}
+		return tx.Exec(`ALTER TABLE bookings ADD CONSTRAINT fk_bookings_hall
+	FOREIGN KEY (hall_id) REFERENCES halls (id) ON DELETE RESTRICT`).Error
+	})
+}
+
+// migrateContactStatuses maps the statuses contacts had before they became
+// tickets: unread and read messages are open, replied ones pending.
+func migrateContactStatuses(db *gorm.DB) error {
+	return db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&models.Contact{}).Where("status IN ?", []string{"unread", "read"}).
+			Update("status", models.ContactOpen).Error; err != nil {
+			return err
+		}
+		return tx.Model(&models.Contact{}).Where("status = ?", "replied").
+			Update("status", models.ContactPending).Error
+	})
+}
+
+// trustedProxies returns the addresses or CIDR ranges in TRUSTED_PROXIES,
+// the reverse proxies whose X-Forwarded-For is believed. None by default,
+// so clients cannot choose their own address.
+func trustedProxies() []string {
+	var proxies []string
+	for _, proxy := range strings.Split(os.Getenv("TRUSTED_PROXIES"), ",") {
+		if proxy = strings.TrimSpace(proxy); proxy != "" {
+			proxies = append(proxies, proxy)
+		}
+	}
+	return proxies
+}
+
 func main() {
 	// Load environment variables
 	if err := godotenv.Load(); err != nil {
@@ -69,89 +259,319 @@ func main() {
 	dsn := fmt.Sprintf("host=%s user=%s password=%s dbname=%s port=%s sslmode=disable",
 		dbHost, dbUser, dbPass, dbName, dbPort)
 
-	// Database connection
-	db, err := gorm.Open(postgres.Open(dsn), &gorm.Config{})
+	// Database connection. TranslateError turns driver errors such as
+	// unique violations into gorm.ErrDuplicatedKey and friends.
+	db, err := gorm.Open(postgres.Open(dsn), &gorm.Config{TranslateError: true})
 	if err != nil {
 		log.Fatalf("Failed to connect to database: %v", err)
 	}
 
 	// Auto migrate models
-	if err := db.AutoMigrate(&models.Booking{}, &models.Hall{}); err != nil {
+	if err := db.AutoMigrate(&models.Booking{}, &models.Hall{}, &models.RescheduleRequest{}, &models.BookingHistory{},
+		&models.Customer{}, &models.CustomerToken{}, &models.AdminUser{}, &models.AdminSession{},
+		&models.AdminRecoveryCode{}, &models.AdminLoginChallenge{}, &models.MFAPolicy{}, &models.SigningKey{},
+		&models.LoginThrottle{}, &models.SecurityEvent{}, &models.AuditLog{}, &models.Contact{}, &models.ImportBatch{},
+		&models.HallMedia{}, &models.Amenity{}, &models.HallLayout{},
+		&models.ContactMessage{}, &models.RateLimitCounter{}, &models.SubmissionReview{}, &models.OutboxMessage{}); err != nil {
 		log.Fatalf("Failed to migrate database: %v", err)
 	}
 
+	if err := protectAuditLog(db); err != nil {
+		log.Fatalf("Failed to protect audit log: %v", err)
+	}
+
+	if err := initializeAmenities(db); err != nil {
+		log.Printf("Warning: Failed to initialize amenities: %v", err)
+	}
+	if err := migrateHallFeatures(db); err != nil {
+		log.Fatalf("Failed to migrate hall features: %v", err)
+	}
+
 	// Initialize halls
 	if err := initializeHalls(db); err != nil {
 		log.Printf("Warning: Failed to initialize halls: %v", err)
 	}
 
+	if err := linkBookingsToHalls(db); err != nil {
+		log.Fatalf("Failed to link bookings to halls: %v", err)
+	}
+
+	if err := migrateContactStatuses(db); err != nil {
+		log.Fatalf("Failed to migrate contact statuses: %v", err)
+	}
+
+	// Initialize the first admin user
+	if err := initializeAdmin(db); err != nil {
+		log.Printf("Warning: Failed to initialize admin user: %v", err)
+	}
+
+	// Command line tools work on the database directly and exit
+	if len(os.Args) > 1 && os.Args[1] == "import" {
+		if err := runImportCommand(db, os.Args[2:]); err != nil {
+			log.Fatalf("Import failed: %v", err)
+		}
+		return
+	}
+
+	// Initialize JWT signing keys; refuse to start without key material
+	keyManager, err := services.NewKeyManager(db)
+	if err != nil {
+		log.Fatalf("Failed to initialize signing keys: %v", err)
+	}
+	middlewares.UseKeyManager(keyManager)
+	go keyManager.Run(context.Background())
+
 	// Initialize services
-	emailService := services.NewEmailService()
+	replyAddresses, err := services.NewReplyAddressService()
+	if err != nil {
+		log.Fatalf("Failed to initialize reply addresses: %v", err)
+	}
+	notifier, err := services.NewNotifier()
+	if err != nil {
+		log.Fatalf("Failed to initialize mail: %v", err)
+	}
+	// Mail is queued in the database and sent by the outbox worker
+	outbox := services.NewOutbox(db, notifier, services.LoadOutboxPolicy())
+	go outbox.Run(context.Background())
+	mailer := services.NewMailer(outbox, replyAddresses)
+	bookingPolicy := services.LoadBookingPolicy()
+	captcha, err := services.NewCaptchaVerifier()
+	if err != nil {
+		log.Fatalf("Failed to initialize CAPTCHA verification: %v", err)
+	}
+	spamGuard := services.NewSpamGuard(db, services.LoadSpamPolicy(), captcha)
+	go spamGuard.Run(context.Background())
+	loginThrottle := services.NewLoginThrottle(db)
+	manageLinks, err := services.NewManageLinkService()
+	if err != nil {
+		log.Fatalf("Failed to initialize manage links: %v", err)
+	}
+	mediaStorage, err := services.NewMediaStorage()
+	if err != nil {
+		log.Fatalf("Failed to initialize media storage: %v", err)
+	}
 
 	// Initialize controllers
-	bookingController := controllers.NewBookingController(db, emailService)
+	bookingController := controllers.NewBookingController(db, mailer, manageLinks, bookingPolicy, spamGuard)
+	manageController := controllers.NewManageController(db, mailer, manageLinks, bookingPolicy)
+	accountController := controllers.NewAccountController(db, mailer, loginThrottle)
+	adminAuthController := controllers.NewAdminAuthController(db, loginThrottle)
+	adminUserController := controllers.NewAdminUserController(db)
+	signingKeyController := controllers.NewSigningKeyController(db, keyManager)
+	securityController := controllers.NewSecurityController(db, loginThrottle)
+	hallController := controllers.NewHallController(db, mediaStorage)
+	amenityController := controllers.NewAmenityController(db)
+	auditController := controllers.NewAuditController(db)
+	exportController := controllers.NewExportController(db)
+	analyticsController := controllers.NewAnalyticsController(db)
+	importController := controllers.NewImportController(db)
+	ticketSLA := services.LoadTicketSLA()
+	contactController := controllers.NewContactController(db, ticketSLA, mailer, spamGuard)
+	reviewController := controllers.NewReviewController(db, bookingController, contactController)
+	inboundController := controllers.NewInboundController(db, replyAddresses, ticketSLA)
+	outboxController := controllers.NewOutboxController(db, outbox)
+	emailTemplateController := controllers.NewEmailTemplateController()
+
+	// Customer replies can be relayed over SMTP by the venue's mail server
+	if addr := os.Getenv("INBOUND_SMTP_ADDR"); addr != "" && replyAddresses.Enabled() {
+		hostname := os.Getenv("INBOUND_SMTP_HOSTNAME")
+		if hostname == "" {
+			hostname = os.Getenv("INBOUND_DOMAIN")
+		}
+		inboundSMTP := &services.InboundSMTPServer{
+			Addr:     addr,
+			Hostname: hostname,
+			MaxSize:  10 << 20,
+			Accept:   inboundController.Accept,
+			Deliver:  inboundController.Deliver,
+		}
+		go func() {
+			if err := inboundSMTP.ListenAndServe(); err != nil {
+				log.Fatalf("Inbound SMTP server failed: %v", err)
+			}
+		}()
+	}
 
 	// Initialize router
 	router := gin.Default()
+	// ClientIP keys the rate limits and login throttle, so it may only
+	// read X-Forwarded-For from proxies we run
+	if err := router.SetTrustedProxies(trustedProxies()); err != nil {
+		log.Fatalf("Invalid TRUSTED_PROXIES: %v", err)
+	}
+	router.Use(middlewares.RequestID())
 
 	// CORS configuration
 	config := cors.DefaultConfig()
 	config.AllowOrigins = []string{"http://localhost:5190", "http://localhost:5173"}
 	config.AllowMethods = []string{"GET", "POST", "PUT", "PATCH", "DELETE", "OPTIONS"}
-	config.AllowHeaders = []string{"Origin", "Content-Type", "Accept", "Authorization"}
+	config.AllowHeaders = []string{"Origin", "Content-Type", "Accept", "Authorization", middlewares.RequestIDHeader}
+	config.ExposeHeaders = []string{middlewares.RequestIDHeader, "Content-Disposition"}
 	config.AllowCredentials = true
 	router.Use(cors.New(config))
 
 	// Public routes
-	router.GET("/api/halls", func(c *gin.Context) {
-		var halls []models.Hall
-		if err := db.Find(&halls).Error; err != nil {
-			c.JSON(500, gin.H{"error": "Failed to fetch halls"})
-			return
-		}
-		c.JSON(200, halls)
+	router.GET("/.well-known/jwks.json", func(c *gin.Context) {
+		c.JSON(200, keyManager.JWKS())
 	})
 
-	router.POST("/api/bookings", bookingController.CreateBooking)
+	router.GET("/api/halls", hallController.ListHalls)
+	router.GET("/api/halls/search", hallController.SearchHalls)
+	router.GET("/api/amenities", amenityController.ListAmenities)
+	if local, ok := mediaStorage.(*services.LocalStorage); ok {
+		router.Static(services.LocalMediaPath, local.Dir)
+	}
+
+	router.POST("/api/bookings", middlewares.OptionalAuth(), bookingController.CreateBooking)
+	router.POST("/api/contacts", contactController.CreateContact)
+	router.POST("/api/inbound/email", inboundController.Receive)
+
+	// Customer accounts
+	account := router.Group("/api/account")
+	{
+		account.POST("/register", accountController.Register)
+		account.POST("/verify-email", accountController.VerifyEmail)
+		account.POST("/login", accountController.Login)
+		account.POST("/password/forgot", accountController.ForgotPassword)
+		account.POST("/password/reset", accountController.ResetPassword)
+
+		customer := account.Group("", middlewares.AuthMiddleware(), middlewares.RequireRole(middlewares.RoleCustomer))
+		customer.GET("/me", accountController.GetProfile)
+		customer.PUT("/me", accountController.UpdateProfile)
+		customer.POST("/verify-email/resend", accountController.ResendVerification)
+		customer.GET("/bookings", accountController.GetBookings)
+		customer.POST("/bookings/claim", accountController.ClaimBookings)
+	}
+
+	// Customer self-service through the signed manage link
+	manage := router.Group("/api/manage/:token")
+	{
+		manage.GET("", manageController.GetBooking)
+		manage.POST("/cancel", manageController.CancelBooking)
+		manage.POST("/reschedule", manageController.RescheduleBooking)
+		manage.POST("/reschedule-request", manageController.RequestReschedule)
+		manage.GET("/documents/confirmation", manageController.DownloadConfirmation)
+		manage.GET("/documents/calendar.ics", manageController.DownloadCalendar)
+	}
+
+	// Admin sign-in
+	router.POST("/api/admin/auth/login", adminAuthController.Login)
+	router.POST("/api/admin/auth/login/mfa", adminAuthController.VerifyLoginMFA)
+	router.POST("/api/admin/auth/refresh", adminAuthController.Refresh)
 
 	// Admin routes (protected)
 	admin := router.Group("/api/admin")
-	admin.Use(middlewares.AdminAuth())
+	admin.Use(middlewares.AdminAuth(db, loginThrottle))
+	{
+		admin.POST("/auth/logout", adminAuthController.Logout)
+		admin.POST("/auth/logout-all", adminAuthController.LogoutAll)
+		admin.GET("/auth/me", adminAuthController.Me)
+		admin.GET("/auth/sessions", adminAuthController.ListSessions)
+		admin.DELETE("/auth/sessions/:id", adminAuthController.RevokeSession)
+		admin.POST("/auth/2fa/setup", adminAuthController.SetupTOTP)
+		admin.POST("/auth/2fa/confirm", adminAuthController.ConfirmTOTP)
+		admin.POST("/auth/2fa/disable", adminAuthController.DisableTOTP)
+		admin.POST("/auth/2fa/recovery-codes", adminAuthController.RegenerateRecoveryCodes)
+	}
+
+	// Everything else needs 2FA set up where the policy requires it
+	admin = admin.Group("", middlewares.RequireMFAEnrollment())
 	{
-		admin.GET("/bookings", bookingController.GetBookings)
-		admin.PUT("/bookings/:id/status", bookingController.UpdateBookingStatus)
+		admin.GET("/bookings", middlewares.RequirePermission(middlewares.PermViewBookings), bookingController.GetBookings)
+		admin.POST("/bookings/bulk", middlewares.RequirePermission(middlewares.PermUpdateStatus), bookingController.BulkUpdateBookings)
+		admin.PUT("/bookings/:id/status", middlewares.RequirePermission(middlewares.PermUpdateStatus), bookingController.UpdateBookingStatus)
+		admin.POST("/bookings/:id/reschedule", middlewares.RequirePermission(middlewares.PermManageBookings), bookingController.RescheduleBooking)
+		admin.POST("/bookings/:id/payments", middlewares.RequirePermission(middlewares.PermManageBookings), bookingController.RecordPayment)
+		admin.GET("/bookings/:id/history", middlewares.RequirePermission(middlewares.PermViewBookings), bookingController.GetBookingHistory)
+
+		// Contact form inbox
+		admin.GET("/contacts", middlewares.RequirePermission(middlewares.PermViewBookings), contactController.GetContacts)
+		admin.GET("/contacts/:id", middlewares.RequirePermission(middlewares.PermViewBookings), contactController.GetContact)
+		contacts := admin.Group("/contacts/:id", middlewares.RequirePermission(middlewares.PermUpdateStatus))
+		contacts.PUT("/status", contactController.UpdateContactStatus)
+		contacts.POST("/replies", contactController.ReplyToContact)
+		contacts.POST("/notes", contactController.AddContactNote)
+		contacts.PUT("/assignee", contactController.AssignContact)
+		contacts.PUT("/booking", contactController.LinkContactBooking)
+		contacts.PUT("/tags", contactController.SetContactTags)
+
+		// Public submissions held back by the spam checks
+		admin.GET("/reviews", middlewares.RequirePermission(middlewares.PermViewBookings), reviewController.ListReviews)
+		admin.POST("/reviews/:id/approve", middlewares.RequirePermission(middlewares.PermUpdateStatus), reviewController.ApproveReview)
+		admin.POST("/reviews/:id/reject", middlewares.RequirePermission(middlewares.PermUpdateStatus), reviewController.RejectReview)
+
+		// Outgoing email: queued, sent and given up
+		admin.GET("/outbox", middlewares.RequirePermission(middlewares.PermManageBookings), outboxController.ListOutbox)
+		admin.GET("/outbox/:id", middlewares.RequirePermission(middlewares.PermManageBookings), outboxController.GetOutboxMessage)
+		admin.POST("/outbox/:id/resend", middlewares.RequirePermission(middlewares.PermManageBookings), outboxController.ResendOutboxMessage)
+
+		// Email templates, rendered with sample data (?locale=hi&format=html)
+		admin.GET("/email-templates", middlewares.RequirePermission(middlewares.PermManageBookings), emailTemplateController.ListEmailTemplates)
+		admin.GET("/email-templates/:name/preview", middlewares.RequirePermission(middlewares.PermManageBookings), emailTemplateController.PreviewEmailTemplate)
+
+		// Exports (?format=csv or xlsx)
+		admin.GET("/exports/bookings", middlewares.RequirePermission(middlewares.PermViewBookings), exportController.ExportBookings)
+		admin.GET("/exports/payments", middlewares.RequirePermission(middlewares.PermViewRevenue), exportController.ExportPayments)
+		admin.GET("/exports/contacts", middlewares.RequirePermission(middlewares.PermViewBookings), exportController.ExportContacts)
+
+		// Reports
+		admin.GET("/analytics/revenue", middlewares.RequirePermission(middlewares.PermViewRevenue), analyticsController.Revenue)
+		admin.GET("/analytics/bookings", middlewares.RequirePermission(middlewares.PermViewRevenue), analyticsController.Bookings)
+		admin.GET("/analytics/comparison", middlewares.RequirePermission(middlewares.PermViewRevenue), analyticsController.Comparison)
+		admin.GET("/analytics/occupancy", middlewares.RequirePermission(middlewares.PermViewBookings), analyticsController.Occupancy)
+
+		// Bulk import of historical and offline bookings
+		imports := admin.Group("/imports", middlewares.RequirePermission(middlewares.PermManageBookings))
+		imports.GET("", importController.ListImports)
+		imports.POST("", importController.ImportBookings)
+		imports.POST("/:id/rollback", importController.RollbackImport)
+
+		// Staff accounts
+		users := admin.Group("/users", middlewares.RequirePermission(middlewares.PermManageUsers))
+		users.GET("", adminUserController.ListUsers)
+		users.POST("", adminUserController.CreateUser)
+		users.PUT("/:id", adminUserController.UpdateUser)
+		users.POST("/:id/password", adminUserController.ResetUserPassword)
+		users.POST("/:id/2fa/reset", adminUserController.ResetUserTOTP)
+		users.GET("/2fa-policy", adminUserController.GetMFAPolicy)
+		users.PUT("/2fa-policy/:role", adminUserController.UpdateMFAPolicy)
+
+		// Token signing keys
+		signingKeys := admin.Group("/security/keys", middlewares.RequirePermission(middlewares.PermManageUsers))
+		signingKeys.GET("", signingKeyController.ListKeys)
+		signingKeys.POST("/rotate", signingKeyController.RotateKeys)
+
+		// Sign-in lockouts
+		security := admin.Group("/security", middlewares.RequirePermission(middlewares.PermManageUsers))
+		security.GET("/lockouts", securityController.ListLockouts)
+		security.POST("/unlock", securityController.Unlock)
+		security.GET("/events", securityController.ListEvents)
+
+		// Audit log
+		audit := admin.Group("/audit", middlewares.RequirePermission(middlewares.PermViewAudit))
+		audit.GET("", auditController.ListAudit)
+		audit.GET("/export", auditController.ExportAudit)
 
 		// Hall management
-		admin.POST("/halls", func(c *gin.Context) {
-			var hall models.Hall
-			if err := c.ShouldBindJSON(&hall); err != nil {
-				c.JSON(400, gin.H{"error": err.Error()})
-				return
-			}
-			if err := db.Create(&hall).Error; err != nil {
-				c.JSON(500, gin.H{"error": "Failed to create hall"})
-				return
-			}
-			c.JSON(201, hall)
-		})
-
-		admin.PUT("/halls/:id", func(c *gin.Context) {
-			id := c.Param("id")
-			var hall models.Hall
-			if err := db.First(&hall, "id = ?", id).Error; err != nil {
-				c.JSON(404, gin.H{"error": "Hall not found"})
-				return
-			}
-			if err := c.ShouldBindJSON(&hall); err != nil {
-				c.JSON(400, gin.H{"error": err.Error()})
-				return
-			}
-			if err := db.Save(&hall).Error; err != nil {
-				c.JSON(500, gin.H{"error": "Failed to update hall"})
-				return
-			}
-			c.JSON(200, hall)
-		})
+		halls := admin.Group("/halls", middlewares.RequirePermission(middlewares.PermManageHalls))
+		halls.POST("", hallController.CreateHall)
+		halls.GET("", hallController.AdminListHalls)
+		halls.PUT("/:id", hallController.UpdateHall)
+		halls.POST("/:id/deactivate", hallController.DeactivateHall)
+		halls.POST("/:id/activate", hallController.ActivateHall)
+		halls.DELETE("/:id", hallController.ArchiveHall)
+		halls.GET("/:id/media", hallController.ListMedia)
+		halls.POST("/:id/media", hallController.UploadMedia)
+		halls.PUT("/:id/media/order", hallController.ReorderMedia)
+		halls.PATCH("/:id/media/:mediaId", hallController.UpdateMedia)
+		halls.DELETE("/:id/media/:mediaId", hallController.DeleteMedia)
+		halls.PUT("/:id/amenities", hallController.SetAmenities)
+		halls.PUT("/:id/layouts", hallController.SetLayouts)
+
+		amenities := admin.Group("/amenities", middlewares.RequirePermission(middlewares.PermManageHalls))
+		amenities.POST("", amenityController.CreateAmenity)
+		amenities.PUT("/:id", amenityController.UpdateAmenity)
+		amenities.DELETE("/:id", amenityController.DeleteAmenity)
 	}
 
 	// Start server
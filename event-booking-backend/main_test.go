@@ -0,0 +1,34 @@
+package main
+
+import (
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestTrustedProxies(t *testing.T) {
+	clientIP := func() string {
+		t.Helper()
+		router := gin.New()
+		if err := router.SetTrustedProxies(trustedProxies()); err != nil {
+			t.Fatal(err)
+		}
+		var ip string
+		router.GET("/", func(ctx *gin.Context) { ip = ctx.ClientIP() })
+		req := httptest.NewRequest("GET", "/", nil)
+		req.RemoteAddr = "10.0.0.5:41000"
+		req.Header.Set("X-Forwarded-For", "203.0.113.9")
+		router.ServeHTTP(httptest.NewRecorder(), req)
+		return ip
+	}
+
+	t.Setenv("TRUSTED_PROXIES", "")
+	if ip := clientIP(); ip != "10.0.0.5" {
+		t.Errorf("with no trusted proxies, client IP = %s, want the peer address", ip)
+	}
+	t.Setenv("TRUSTED_PROXIES", "10.0.0.0/8, 192.168.1.1")
+	if ip := clientIP(); ip != "203.0.113.9" {
+		t.Errorf("behind a trusted proxy, client IP = %s, want the forwarded address", ip)
+	}
+}
@@ -1,15 +1,46 @@
 package middlewares
 
 import (
+	"errors"
+	"math"
 	"net/http"
-	"os"
+	"strconv"
 	"strings"
+	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt/v5"
+	"gorm.io/gorm"
+
+	"event-booking-backend/models"
+	"event-booking-backend/services"
 )
 
-func AdminAuth() gin.HandlerFunc {
+// AdminIdentity describes the admin user behind a request, for handlers
+// that need to record who did what.
+type AdminIdentity struct {
+	UserID    uint
+	Email     string
+	Role      string
+	SessionID string
+	// MFAEnrollmentRequired is set when the user's role requires 2FA and
+	// they have not enrolled yet.
+	MFAEnrollmentRequired bool
+}
+
+// AdminAuth accepts access tokens issued by the admin login and checks that
+// their session is still live and the user still active. Forged tokens count
+// against the caller's IP in throttle, so guessing gets slowed down too.
+func AdminAuth(db *gorm.DB, throttle *services.LoginThrottle) gin.HandlerFunc {
 	return func(c *gin.Context) {
+		ipKey := services.IPKey(c.ClientIP())
+		if wait := throttle.Blocked(ipKey); wait > 0 {
+			c.Header("Retry-After", strconv.Itoa(int(math.Ceil(wait.Seconds()))))
+			c.JSON(http.StatusTooManyRequests, gin.H{"error": "Too many failed attempts, please try again later"})
+			c.Abort()
+			return
+		}
+
 		authHeader := c.GetHeader("Authorization")
 		if authHeader == "" {
 			c.JSON(http.StatusUnauthorized, gin.H{"error": "Authorization header required"})
@@ -25,15 +56,88 @@ func AdminAuth() gin.HandlerFunc {
 			return
 		}
 
-		token := parts[1]
-		adminToken := os.Getenv("ADMIN_TOKEN")
+		claims, err := parseToken(parts[1])
+		if err != nil && !errors.Is(err, jwt.ErrTokenExpired) {
+			// Expired tokens are routine; anything else was not issued by us.
+			throttle.RecordFailure(c.ClientIP(), ipKey)
+		}
+		if err != nil || claims.ID == "" || !ValidRole(claims.Role) {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid or expired token"})
+			c.Abort()
+			return
+		}
+
+		var session models.AdminSession
+		err = db.Where("id = ? AND admin_user_id = ? AND revoked_at IS NULL AND expires_at > ?",
+			claims.ID, claims.UserID, time.Now()).First(&session).Error
+		if err != nil {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "Session has been revoked"})
+			c.Abort()
+			return
+		}
 
-		if token != adminToken {
-			c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid admin token"})
+		var user models.AdminUser
+		if err := db.First(&user, claims.UserID).Error; err != nil || !user.Active {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "Account is disabled"})
+			c.Abort()
+			return
+		}
+		// Role changes revoke sessions, so a live token's role is current.
+		if user.Role != claims.Role {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid or expired token"})
 			c.Abort()
 			return
 		}
 
+		c.Set("user_id", user.ID)
+		c.Set("role", claims.Role)
+		c.Set("admin", &AdminIdentity{
+			UserID:                user.ID,
+			Email:                 user.Email,
+			Role:                  claims.Role,
+			SessionID:             session.ID,
+			MFAEnrollmentRequired: MFAEnrollmentRequired(db, &user),
+		})
+		c.Next()
+	}
+}
+
+// RequireMFAEnrollment stops users who still have to enroll in 2FA from
+// reaching anything but the enrollment routes. It must run after AdminAuth.
+func RequireMFAEnrollment() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if admin := CurrentAdmin(c); admin != nil && admin.MFAEnrollmentRequired {
+			c.JSON(http.StatusForbidden, gin.H{
+				"error": "Two-factor authentication must be set up before continuing",
+				"code":  "mfa_enrollment_required",
+			})
+			c.Abort()
+			return
+		}
 		c.Next()
 	}
 }
+
+// MFAEnrollmentRequired reports whether the policy requires 2FA for the
+// user's role while the user has not enrolled.
+func MFAEnrollmentRequired(db *gorm.DB, user *models.AdminUser) bool {
+	return !user.TOTPEnabled() && MFARequiredForRole(db, user.Role)
+}
+
+// MFARequiredForRole reports whether the policy requires 2FA for role.
+func MFARequiredForRole(db *gorm.DB, role string) bool {
+	var count int64
+	db.Model(&models.MFAPolicy{}).Where("role = ? AND required", role).Count(&count)
+	return count > 0
+}
+
+// CurrentAdmin returns the admin set by AdminAuth, or nil outside admin
+// routes.
+func CurrentAdmin(c *gin.Context) *AdminIdentity {
+	if v, ok := c.Get("admin"); ok {
+		if identity, ok := v.(*AdminIdentity); ok {
+			return identity
+		}
+	}
+	return nil
+}
@@ -1,15 +1,32 @@
 package middlewares
 
 import (
+	"errors"
 	"net/http"
-	"os"
 	"strings"
 	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/golang-jwt/jwt/v5"
+
+	"event-booking-backend/services"
 )
 
+// RoleCustomer is the role carried by tokens issued to customer accounts.
+// Admin tokens carry one of the staff roles in rbac.go.
+const RoleCustomer = "customer"
+
+var errNoKeyManager = errors.New("no signing key manager configured")
+
+// keys signs and verifies every token issued by this package. It is set
+// once at startup by UseKeyManager.
+var keys *services.KeyManager
+
+// UseKeyManager sets the key manager used to sign and verify tokens.
+func UseKeyManager(km *services.KeyManager) {
+	keys = km
+}
+
 // Claims represents the JWT claims structure
 type Claims struct {
 	UserID uint   `json:"user_id"`
@@ -27,15 +44,8 @@ func AuthMiddleware() gin.HandlerFunc {
 			return
 		}
 
-		token := strings.TrimPrefix(authorization, "Bearer ")
-		claims := &Claims{}
-
-		// Parse and validate the token
-		tokenObj, err := jwt.ParseWithClaims(token, claims, func(token *jwt.Token) (interface{}, error) {
-			return []byte(os.Getenv("JWT_SECRET")), nil
-		})
-
-		if err != nil || !tokenObj.Valid {
+		claims, err := parseToken(authorization)
+		if err != nil {
 			c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid or expired token"})
 			c.Abort()
 			return
@@ -48,17 +58,80 @@ func AuthMiddleware() gin.HandlerFunc {
 	}
 }
 
+// OptionalAuth sets the token's claims on the context when a valid token is
+// sent, and lets the request through either way.
+func OptionalAuth() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if authorization := c.GetHeader("Authorization"); authorization != "" {
+			if claims, err := parseToken(authorization); err == nil {
+				c.Set("user_id", claims.UserID)
+				c.Set("role", claims.Role)
+			}
+		}
+		c.Next()
+	}
+}
+
+// RequireRole rejects requests whose token was not issued for role. It must
+// run after AuthMiddleware.
+func RequireRole(role string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if c.GetString("role") != role {
+			c.JSON(http.StatusForbidden, gin.H{"error": "Insufficient permissions"})
+			c.Abort()
+			return
+		}
+		c.Next()
+	}
+}
+
+func parseToken(authorization string) (*Claims, error) {
+	if keys == nil {
+		return nil, errNoKeyManager
+	}
+	token := strings.TrimPrefix(authorization, "Bearer ")
+	claims := &Claims{}
+
+	// Parse and validate the token
+	tokenObj, err := jwt.ParseWithClaims(token, claims, keys.Keyfunc, jwt.WithValidMethods(keys.ValidMethods()))
+	if err != nil {
+		return nil, err
+	}
+	if !tokenObj.Valid {
+		return nil, jwt.ErrTokenInvalidClaims
+	}
+	return claims, nil
+}
+
 // GenerateToken creates a new JWT token for the user
 func GenerateToken(userID uint, role string) (string, error) {
-	claims := &Claims{
+	return signToken(&Claims{
 		UserID: userID,
 		Role:   role,
 		RegisteredClaims: jwt.RegisteredClaims{
-			ExpiresAt: jwt.NewNumericDate(time.Now().Add(24 * time.Hour)),
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(services.MaxTokenLifetime)),
 			IssuedAt:  jwt.NewNumericDate(time.Now()),
 		},
-	}
+	})
+}
 
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	return token.SignedString([]byte(os.Getenv("JWT_SECRET")))
+// GenerateSessionToken creates a short-lived access token bound to an admin
+// session. The session ID travels as the token's ID claim.
+func GenerateSessionToken(userID uint, role, sessionID string, ttl time.Duration) (string, error) {
+	return signToken(&Claims{
+		UserID: userID,
+		Role:   role,
+		RegisteredClaims: jwt.RegisteredClaims{
+			ID:        sessionID,
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(ttl)),
+			IssuedAt:  jwt.NewNumericDate(time.Now()),
+		},
+	})
+}
+
+func signToken(claims *Claims) (string, error) {
+	if keys == nil {
+		return "", errNoKeyManager
+	}
+	return keys.Sign(claims)
 }
@@ -0,0 +1,69 @@
+package middlewares
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Staff roles, from least to most privileged.
+const (
+	RoleFrontDesk = "front_desk"
+	RoleManager   = "manager"
+	RoleOwner     = "owner"
+)
+
+// Permission names one thing an admin user may do.
+type Permission string
+
+const (
+	PermViewBookings   Permission = "bookings:view"
+	PermUpdateStatus   Permission = "bookings:update_status"
+	PermManageBookings Permission = "bookings:manage"
+	PermManageHalls    Permission = "halls:manage"
+	PermViewRevenue    Permission = "revenue:view"
+	PermManageUsers    Permission = "users:manage"
+	PermViewAudit      Permission = "audit:view"
+)
+
+// rolePermissions lists what each role may do. Each role includes the
+// permissions of the roles below it.
+var rolePermissions = map[string][]Permission{
+	RoleFrontDesk: {PermViewBookings, PermUpdateStatus},
+	RoleManager:   {PermViewBookings, PermUpdateStatus, PermManageBookings, PermManageHalls},
+	RoleOwner:     {PermViewBookings, PermUpdateStatus, PermManageBookings, PermManageHalls, PermViewRevenue, PermManageUsers, PermViewAudit},
+}
+
+// ValidRole reports whether role is a staff role.
+func ValidRole(role string) bool {
+	_, ok := rolePermissions[role]
+	return ok
+}
+
+// HasPermission reports whether role grants perm.
+func HasPermission(role string, perm Permission) bool {
+	for _, p := range rolePermissions[role] {
+		if p == perm {
+			return true
+		}
+	}
+	return false
+}
+
+// PermissionsFor returns the permissions granted to role.
+func PermissionsFor(role string) []Permission {
+	return rolePermissions[role]
+}
+
+// RequirePermission rejects admin requests whose role lacks perm. It must
+// run after AdminAuth.
+func RequirePermission(perm Permission) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !HasPermission(c.GetString("role"), perm) {
+			c.JSON(http.StatusForbidden, gin.H{"error": "Insufficient permissions"})
+			c.Abort()
+			return
+		}
+		c.Next()
+	}
+}
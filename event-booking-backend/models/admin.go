@@ -0,0 +1,71 @@
+package models
+
+import "time"
+
+// AdminUser is a member of staff who can sign in to the admin dashboard.
+type AdminUser struct {
+	ID           uint       `json:"id" gorm:"primaryKey"`
+	Email        string     `json:"email" gorm:"type:text;not null;uniqueIndex"`
+	Name         string     `json:"name" gorm:"type:text;not null"`
+	PasswordHash string     `json:"-" gorm:"type:text;not null"`
+	Role         string     `json:"role" gorm:"type:text;not null;default:'front_desk'"` // front_desk, manager, owner
+	Active       bool       `json:"active" gorm:"not null;default:true"`
+	LastLoginAt  *time.Time `json:"lastLoginAt,omitempty"`
+	// TOTPSecret is set during enrollment and only in force once
+	// TOTPEnabledAt is set.
+	TOTPSecret    string     `json:"-" gorm:"type:text"`
+	TOTPEnabledAt *time.Time `json:"totpEnabledAt,omitempty"`
+	// TOTPLastStep is the last time step accepted, so a code cannot be
+	// replayed within its validity window.
+	TOTPLastStep int64     `json:"-" gorm:"not null;default:0"`
+	CreatedAt    time.Time `json:"createdAt" gorm:"autoCreateTime"`
+	UpdatedAt    time.Time `json:"updatedAt" gorm:"autoUpdateTime"`
+}
+
+// TOTPEnabled reports whether the user must present a TOTP code to sign in.
+func (u *AdminUser) TOTPEnabled() bool {
+	return u.TOTPEnabledAt != nil && u.TOTPSecret != ""
+}
+
+// AdminSession is one signed-in device. Access tokens carry the session ID
+// so revoking the session cuts them off immediately, and the refresh token
+// is rotated on every use.
+type AdminSession struct {
+	ID                string     `json:"id" gorm:"type:text;primaryKey"`
+	AdminUserID       uint       `json:"adminUserId" gorm:"not null;index"`
+	RefreshTokenHash  string     `json:"-" gorm:"type:text;not null;uniqueIndex"`
+	PreviousTokenHash string     `json:"-" gorm:"type:text;index"`
+	IP                string     `json:"ip" gorm:"type:text"`
+	UserAgent         string     `json:"userAgent" gorm:"type:text"`
+	ExpiresAt         time.Time  `json:"expiresAt" gorm:"not null"`
+	RevokedAt         *time.Time `json:"revokedAt,omitempty"`
+	LastUsedAt        time.Time  `json:"lastUsedAt"`
+	CreatedAt         time.Time  `json:"createdAt" gorm:"autoCreateTime"`
+}
+
+// AdminRecoveryCode is a one-time code that stands in for a TOTP code when
+// the user has lost their authenticator. Only the hash is stored.
+type AdminRecoveryCode struct {
+	ID          uint   `gorm:"primaryKey"`
+	AdminUserID uint   `gorm:"not null;index"`
+	CodeHash    string `gorm:"type:text;not null"`
+	UsedAt      *time.Time
+	CreatedAt   time.Time `gorm:"autoCreateTime"`
+}
+
+// AdminLoginChallenge is the second step of a login for users with 2FA. The
+// client holds the token; only its hash is stored.
+type AdminLoginChallenge struct {
+	TokenHash   string    `gorm:"type:text;primaryKey"`
+	AdminUserID uint      `gorm:"not null;index"`
+	Attempts    int       `gorm:"not null;default:0"`
+	ExpiresAt   time.Time `gorm:"not null"`
+	CreatedAt   time.Time `gorm:"autoCreateTime"`
+}
+
+// MFAPolicy says whether admin users with Role must enroll in 2FA.
+type MFAPolicy struct {
+	Role      string    `json:"role" gorm:"type:text;primaryKey"`
+	Required  bool      `json:"required" gorm:"not null;default:false"`
+	UpdatedAt time.Time `json:"updatedAt" gorm:"autoUpdateTime"`
+}
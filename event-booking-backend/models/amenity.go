@@ -0,0 +1,73 @@
+package models
+
+import (
+	"strings"
+	"time"
+	"unicode"
+)
+
+// Amenity is an entry in the venue's catalogue of things a hall can
+// offer. Halls link to amenities rather than describing them, so halls can
+// be filtered by amenity and the frontend needs no copy of the list.
+type Amenity struct {
+	// ID is a slug such as "projector", stable across renames.
+	ID       string `json:"id" gorm:"primaryKey;type:text"`
+	Name     string `json:"name" gorm:"type:text;not null"`
+	Category string `json:"category" gorm:"type:text;not null;default:'general';index"`
+	// Icon is a name from the frontend's icon set.
+	Icon      string    `json:"icon,omitempty" gorm:"type:text"`
+	CreatedAt time.Time `json:"createdAt" gorm:"autoCreateTime"`
+	UpdatedAt time.Time `json:"updatedAt" gorm:"autoUpdateTime"`
+}
+
+// Slugify turns a name into an amenity ID: lower case, with runs of
+// anything but letters and digits replaced by single underscores.
+func Slugify(name string) string {
+	var b strings.Builder
+	pending := false
+	for _, r := range strings.ToLower(name) {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			if pending && b.Len() > 0 {
+				b.WriteByte('_')
+			}
+			b.WriteRune(r)
+			pending = false
+		} else {
+			pending = true
+		}
+	}
+	return b.String()
+}
+
+type SeatingLayout string
+
+const (
+	LayoutTheatre   SeatingLayout = "theatre"
+	LayoutBanquet   SeatingLayout = "banquet"
+	LayoutCocktail  SeatingLayout = "cocktail"
+	LayoutClassroom SeatingLayout = "classroom"
+	LayoutBoardroom SeatingLayout = "boardroom"
+	LayoutUShape    SeatingLayout = "u_shape"
+)
+
+// SeatingLayouts lists every layout a hall can offer.
+var SeatingLayouts = []SeatingLayout{LayoutTheatre, LayoutBanquet, LayoutCocktail,
+	LayoutClassroom, LayoutBoardroom, LayoutUShape}
+
+func (l SeatingLayout) Valid() bool {
+	for _, layout := range SeatingLayouts {
+		if l == layout {
+			return true
+		}
+	}
+	return false
+}
+
+// HallLayout is a way a hall can be set up and how many guests it then
+// holds. Bookings that choose a layout are checked against its capacity
+// instead of the hall's.
+type HallLayout struct {
+	HallID   string        `json:"-" gorm:"primaryKey;type:text"`
+	Layout   SeatingLayout `json:"layout" gorm:"primaryKey;type:text"`
+	Capacity int           `json:"capacity" gorm:"not null"`
+}
@@ -0,0 +1,57 @@
+package models
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"time"
+)
+
+// AuditChange is the value of one field before and after a change.
+type AuditChange struct {
+	From interface{} `json:"from"`
+	To   interface{} `json:"to"`
+}
+
+// AuditChanges maps field names to their change, stored as jsonb.
+type AuditChanges map[string]AuditChange
+
+func (c AuditChanges) Value() (driver.Value, error) {
+	if c == nil {
+		return "{}", nil
+	}
+	b, err := json.Marshal(c)
+	return string(b), err
+}
+
+func (c *AuditChanges) Scan(value interface{}) error {
+	var b []byte
+	switch v := value.(type) {
+	case []byte:
+		b = v
+	case string:
+		b = []byte(v)
+	case nil:
+		*c = nil
+		return nil
+	default:
+		return errors.New("unsupported type for AuditChanges")
+	}
+	return json.Unmarshal(b, c)
+}
+
+// AuditLog is one admin action. Rows are only ever inserted; a database
+// trigger rejects updates and deletes.
+type AuditLog struct {
+	ID         uint64       `json:"id,string" gorm:"primaryKey;autoIncrement"`
+	ActorID    *uint        `json:"actorId,omitempty" gorm:"index"`
+	ActorEmail string       `json:"actorEmail" gorm:"type:text"`
+	ActorRole  string       `json:"actorRole" gorm:"type:text"`
+	Action     string       `json:"action" gorm:"type:text;not null;index"` // e.g. booking.status_updated
+	EntityType string       `json:"entityType" gorm:"type:text;not null;index:idx_audit_entity"`
+	EntityID   string       `json:"entityId" gorm:"type:text;index:idx_audit_entity"`
+	Changes    AuditChanges `json:"changes" gorm:"type:jsonb;not null;default:'{}'"`
+	IP         string       `json:"ip" gorm:"type:text"`
+	RequestID  string       `json:"requestId" gorm:"type:text"`
+	CreatedAt  time.Time    `json:"createdAt" gorm:"autoCreateTime;index"`
+}
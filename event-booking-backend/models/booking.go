@@ -14,33 +14,73 @@ const (
 
 type Booking struct {
     ID              uint64        `json:"id,string" gorm:"primaryKey;autoIncrement"`
-    HallID          string        `json:"hallId" gorm:"column:hall_id;type:text;not null"`
+    HallID          string        `json:"hallId" gorm:"column:hall_id;type:text;not null;index"`
+    CustomerID      *uint         `json:"customerId,omitempty" gorm:"column:customer_id;index"`
     CustomerName    string        `json:"customerName" gorm:"column:customer_name;type:text;not null"`
     CustomerEmail   string        `json:"customerEmail" gorm:"column:customer_email;type:text;not null"`
     CustomerPhone   string        `json:"customerPhone" gorm:"column:customer_phone;type:text;not null"`
     GuestCount      int           `json:"guestCount" gorm:"column:guest_count;not null"`
-    EventDate       time.Time     `json:"eventDate" gorm:"column:event_date;not null"`
+    // Layout is the seating layout asked for, if any; it sets the capacity
+    // the guest count is checked against.
+    Layout          SeatingLayout `json:"layout,omitempty" gorm:"column:layout;type:text"`
+    EventDate       time.Time     `json:"eventDate" gorm:"column:event_date;not null;index"`
     StartTime       string        `json:"startTime" gorm:"column:start_time;type:text;not null"`
     EndTime         string        `json:"endTime" gorm:"column:end_time;type:text;not null"`
     SpecialRequests string        `json:"specialRequests" gorm:"column:special_requests;type:text"`
-    Status          BookingStatus `json:"status" gorm:"column:status;type:text;not null;default:'pending'"`
+    Status          BookingStatus `json:"status" gorm:"column:status;type:text;not null;default:'pending';index"`
     TotalPrice      float64       `json:"totalPrice" gorm:"column:total_price;not null"`
-    CreatedAt       time.Time     `json:"createdAt" gorm:"column:created_at;not null;default:CURRENT_TIMESTAMP"`
+    RefundAmount    float64       `json:"refundAmount" gorm:"column:refund_amount;not null;default:0"`
+    FeeTotal        float64       `json:"feeTotal" gorm:"column:fee_total;not null;default:0"`
+    // AmountPaid is what the customer has paid so far, as recorded by staff.
+    AmountPaid      float64       `json:"amountPaid" gorm:"column:amount_paid;not null;default:0"`
+    RescheduleCount int           `json:"rescheduleCount" gorm:"column:reschedule_count;not null;default:0"`
+    CancelledAt     *time.Time    `json:"cancelledAt,omitempty" gorm:"column:cancelled_at"`
+    ImportBatchID   *string       `json:"importBatchId,omitempty" gorm:"column:import_batch_id;type:text;index"`
+    Tags            StringList    `json:"tags" gorm:"column:tags;type:jsonb;not null;default:'[]'"`
+    // Locale is the language the customer is emailed in.
+    Locale          string        `json:"locale,omitempty" gorm:"column:locale;type:text"`
+    CreatedAt       time.Time     `json:"createdAt" gorm:"column:created_at;not null;default:CURRENT_TIMESTAMP;index"`
     UpdatedAt       time.Time     `json:"updatedAt" gorm:"column:updated_at;not null;default:CURRENT_TIMESTAMP"`
 }
+
+// StartsAt combines the event date with the start time slot, read as a
+// wall clock time in loc, the venue's time zone.
+func (b *Booking) StartsAt(loc *time.Location) time.Time {
+    start, err := time.Parse("15:04", b.StartTime)
+    if err != nil {
+        return b.EventDate
+    }
+    y, m, d := b.EventDate.Date()
+    return time.Date(y, m, d, start.Hour(), start.Minute(), 0, 0, loc)
+}
+
+// BalanceDue is what the customer still owes: the price and fees less
+// what they have paid. It is negative when they have paid too much.
+func (b *Booking) BalanceDue() float64 {
+    return b.TotalPrice + b.FeeTotal - b.AmountPaid
+}
+
 type BookingRequest struct {
     HallID          string    `json:"hallId" binding:"required"`
     CustomerName    string    `json:"customerName" binding:"required"`
     CustomerEmail   string    `json:"customerEmail" binding:"required,email"`
     CustomerPhone   string    `json:"customerPhone" binding:"required"`
     GuestCount      int       `json:"guestCount" binding:"required,min=1"`
+    Layout          string    `json:"layout"`
     EventDate       time.Time `json:"eventDate" binding:"required"`
     StartTime       string    `json:"startTime" binding:"required"`
     SpecialRequests string    `json:"specialRequests"`
+    // Locale picks the language of the emails, e.g. "hi"; the
+    // Accept-Language header is used when it is not given.
+    Locale          string    `json:"locale"`
+    // Website is a honeypot: the form hides it, so only bots fill it in.
+    Website      string `json:"website"`
+    CaptchaToken string `json:"captchaToken"`
 }
 
 type BookingResponse struct {
     ID            string        `json:"id"`
     Status        BookingStatus `json:"status"`
     BookingDetail Booking       `json:"bookingDetail"`
+    ManageToken   string        `json:"manageToken,omitempty"`
 }
\ No newline at end of file
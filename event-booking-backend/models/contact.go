@@ -2,14 +2,69 @@ package models
 
 import "time"
 
+// Contact statuses. A message from the public opens a ticket; replying
+// leaves it pending on the customer, and it is resolved once dealt with.
+const (
+	ContactOpen     = "open"
+	ContactPending  = "pending"
+	ContactResolved = "resolved"
+)
+
+// Contact is a message sent through the contact form, handled as a
+// lightweight ticket.
 type Contact struct {
-	ID        uint      `json:"id" gorm:"primaryKey"`
-	Name      string    `json:"name" binding:"required"`
-	Email     string    `json:"email" binding:"required,email"`
-	Phone     string    `json:"phone"`
-	Subject   string    `json:"subject" binding:"required"`
-	Message   string    `json:"message" binding:"required"`
-	Status    string    `json:"status" gorm:"default:'unread'"` // unread, read, replied
-	CreatedAt time.Time `json:"created_at"`
-	UpdatedAt time.Time `json:"updated_at"`
+	ID      uint   `json:"id" gorm:"primaryKey"`
+	Name    string `json:"name" binding:"required"`
+	Email   string `json:"email" binding:"required,email"`
+	Phone   string `json:"phone"`
+	Subject string `json:"subject" binding:"required"`
+	Message string `json:"message" binding:"required"`
+	Status  string `json:"status" gorm:"default:'open';index"` // open, pending, resolved
+	// AssigneeID is the staff member handling the ticket.
+	AssigneeID *uint      `json:"assignee_id,omitempty" gorm:"index"`
+	Assignee   *AdminUser `json:"-" gorm:"foreignKey:AssigneeID;constraint:OnDelete:SET NULL"`
+	// BookingID links the ticket to the booking it is about.
+	BookingID *uint64    `json:"booking_id,omitempty,string" gorm:"index"`
+	Booking   *Booking   `json:"-" gorm:"foreignKey:BookingID;constraint:OnDelete:SET NULL"`
+	Tags      StringList `json:"tags" gorm:"type:jsonb;not null;default:'[]'"`
+	// Locale is the language the customer is emailed in.
+	Locale string `json:"locale,omitempty" gorm:"type:text"`
+	// The SLA: the customer should hear back by FirstResponseDueAt and the
+	// ticket be resolved by ResolutionDueAt. FirstResponseAt and
+	// ResolvedAt record when that happened.
+	FirstResponseDueAt *time.Time       `json:"first_response_due_at,omitempty"`
+	FirstResponseAt    *time.Time       `json:"first_response_at,omitempty"`
+	ResolutionDueAt    *time.Time       `json:"resolution_due_at,omitempty"`
+	ResolvedAt         *time.Time       `json:"resolved_at,omitempty"`
+	Messages           []ContactMessage `json:"messages,omitempty" gorm:"foreignKey:ContactID;constraint:OnDelete:CASCADE"`
+	// EmailMessageID is the Message-ID of the email that opened the ticket,
+	// when it came in by email, so a redelivered message is not taken twice.
+	EmailMessageID string    `json:"-" gorm:"type:text;index"`
+	CreatedAt      time.Time `json:"created_at"`
+	UpdatedAt      time.Time `json:"updated_at"`
+}
+
+type ContactMessageKind string
+
+const (
+	// ContactReply is a staff answer emailed to the customer.
+	ContactReply ContactMessageKind = "reply"
+	// ContactNote is an internal note the customer never sees.
+	ContactNote ContactMessageKind = "note"
+	// ContactCustomer is a reply the customer emailed back.
+	ContactCustomer ContactMessageKind = "customer"
+)
+
+// ContactMessage is one entry in a ticket's thread after the original
+// message.
+type ContactMessage struct {
+	ID         uint64             `json:"id,string" gorm:"primaryKey;autoIncrement"`
+	ContactID  uint               `json:"contact_id" gorm:"not null;index"`
+	Kind       ContactMessageKind `json:"kind" gorm:"type:text;not null"`
+	AuthorID   *uint              `json:"author_id,omitempty"`
+	AuthorName string             `json:"author_name" gorm:"type:text"`
+	Body       string             `json:"body" gorm:"type:text;not null"`
+	// EmailMessageID is the Message-ID of a customer reply.
+	EmailMessageID string    `json:"-" gorm:"type:text;index"`
+	CreatedAt      time.Time `json:"created_at"`
 }
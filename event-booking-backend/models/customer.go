@@ -0,0 +1,36 @@
+package models
+
+import "time"
+
+// Customer is a registered customer account. Guest bookings made with the
+// same email are attached once the address is verified.
+type Customer struct {
+	ID              uint       `json:"id" gorm:"primaryKey"`
+	Email           string     `json:"email" gorm:"type:text;not null;uniqueIndex"`
+	PasswordHash    string     `json:"-" gorm:"type:text;not null"`
+	Name            string     `json:"name" gorm:"type:text;not null"`
+	Phone           string     `json:"phone" gorm:"type:text"`
+	Locale          string     `json:"locale,omitempty" gorm:"type:text"`
+	EmailVerifiedAt *time.Time `json:"emailVerifiedAt,omitempty"`
+	CreatedAt       time.Time  `json:"createdAt" gorm:"autoCreateTime"`
+	UpdatedAt       time.Time  `json:"updatedAt" gorm:"autoUpdateTime"`
+}
+
+type TokenPurpose string
+
+const (
+	TokenEmailVerification TokenPurpose = "email_verification"
+	TokenPasswordReset     TokenPurpose = "password_reset"
+)
+
+// CustomerToken is a single-use token emailed to a customer. Only the hash
+// of the token is stored.
+type CustomerToken struct {
+	ID         uint         `gorm:"primaryKey"`
+	CustomerID uint         `gorm:"not null;index"`
+	Purpose    TokenPurpose `gorm:"type:text;not null"`
+	TokenHash  string       `gorm:"type:text;not null;uniqueIndex"`
+	ExpiresAt  time.Time    `gorm:"not null"`
+	UsedAt     *time.Time
+	CreatedAt  time.Time `gorm:"autoCreateTime"`
+}
@@ -1,15 +1,73 @@
 package models
 
-import "time"
+import (
+	"errors"
+	"time"
+)
+
+type HallStatus string
+
+const (
+	HallActive HallStatus = "active"
+	// HallInactive halls are closed for a while, until InactiveUntil or
+	// until reactivated by hand.
+	HallInactive HallStatus = "inactive"
+	// HallArchived halls are retired. They stay in the table so past
+	// bookings keep their hall.
+	HallArchived HallStatus = "archived"
+)
 
 type Hall struct {
-	ID          string    `json:"id" gorm:"primaryKey"`
-	Name        string    `json:"name" gorm:"not null"`
-	Capacity    int       `json:"capacity" gorm:"not null"`
-	BasePrice   float64   `json:"basePrice" gorm:"not null"`
-	WeekendRate float64   `json:"weekendRate" gorm:"not null;default:0"`
-	PeakRate    float64   `json:"peakRate" gorm:"not null;default:0"`
-	Features    string    `json:"features" gorm:"type:text"`
-	CreatedAt   time.Time `json:"createdAt" gorm:"autoCreateTime"`
-	UpdatedAt   time.Time `json:"updatedAt" gorm:"autoUpdateTime"`
+	ID             string       `json:"id" gorm:"primaryKey"`
+	Name           string       `json:"name" gorm:"not null"`
+	Capacity       int          `json:"capacity" gorm:"not null"`
+	BasePrice      float64      `json:"basePrice" gorm:"not null"`
+	WeekendRate    float64      `json:"weekendRate" gorm:"not null;default:0"`
+	PeakRate       float64      `json:"peakRate" gorm:"not null;default:0"`
+	OpensAt        string       `json:"opensAt" gorm:"type:text;not null;default:'09:00'"`
+	ClosesAt       string       `json:"closesAt" gorm:"type:text;not null;default:'23:00'"`
+	Status         HallStatus   `json:"status" gorm:"type:text;not null;default:'active';index"`
+	InactiveUntil  *time.Time   `json:"inactiveUntil,omitempty"`
+	InactiveReason string       `json:"inactiveReason,omitempty" gorm:"type:text"`
+	ArchivedAt     *time.Time   `json:"archivedAt,omitempty"`
+	Media          []HallMedia  `json:"media,omitempty" gorm:"foreignKey:HallID;constraint:OnDelete:CASCADE"`
+	Amenities      []Amenity    `json:"amenities,omitempty" gorm:"many2many:hall_amenities;constraint:OnDelete:CASCADE"`
+	Layouts        []HallLayout `json:"layouts,omitempty" gorm:"foreignKey:HallID;constraint:OnDelete:CASCADE"`
+	CreatedAt      time.Time    `json:"createdAt" gorm:"autoCreateTime"`
+	UpdatedAt      time.Time    `json:"updatedAt" gorm:"autoUpdateTime"`
+}
+
+// OpenHours returns how many hours a day the hall is open. OpensAt and
+// ClosesAt are HH:MM in the venue's time zone; closing at 00:00 means open
+// until midnight.
+func (h *Hall) OpenHours() (float64, error) {
+	opens, err := time.Parse("15:04", h.OpensAt)
+	if err != nil {
+		return 0, errors.New("opensAt must be in HH:MM format")
+	}
+	closes, err := time.Parse("15:04", h.ClosesAt)
+	if err != nil {
+		return 0, errors.New("closesAt must be in HH:MM format")
+	}
+	if closes.Hour() == 0 && closes.Minute() == 0 {
+		closes = closes.Add(24 * time.Hour)
+	}
+	if !closes.After(opens) {
+		return 0, errors.New("closesAt must be after opensAt")
+	}
+	return closes.Sub(opens).Hours(), nil
+}
+
+// AcceptsBookings reports whether the hall takes a booking for an event
+// beginning at start. An inactive hall with an end date is open again from
+// then, so later events can be booked while it is still closed.
+func (h *Hall) AcceptsBookings(start time.Time) bool {
+	switch h.Status {
+	case HallActive:
+		return true
+	case HallInactive:
+		return h.InactiveUntil != nil && !start.Before(*h.InactiveUntil)
+	default:
+		return false
+	}
 }
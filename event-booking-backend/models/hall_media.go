@@ -0,0 +1,55 @@
+package models
+
+import "time"
+
+type MediaKind string
+
+const (
+	MediaImage MediaKind = "image"
+	MediaVideo MediaKind = "video"
+)
+
+// HallMedia is a photo or video in a hall's gallery. Files live in media
+// storage; the row keeps their storage keys, and the URLs are filled in
+// for each response so a change of storage host needs no migration.
+type HallMedia struct {
+	ID          string    `json:"id" gorm:"primaryKey;type:text"`
+	HallID      string    `json:"hallId" gorm:"type:text;not null;index"`
+	Kind        MediaKind `json:"kind" gorm:"type:text;not null"`
+	Caption     string    `json:"caption" gorm:"type:text"`
+	Position    int       `json:"position" gorm:"not null;default:0"`
+	ContentType string    `json:"contentType" gorm:"type:text;not null"`
+	Size        int64     `json:"size" gorm:"not null"`
+	Width       int       `json:"width,omitempty"`
+	Height      int       `json:"height,omitempty"`
+	StorageKey  string    `json:"-" gorm:"type:text;not null"`
+	// VariantKeys maps each resized copy (thumb, display) to its key.
+	VariantKeys StringMap `json:"-" gorm:"type:jsonb;not null;default:'{}'"`
+	CreatedAt   time.Time `json:"createdAt" gorm:"autoCreateTime"`
+	UpdatedAt   time.Time `json:"updatedAt" gorm:"autoUpdateTime"`
+
+	URL      string            `json:"url" gorm:"-"`
+	Variants map[string]string `json:"variants,omitempty" gorm:"-"`
+}
+
+// Keys returns every storage key the media item uses.
+func (m *HallMedia) Keys() []string {
+	keys := []string{m.StorageKey}
+	for _, k := range m.VariantKeys {
+		keys = append(keys, k)
+	}
+	return keys
+}
+
+// ResolveURLs fills in URL and Variants using urlFor to turn storage keys
+// into public addresses.
+func (m *HallMedia) ResolveURLs(urlFor func(key string) string) {
+	m.URL = urlFor(m.StorageKey)
+	if len(m.VariantKeys) == 0 {
+		return
+	}
+	m.Variants = make(map[string]string, len(m.VariantKeys))
+	for name, key := range m.VariantKeys {
+		m.Variants[name] = urlFor(key)
+	}
+}
@@ -0,0 +1,36 @@
+package models
+
+import "time"
+
+type HistoryAction string
+
+const (
+	HistoryRescheduled HistoryAction = "rescheduled"
+	HistoryCancelled   HistoryAction = "cancelled"
+	HistoryPaid        HistoryAction = "payment_recorded"
+)
+
+// BookingHistory is one entry in a booking's change log. AmountPaid is
+// what the customer had paid once the change was made, and BalanceDue what
+// they still owe against it: the price and fees less the amount paid. A
+// negative balance is owed back to them.
+type BookingHistory struct {
+	ID            uint64        `json:"id,string" gorm:"primaryKey;autoIncrement"`
+	BookingID     uint64        `json:"bookingId,string" gorm:"not null;index"`
+	Action        HistoryAction `json:"action" gorm:"type:text;not null"`
+	Actor         string        `json:"actor" gorm:"type:text;not null"` // customer or admin
+	FromHallID    string        `json:"fromHallId,omitempty" gorm:"type:text"`
+	FromDate      *time.Time    `json:"fromDate,omitempty"`
+	FromStartTime string        `json:"fromStartTime,omitempty" gorm:"type:text"`
+	ToHallID      string        `json:"toHallId,omitempty" gorm:"type:text"`
+	ToDate        *time.Time    `json:"toDate,omitempty"`
+	ToStartTime   string        `json:"toStartTime,omitempty" gorm:"type:text"`
+	PreviousPrice float64       `json:"previousPrice"`
+	NewPrice      float64       `json:"newPrice"`
+	PriceChange   float64       `json:"priceChange" gorm:"column:price_difference"` // of the quoted price
+	Fee           float64       `json:"fee"`
+	AmountPaid    float64       `json:"amountPaid"`
+	BalanceDue    float64       `json:"balanceDue"`
+	Note          string        `json:"note,omitempty" gorm:"type:text"`
+	CreatedAt     time.Time     `json:"createdAt" gorm:"autoCreateTime"`
+}
@@ -0,0 +1,24 @@
+package models
+
+import "time"
+
+type ImportBatchStatus string
+
+const (
+	ImportBatchImported   ImportBatchStatus = "imported"
+	ImportBatchRolledBack ImportBatchStatus = "rolled_back"
+)
+
+// ImportBatch groups the bookings created by one bulk import so the whole
+// import can be rolled back.
+type ImportBatch struct {
+	ID           string            `json:"id" gorm:"type:text;primaryKey"`
+	Filename     string            `json:"filename" gorm:"type:text"`
+	Source       string            `json:"source" gorm:"type:text;not null"` // api or cli
+	ActorID      *uint             `json:"actorId,omitempty"`
+	Status       ImportBatchStatus `json:"status" gorm:"type:text;not null;default:'imported'"`
+	RowCount     int               `json:"rowCount" gorm:"not null;default:0"`
+	SkippedCount int               `json:"skippedCount" gorm:"not null;default:0"`
+	RolledBackAt *time.Time        `json:"rolledBackAt,omitempty"`
+	CreatedAt    time.Time         `json:"createdAt" gorm:"autoCreateTime"`
+}
@@ -0,0 +1,31 @@
+package models
+
+import "time"
+
+type OutboxStatus string
+
+const (
+	OutboxPending OutboxStatus = "pending"
+	OutboxSent    OutboxStatus = "sent"
+	// OutboxDead messages ran out of attempts; staff can resend them.
+	OutboxDead OutboxStatus = "dead"
+)
+
+// OutboxMessage is an email waiting to be sent, or the record of one that
+// was. It is written in the same transaction as the change it reports, so
+// a committed change always gets its email, even across restarts.
+type OutboxMessage struct {
+	ID        uint64 `json:"id,string" gorm:"primaryKey;autoIncrement"`
+	Template  string `json:"template" gorm:"type:text;not null;index"`
+	Recipient string `json:"recipient" gorm:"type:text;not null;index"`
+	Subject   string `json:"subject" gorm:"type:text"`
+	// Payload is the whole message as JSON.
+	Payload       string       `json:"-" gorm:"type:jsonb;not null"`
+	Status        OutboxStatus `json:"status" gorm:"type:text;not null;default:'pending';index:idx_outbox_due,priority:1"`
+	Attempts      int          `json:"attempts" gorm:"not null;default:0"`
+	NextAttemptAt time.Time    `json:"nextAttemptAt" gorm:"not null;index:idx_outbox_due,priority:2"`
+	LastError     string       `json:"lastError,omitempty" gorm:"type:text"`
+	SentAt        *time.Time   `json:"sentAt,omitempty"`
+	CreatedAt     time.Time    `json:"createdAt" gorm:"index"`
+	UpdatedAt     time.Time    `json:"updatedAt"`
+}
@@ -0,0 +1,23 @@
+package models
+
+import "time"
+
+type RescheduleRequestStatus string
+
+const (
+	RescheduleRequestOpen     RescheduleRequestStatus = "open"
+	RescheduleRequestResolved RescheduleRequestStatus = "resolved"
+)
+
+// RescheduleRequest records a customer's wish to move a booking to another
+// slot, for staff to follow up on.
+type RescheduleRequest struct {
+	ID                 uint64                  `json:"id,string" gorm:"primaryKey;autoIncrement"`
+	BookingID          uint64                  `json:"bookingId,string" gorm:"not null;index"`
+	RequestedDate      time.Time               `json:"requestedDate" gorm:"not null"`
+	RequestedStartTime string                  `json:"requestedStartTime" gorm:"type:text;not null"`
+	Note               string                  `json:"note" gorm:"type:text"`
+	Status             RescheduleRequestStatus `json:"status" gorm:"type:text;not null;default:'open'"`
+	CreatedAt          time.Time               `json:"createdAt" gorm:"autoCreateTime"`
+	UpdatedAt          time.Time               `json:"updatedAt" gorm:"autoUpdateTime"`
+}
@@ -0,0 +1,31 @@
+package models
+
+import "time"
+
+// LoginThrottle tracks failed sign-in attempts for one account or client
+// IP. Keys look like "admin:<email>", "customer:<email>" or "ip:<address>".
+type LoginThrottle struct {
+	Key           string     `json:"key" gorm:"type:text;primaryKey"`
+	Failures      int        `json:"failures" gorm:"not null;default:0"`
+	LastFailureAt time.Time  `json:"lastFailureAt"`
+	LockedUntil   *time.Time `json:"lockedUntil,omitempty"`
+	UpdatedAt     time.Time  `json:"updatedAt" gorm:"autoUpdateTime"`
+}
+
+type SecurityEventType string
+
+const (
+	SecurityEventLockout SecurityEventType = "lockout"
+	SecurityEventUnlock  SecurityEventType = "unlock"
+)
+
+// SecurityEvent records lockouts and unlocks for review.
+type SecurityEvent struct {
+	ID        uint64            `json:"id,string" gorm:"primaryKey;autoIncrement"`
+	Type      SecurityEventType `json:"type" gorm:"type:text;not null;index"`
+	Key       string            `json:"key" gorm:"type:text;not null;index"`
+	IP        string            `json:"ip" gorm:"type:text"`
+	ActorID   *uint             `json:"actorId,omitempty"` // admin user who unlocked
+	Details   string            `json:"details" gorm:"type:text"`
+	CreatedAt time.Time         `json:"createdAt" gorm:"autoCreateTime;index"`
+}
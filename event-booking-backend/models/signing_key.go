@@ -0,0 +1,17 @@
+package models
+
+import "time"
+
+// SigningKey is a JWT signing key shared by every server instance. The
+// private key is stored encrypted; the public key is published in the JWKS.
+type SigningKey struct {
+	KID        string    `json:"kid" gorm:"type:text;primaryKey"`
+	Algorithm  string    `json:"algorithm" gorm:"type:text;not null"`
+	PrivateKey []byte    `json:"-" gorm:"not null"`
+	PublicKey  []byte    `json:"-" gorm:"not null"`
+	CreatedAt  time.Time `json:"createdAt" gorm:"autoCreateTime"`
+	// RetiredAt is when the key stopped signing new tokens.
+	RetiredAt *time.Time `json:"retiredAt,omitempty"`
+	// ExpiresAt is when tokens signed by the key stop being accepted.
+	ExpiresAt *time.Time `json:"expiresAt,omitempty"`
+}
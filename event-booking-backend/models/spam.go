@@ -0,0 +1,44 @@
+package models
+
+import "time"
+
+// RateLimitCounter counts public submissions from one client in the
+// current window. Keys look like "booking:ip:<address>" or
+// "contact:email:<address>".
+type RateLimitCounter struct {
+	Key         string    `json:"key" gorm:"type:text;primaryKey"`
+	Count       int       `json:"count" gorm:"not null;default:0"`
+	WindowStart time.Time `json:"windowStart" gorm:"not null;index"`
+}
+
+type ReviewKind string
+
+const (
+	ReviewBooking ReviewKind = "booking"
+	ReviewContact ReviewKind = "contact"
+)
+
+type ReviewStatus string
+
+const (
+	ReviewPending  ReviewStatus = "pending"
+	ReviewApproved ReviewStatus = "approved"
+	ReviewRejected ReviewStatus = "rejected"
+)
+
+// SubmissionReview holds a booking or contact message from the public
+// that looked suspicious. It is saved as usual, but the customer is not
+// emailed until staff approve it; rejecting it cancels the booking or
+// closes the ticket.
+type SubmissionReview struct {
+	ID         uint64       `json:"id,string" gorm:"primaryKey;autoIncrement"`
+	Kind       ReviewKind   `json:"kind" gorm:"type:text;not null;index:idx_review_entity"`
+	EntityID   string       `json:"entityId" gorm:"type:text;not null;index:idx_review_entity"`
+	IP         string       `json:"ip" gorm:"type:text"`
+	Email      string       `json:"email" gorm:"type:text"`
+	Reasons    StringList   `json:"reasons" gorm:"type:jsonb;not null;default:'[]'"`
+	Status     ReviewStatus `json:"status" gorm:"type:text;not null;default:'pending';index"`
+	ReviewedBy *uint        `json:"reviewedBy,omitempty"`
+	ReviewedAt *time.Time   `json:"reviewedAt,omitempty"`
+	CreatedAt  time.Time    `json:"createdAt" gorm:"autoCreateTime;index"`
+}
@@ -0,0 +1,68 @@
+package services
+
+import (
+	"bytes"
+	"fmt"
+	"html/template"
+	"strings"
+	"time"
+
+	"event-booking-backend/models"
+)
+
+var confirmationTemplate = template.Must(template.New("confirmation").Parse(`<!DOCTYPE html>
+<html>
+<head><meta charset="UTF-8"><title>Booking #{{.Booking.ID}}</title></head>
+<body>
+<h2>Booking Confirmation</h2>
+<p>Dear {{.Booking.CustomerName}},</p>
+<table>
+<tr><th align="left">Booking</th><td>#{{.Booking.ID}}</td></tr>
+<tr><th align="left">Status</th><td>{{.Booking.Status}}</td></tr>
+<tr><th align="left">Hall</th><td>{{.HallName}}</td></tr>
+<tr><th align="left">Date</th><td>{{.Booking.EventDate.Format "January 2, 2006"}}</td></tr>
+<tr><th align="left">Time</th><td>{{.Booking.StartTime}} - {{.Booking.EndTime}}</td></tr>
+<tr><th align="left">Guests</th><td>{{.Booking.GuestCount}}</td></tr>
+<tr><th align="left">Total</th><td>{{printf "%.2f" .Booking.TotalPrice}}</td></tr>
+{{if .Booking.SpecialRequests}}<tr><th align="left">Special requests</th><td>{{.Booking.SpecialRequests}}</td></tr>{{end}}
+</table>
+</body>
+</html>
+`))
+
+// BookingConfirmationHTML renders a printable confirmation for the booking.
+func BookingConfirmationHTML(booking *models.Booking, hall *models.Hall) ([]byte, error) {
+	var buf bytes.Buffer
+	err := confirmationTemplate.Execute(&buf, struct {
+		Booking  *models.Booking
+		HallName string
+	}{booking, hall.Name})
+	return buf.Bytes(), err
+}
+
+// BookingCalendarICS renders the booking as an iCalendar event.
+func BookingCalendarICS(booking *models.Booking, hall *models.Hall) []byte {
+	const stamp = "20060102T150405"
+	start := booking.StartsAt(VenueLocation())
+	end := start.Add(2 * time.Hour)
+
+	lines := []string{
+		"BEGIN:VCALENDAR",
+		"VERSION:2.0",
+		"PRODID:-//Event Booking System//EN",
+		"BEGIN:VEVENT",
+		fmt.Sprintf("UID:booking-%d@event-booking", booking.ID),
+		"DTSTAMP:" + time.Now().UTC().Format(stamp) + "Z",
+		"DTSTART:" + start.Format(stamp),
+		"DTEND:" + end.Format(stamp),
+		"SUMMARY:" + icsEscape("Event at "+hall.Name),
+		"LOCATION:" + icsEscape(hall.Name),
+		"END:VEVENT",
+		"END:VCALENDAR",
+	}
+	return []byte(strings.Join(lines, "\r\n") + "\r\n")
+}
+
+func icsEscape(s string) string {
+	return strings.NewReplacer(`\`, `\\`, ";", `\;`, ",", `\,`, "\n", `\n`).Replace(s)
+}
@@ -0,0 +1,251 @@
+package services
+
+import (
+	"bytes"
+	"embed"
+	"errors"
+	"fmt"
+	htmltemplate "html/template"
+	"io/fs"
+	"regexp"
+	"sort"
+	"strings"
+	texttemplate "text/template"
+	"time"
+
+	"event-booking-backend/models"
+)
+
+// The email templates live in emails/. layout.html and layout.txt wrap
+// every message; each locale has a directory holding common.html and
+// common.txt (the footer and booking details) and, per template, a .html
+// file defining "heading" and "content" and a .txt file defining "subject"
+// and "content". A locale without its own copy of a template uses the
+// English one.
+//
+//go:embed emails
+var emailFiles embed.FS
+
+// DefaultLocale is used when a message has no locale or one we have no
+// templates for.
+const DefaultLocale = "en"
+
+// Locales are the languages email can be sent in.
+var Locales = []string{"en", "hi", "ta"}
+
+var ErrUnknownEmailTemplate = errors.New("unknown email template")
+
+// EmailTemplates are the templates the Mailer renders.
+var EmailTemplates = []string{
+	TemplateBookingConfirmation,
+	TemplateAdminNewBooking,
+	TemplateBookingCancelled,
+	TemplateRescheduleRequest,
+	TemplateBookingRescheduled,
+	TemplateEmailVerification,
+	TemplatePasswordReset,
+	TemplateBookingConfirmed,
+	TemplateContactAcknowledgment,
+	TemplateContactReply,
+}
+
+// emailTemplate is one template in one locale.
+type emailTemplate struct {
+	html *htmltemplate.Template
+	text *texttemplate.Template
+}
+
+// emailTemplates holds every template by locale, then name. It is parsed
+// at start-up, so a broken template stops the server rather than a send.
+var emailTemplates = mustParseEmailTemplates()
+
+// emailData is what the templates are executed with. Only the fields the
+// template needs are set.
+type emailData struct {
+	Locale string
+	// Name is who the message greets.
+	Name    string
+	Booking *models.Booking
+	// HallName is the name of the booking's hall.
+	HallName string
+	// Refund is the amount refunded on cancellation, when one was decided.
+	Refund   *float64
+	Request  *models.RescheduleRequest
+	Contact  *models.Contact
+	Link     string
+	Subject  string
+	Reply    string
+	CanReply bool
+}
+
+// monthNames are the months in the locales whose names Go does not know.
+var monthNames = map[string][12]string{
+	"hi": {"जनवरी", "फ़रवरी", "मार्च", "अप्रैल", "मई", "जून", "जुलाई", "अगस्त", "सितंबर", "अक्टूबर", "नवंबर", "दिसंबर"},
+	"ta": {"ஜனவரி", "பிப்ரவரி", "மார்ச்", "ஏப்ரல்", "மே", "ஜூன்", "ஜூலை", "ஆகஸ்ட்", "செப்டம்பர்", "அக்டோபர்", "நவம்பர்", "டிசம்பர்"},
+}
+
+// emailFuncs are the functions the templates of locale may call.
+func emailFuncs(locale string) map[string]interface{} {
+	return map[string]interface{}{
+		"date": func(t time.Time) string {
+			if names, ok := monthNames[locale]; ok {
+				return fmt.Sprintf("%d %s %d", t.Day(), names[t.Month()-1], t.Year())
+			}
+			return t.Format("January 2, 2006")
+		},
+		"money": func(amount float64) string {
+			return fmt.Sprintf("%.2f", amount)
+		},
+	}
+}
+
+func mustParseEmailTemplates() map[string]map[string]emailTemplate {
+	templates := map[string]map[string]emailTemplate{}
+	for _, locale := range Locales {
+		templates[locale] = map[string]emailTemplate{}
+		for _, name := range EmailTemplates {
+			file := "emails/" + locale + "/" + name
+			if _, err := fs.Stat(emailFiles, file+".html"); err != nil {
+				if locale == DefaultLocale {
+					panic(fmt.Sprintf("email template %s is missing", file))
+				}
+				continue
+			}
+			html, err := htmltemplate.New(name).Funcs(emailFuncs(locale)).
+				ParseFS(emailFiles, "emails/layout.html", "emails/"+locale+"/common.html", file+".html")
+			if err != nil {
+				panic(err)
+			}
+			text, err := texttemplate.New(name).Funcs(emailFuncs(locale)).
+				ParseFS(emailFiles, "emails/layout.txt", "emails/"+locale+"/common.txt", file+".txt")
+			if err != nil {
+				panic(err)
+			}
+			templates[locale][name] = emailTemplate{html: html, text: text}
+		}
+	}
+	return templates
+}
+
+// MatchLocale picks the supported locale for a language tag or an
+// Accept-Language header, e.g. "ta-IN" or "hi;q=0.9, en;q=0.8". It returns
+// "" when none of them is supported.
+func MatchLocale(value string) string {
+	type choice struct {
+		locale string
+		q      float64
+	}
+	var choices []choice
+	for _, part := range strings.Split(value, ",") {
+		tag, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		tag = strings.ToLower(strings.TrimSpace(tag))
+		tag, _, _ = strings.Cut(tag, "-")
+		q := 1.0
+		if v, ok := strings.CutPrefix(strings.TrimSpace(params), "q="); ok {
+			if _, err := fmt.Sscanf(v, "%g", &q); err != nil {
+				continue
+			}
+		}
+		for _, locale := range Locales {
+			if tag == locale && q > 0 {
+				choices = append(choices, choice{locale, q})
+			}
+		}
+	}
+	if len(choices) == 0 {
+		return ""
+	}
+	sort.SliceStable(choices, func(i, j int) bool { return choices[i].q > choices[j].q })
+	return choices[0].locale
+}
+
+// blankLines are runs of empty lines in a rendered text part.
+var blankLines = regexp.MustCompile(`\n(?:[ \t]*\n){2,}`)
+
+// renderEmail renders template name in data.Locale, or in English when
+// that locale has no copy of it. The message has no recipients yet.
+func renderEmail(name string, data emailData) (*Message, error) {
+	tmpl, ok := emailTemplates[data.Locale][name]
+	if !ok {
+		data.Locale = DefaultLocale
+		if tmpl, ok = emailTemplates[DefaultLocale][name]; !ok {
+			return nil, ErrUnknownEmailTemplate
+		}
+	}
+	msg := &Message{Template: name, Locale: data.Locale}
+
+	var buf bytes.Buffer
+	if err := tmpl.text.ExecuteTemplate(&buf, "subject", data); err != nil {
+		return nil, err
+	}
+	msg.Subject = strings.Join(strings.Fields(buf.String()), " ")
+
+	buf.Reset()
+	if err := tmpl.text.ExecuteTemplate(&buf, "layout", data); err != nil {
+		return nil, err
+	}
+	msg.Text = strings.TrimSpace(blankLines.ReplaceAllString(buf.String(), "\n\n")) + "\n"
+
+	buf.Reset()
+	if err := tmpl.html.ExecuteTemplate(&buf, "layout", data); err != nil {
+		return nil, err
+	}
+	msg.HTML = buf.String()
+	return msg, nil
+}
+
+// PreviewEmail renders template name in locale with sample data, so staff
+// can see a message without sending it.
+func PreviewEmail(name, locale string) (*Message, error) {
+	eventDate := time.Now().AddDate(0, 1, 0).Truncate(24 * time.Hour)
+	booking := &models.Booking{
+		ID:              1024,
+		HallID:          "grand-ballroom",
+		CustomerName:    "Priya Raman",
+		CustomerEmail:   "priya@example.com",
+		CustomerPhone:   "+91 98765 43210",
+		GuestCount:      150,
+		EventDate:       eventDate,
+		StartTime:       "18:00",
+		EndTime:         "20:00",
+		SpecialRequests: "Vegetarian menu, stage for a live band",
+		Status:          models.StatusConfirmed,
+		TotalPrice:      85000,
+		RefundAmount:    42500,
+	}
+	data := emailData{
+		Locale:   locale,
+		Name:     booking.CustomerName,
+		Booking:  booking,
+		HallName: "Grand Ballroom",
+		Refund:   &booking.RefundAmount,
+		Link:     FrontendURL() + "/preview",
+		CanReply: true,
+	}
+	switch name {
+	case TemplateAdminNewBooking, TemplateRescheduleRequest:
+		data.Name, data.CanReply = "", false
+		data.Request = &models.RescheduleRequest{
+			RequestedDate:      eventDate.AddDate(0, 0, 7),
+			RequestedStartTime: "19:00",
+			Note:               "Our guests arrive a week later than planned.",
+		}
+	case TemplateContactAcknowledgment, TemplateContactReply:
+		data.Contact = &models.Contact{
+			ID:      311,
+			Name:    booking.CustomerName,
+			Email:   booking.CustomerEmail,
+			Subject: "Parking for guests",
+			Message: "Is there parking for 40 cars?",
+		}
+		data.Subject = "Re: Parking for guests"
+		data.Reply = "Yes, the venue has parking for 60 cars.\nValet service is available on request."
+	}
+
+	msg, err := renderEmail(name, data)
+	if err != nil {
+		return nil, err
+	}
+	msg.To = []Address{{Email: booking.CustomerEmail, Name: booking.CustomerName}}
+	return msg, nil
+}
@@ -0,0 +1,103 @@
+package services
+
+import (
+	"errors"
+	"io/fs"
+	"strings"
+	"testing"
+
+	"event-booking-backend/models"
+)
+
+func TestEveryEmailTemplateRenders(t *testing.T) {
+	for _, locale := range Locales {
+		for _, name := range EmailTemplates {
+			msg, err := PreviewEmail(name, locale)
+			if err != nil {
+				t.Errorf("%s/%s: %v", locale, name, err)
+				continue
+			}
+			want := locale
+			if _, err := fs.Stat(emailFiles, "emails/"+locale+"/"+name+".html"); err != nil {
+				want = DefaultLocale
+			}
+			if msg.Locale != want {
+				t.Errorf("%s/%s rendered in %s, want %s", locale, name, msg.Locale, want)
+			}
+			if msg.Subject == "" || strings.TrimSpace(msg.Text) == "" || !strings.Contains(msg.HTML, "</html>") {
+				t.Errorf("%s/%s rendered incomplete: subject %q", locale, name, msg.Subject)
+			}
+			for _, part := range []string{msg.Subject, msg.Text, msg.HTML} {
+				if strings.Contains(part, "<no value>") || strings.Contains(part, "%!") {
+					t.Errorf("%s/%s has a missing value:\n%s", locale, name, part)
+				}
+			}
+		}
+	}
+}
+
+func TestEmailEscapesCustomerName(t *testing.T) {
+	name := `<script>alert("x")</script> & Sons`
+	booking := &models.Booking{ID: 7, CustomerName: name, StartTime: "10:00", EndTime: "12:00"}
+	for _, locale := range Locales {
+		msg, err := renderEmail(TemplateBookingConfirmation, emailData{Locale: locale, Name: name, Booking: booking, HallName: "<b>Garden</b>"})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if strings.Contains(msg.HTML, "<script>") || strings.Contains(msg.HTML, "<b>Garden") {
+			t.Errorf("%s: HTML part carries the markup unescaped:\n%s", locale, msg.HTML)
+		}
+		if !strings.Contains(msg.HTML, "&lt;script&gt;") || !strings.Contains(msg.HTML, "&amp; Sons") {
+			t.Errorf("%s: HTML part lost the escaped name:\n%s", locale, msg.HTML)
+		}
+		if !strings.Contains(msg.Text, name) {
+			t.Errorf("%s: text part changed the name:\n%s", locale, msg.Text)
+		}
+	}
+}
+
+func TestEmailFallsBackToEnglish(t *testing.T) {
+	tests := []struct {
+		name, locale string
+	}{
+		{TemplateAdminNewBooking, "hi"},
+		{TemplateRescheduleRequest, "ta"},
+		{TemplateBookingConfirmation, "fr"},
+		{TemplateBookingConfirmation, ""},
+	}
+	english, err := PreviewEmail(TemplateBookingConfirmation, DefaultLocale)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, tt := range tests {
+		msg, err := PreviewEmail(tt.name, tt.locale)
+		if err != nil {
+			t.Fatalf("%s in %q: %v", tt.name, tt.locale, err)
+		}
+		if msg.Locale != DefaultLocale {
+			t.Errorf("%s in %q rendered in %s, want %s", tt.name, tt.locale, msg.Locale, DefaultLocale)
+		}
+		if tt.name == TemplateBookingConfirmation && msg.Subject != english.Subject {
+			t.Errorf("%s in %q has subject %q, want %q", tt.name, tt.locale, msg.Subject, english.Subject)
+		}
+	}
+	if _, err := PreviewEmail("no_such_template", "hi"); !errors.Is(err, ErrUnknownEmailTemplate) {
+		t.Errorf("unknown template: %v, want ErrUnknownEmailTemplate", err)
+	}
+}
+
+func TestMatchLocale(t *testing.T) {
+	tests := map[string]string{
+		"ta-IN":                 "ta",
+		"hi;q=0.9, en;q=0.8":    "hi",
+		"fr, en;q=0.5, ta;q=.7": "ta",
+		"fr-FR":                 "",
+		"hi;q=0":                "",
+		"":                      "",
+	}
+	for value, want := range tests {
+		if got := MatchLocale(value); got != want {
+			t.Errorf("MatchLocale(%q) = %q, want %q", value, got, want)
+		}
+	}
+}
@@ -0,0 +1,201 @@
+package services
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/xuri/excelize/v2"
+)
+
+// Export formats.
+const (
+	ExportCSV  = "csv"
+	ExportXLSX = "xlsx"
+)
+
+// csvFlushEvery is how many rows the CSV writer buffers before flushing to
+// the client.
+const csvFlushEvery = 200
+
+// TableWriter writes a tabular export one row at a time, so exports never
+// hold the whole result in memory. Cells may be strings, numbers, bools,
+// time.Time or nil.
+type TableWriter interface {
+	WriteHeader(names ...string) error
+	WriteRow(values ...interface{}) error
+	// Close finishes the file. Nothing is complete before it returns.
+	Close() error
+}
+
+// ExportContentType returns the MIME type of format.
+func ExportContentType(format string) string {
+	if format == ExportXLSX {
+		return "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet"
+	}
+	return "text/csv; charset=utf-8"
+}
+
+// NewTableWriter returns a writer for format streaming to w. sheet names
+// the worksheet in XLSX files.
+func NewTableWriter(format string, w io.Writer, sheet string) (TableWriter, error) {
+	switch format {
+	case ExportCSV:
+		return &csvTableWriter{w: csv.NewWriter(w)}, nil
+	case ExportXLSX:
+		return newXLSXTableWriter(w, sheet)
+	default:
+		return nil, fmt.Errorf("unsupported export format %q", format)
+	}
+}
+
+// CSVSafe stops spreadsheet apps from treating user-supplied text as a
+// formula.
+func CSVSafe(s string) string {
+	if s != "" && strings.ContainsRune("=+-@\t\r", rune(s[0])) {
+		return "'" + s
+	}
+	return s
+}
+
+type csvTableWriter struct {
+	w    *csv.Writer
+	rows int
+}
+
+func (t *csvTableWriter) WriteHeader(names ...string) error {
+	return t.w.Write(names)
+}
+
+func (t *csvTableWriter) WriteRow(values ...interface{}) error {
+	record := make([]string, len(values))
+	for i, v := range values {
+		switch v := v.(type) {
+		case nil:
+		case string:
+			record[i] = CSVSafe(v)
+		case float64:
+			record[i] = strconv.FormatFloat(v, 'f', 2, 64)
+		case time.Time:
+			if !v.IsZero() {
+				record[i] = v.UTC().Format(time.RFC3339)
+			}
+		case *time.Time:
+			if v != nil {
+				record[i] = v.UTC().Format(time.RFC3339)
+			}
+		default:
+			record[i] = fmt.Sprint(v)
+		}
+	}
+	if err := t.w.Write(record); err != nil {
+		return err
+	}
+	t.rows++
+	if t.rows%csvFlushEvery == 0 {
+		t.w.Flush()
+		return t.w.Error()
+	}
+	return nil
+}
+
+func (t *csvTableWriter) Close() error {
+	t.w.Flush()
+	return t.w.Error()
+}
+
+// xlsxTableWriter uses excelize's stream writer, which spools rows to a
+// temporary file instead of building the sheet in memory.
+type xlsxTableWriter struct {
+	out         io.Writer
+	file        *excelize.File
+	stream      *excelize.StreamWriter
+	row         int
+	headerStyle int
+	timeStyle   int
+}
+
+func newXLSXTableWriter(w io.Writer, sheet string) (*xlsxTableWriter, error) {
+	f := excelize.NewFile()
+	if err := f.SetSheetName("Sheet1", sheet); err != nil {
+		f.Close()
+		return nil, err
+	}
+	stream, err := f.NewStreamWriter(sheet)
+	if err != nil {
+		f.Close()
+		return nil, err
+	}
+	headerStyle, err := f.NewStyle(&excelize.Style{Font: &excelize.Font{Bold: true}})
+	if err != nil {
+		f.Close()
+		return nil, err
+	}
+	timeStyle, err := f.NewStyle(&excelize.Style{NumFmt: 22})
+	if err != nil {
+		f.Close()
+		return nil, err
+	}
+	return &xlsxTableWriter{
+		out:         w,
+		file:        f,
+		stream:      stream,
+		headerStyle: headerStyle,
+		timeStyle:   timeStyle,
+	}, nil
+}
+
+func (t *xlsxTableWriter) WriteHeader(names ...string) error {
+	values := make([]interface{}, len(names))
+	for i, name := range names {
+		values[i] = name
+	}
+	if err := t.stream.SetPanes(&excelize.Panes{
+		Freeze:      true,
+		YSplit:      1,
+		TopLeftCell: "A2",
+		ActivePane:  "bottomLeft",
+	}); err != nil {
+		return err
+	}
+	return t.setRow(values, excelize.RowOpts{StyleID: t.headerStyle})
+}
+
+func (t *xlsxTableWriter) WriteRow(values ...interface{}) error {
+	cells := make([]interface{}, len(values))
+	for i, v := range values {
+		switch v := v.(type) {
+		case time.Time:
+			if !v.IsZero() {
+				cells[i] = excelize.Cell{StyleID: t.timeStyle, Value: v.UTC()}
+			}
+		case *time.Time:
+			if v != nil {
+				cells[i] = excelize.Cell{StyleID: t.timeStyle, Value: v.UTC()}
+			}
+		default:
+			cells[i] = v
+		}
+	}
+	return t.setRow(cells)
+}
+
+func (t *xlsxTableWriter) setRow(values []interface{}, opts ...excelize.RowOpts) error {
+	t.row++
+	cell, err := excelize.CoordinatesToCellName(1, t.row)
+	if err != nil {
+		return err
+	}
+	return t.stream.SetRow(cell, values, opts...)
+}
+
+func (t *xlsxTableWriter) Close() error {
+	defer t.file.Close()
+	if err := t.stream.Flush(); err != nil {
+		return err
+	}
+	return t.file.Write(t.out)
+}
@@ -0,0 +1,99 @@
+package services
+
+import (
+	"bytes"
+	"errors"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"io"
+
+	// Formats accepted for upload
+	_ "image/gif"
+	_ "image/png"
+
+	"golang.org/x/image/draw"
+	_ "golang.org/x/image/webp"
+)
+
+// maxImagePixels guards against images that are small on disk but huge
+// once decoded.
+const maxImagePixels = 50_000_000
+
+// ImageVariant is one resized copy of an uploaded image.
+type ImageVariant struct {
+	Name    string
+	MaxSide int
+}
+
+// ImageVariants are the sizes made of every uploaded image: a thumbnail
+// for galleries and a display copy for full-screen views.
+var ImageVariants = []ImageVariant{
+	{Name: "thumb", MaxSide: 400},
+	{Name: "display", MaxSide: 1600},
+}
+
+// ErrImageTooLarge is returned for images with too many pixels to process.
+var ErrImageTooLarge = errors.New("image dimensions are too large")
+
+// ResizedImage is a JPEG encoding of one variant.
+type ResizedImage struct {
+	Variant ImageVariant
+	Data    []byte
+	Width   int
+	Height  int
+}
+
+// ResizeImage decodes a JPEG, PNG, GIF or WebP image and returns its
+// dimensions and a JPEG of every variant. Variants are never scaled up,
+// and transparent areas are filled with white.
+func ResizeImage(r io.ReadSeeker) (width, height int, variants []ResizedImage, err error) {
+	config, _, err := image.DecodeConfig(r)
+	if err != nil {
+		return 0, 0, nil, err
+	}
+	if config.Width*config.Height > maxImagePixels {
+		return 0, 0, nil, ErrImageTooLarge
+	}
+	if _, err := r.Seek(0, io.SeekStart); err != nil {
+		return 0, 0, nil, err
+	}
+	src, _, err := image.Decode(r)
+	if err != nil {
+		return 0, 0, nil, err
+	}
+
+	bounds := src.Bounds()
+	for _, v := range ImageVariants {
+		w, h := fitWithin(bounds.Dx(), bounds.Dy(), v.MaxSide)
+		dst := image.NewRGBA(image.Rect(0, 0, w, h))
+		draw.Draw(dst, dst.Bounds(), image.NewUniform(color.White), image.Point{}, draw.Src)
+		draw.CatmullRom.Scale(dst, dst.Bounds(), src, bounds, draw.Over, nil)
+
+		var buf bytes.Buffer
+		if err := jpeg.Encode(&buf, dst, &jpeg.Options{Quality: 82}); err != nil {
+			return 0, 0, nil, err
+		}
+		variants = append(variants, ResizedImage{Variant: v, Data: buf.Bytes(), Width: w, Height: h})
+	}
+	return bounds.Dx(), bounds.Dy(), variants, nil
+}
+
+// fitWithin scales w by h down so neither side exceeds max, keeping the
+// aspect ratio.
+func fitWithin(w, h, max int) (int, int) {
+	if w <= max && h <= max {
+		return w, h
+	}
+	if w >= h {
+		return max, maxInt(1, h*max/w)
+	}
+	return maxInt(1, w*max/h), max
+}
+
+func maxInt(a, b int) int {
+	if a > b {
+		return a
+	}
+	return b
+}
@@ -0,0 +1,226 @@
+package services
+
+import (
+	"bytes"
+	"encoding/base64"
+	"errors"
+	"html"
+	"io"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net/mail"
+	"regexp"
+	"strings"
+)
+
+// maxInboundText caps how much of a reply is kept.
+const maxInboundText = 10000
+
+// maxMIMEDepth stops parsing of pathologically nested messages.
+const maxMIMEDepth = 5
+
+// ErrNoConversation is returned for inbound mail that belongs to no
+// booking or contact thread. Mail servers should bounce it.
+var ErrNoConversation = errors.New("message does not belong to a conversation")
+
+// InboundMessage is a parsed reply from a customer.
+type InboundMessage struct {
+	From      string
+	FromName  string
+	Subject   string
+	MessageID string
+	// Recipients are the envelope recipients when known, otherwise the To
+	// and Cc addresses.
+	Recipients []string
+	// Text is the new part of the reply, with quoted history and the
+	// signature removed.
+	Text string
+}
+
+// ParseInboundMessage reads a raw RFC 5322 message. envelope lists the
+// SMTP recipients when the transport knows them.
+func ParseInboundMessage(raw []byte, envelope []string) (*InboundMessage, error) {
+	msg, err := mail.ReadMessage(bytes.NewReader(raw))
+	if err != nil {
+		return nil, err
+	}
+	from, err := msg.Header.AddressList("From")
+	if err != nil || len(from) == 0 {
+		return nil, errors.New("message has no valid From address")
+	}
+
+	decoder := new(mime.WordDecoder)
+	subject, err := decoder.DecodeHeader(msg.Header.Get("Subject"))
+	if err != nil {
+		subject = msg.Header.Get("Subject")
+	}
+	inbound := &InboundMessage{
+		From:       strings.ToLower(from[0].Address),
+		FromName:   from[0].Name,
+		Subject:    strings.TrimSpace(subject),
+		MessageID:  strings.Trim(strings.TrimSpace(msg.Header.Get("Message-Id")), "<>"),
+		Recipients: envelope,
+	}
+	if len(inbound.Recipients) == 0 {
+		for _, field := range []string{"To", "Cc"} {
+			addresses, _ := msg.Header.AddressList(field)
+			for _, a := range addresses {
+				inbound.Recipients = append(inbound.Recipients, a.Address)
+			}
+		}
+	}
+
+	text, err := messageText(msg.Header.Get("Content-Type"), msg.Header.Get("Content-Transfer-Encoding"), msg.Body, 0)
+	if err != nil {
+		return nil, err
+	}
+	inbound.Text = StripQuotedReply(text)
+	if len(inbound.Text) > maxInboundText {
+		inbound.Text = strings.ToValidUTF8(inbound.Text[:maxInboundText], "")
+	}
+	return inbound, nil
+}
+
+// messageText finds the text of a message part: its text/plain body, or
+// failing that its text/html body with the markup removed.
+func messageText(contentType, encoding string, body io.Reader, depth int) (string, error) {
+	if depth > maxMIMEDepth {
+		return "", errors.New("message is nested too deeply")
+	}
+	mediaType, params, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		mediaType = "text/plain"
+	}
+
+	if strings.HasPrefix(mediaType, "multipart/") {
+		reader := multipart.NewReader(body, params["boundary"])
+		var htmlText string
+		for {
+			part, err := reader.NextRawPart()
+			if err == io.EOF {
+				break
+			}
+			if err != nil {
+				return "", err
+			}
+			if disposition, _, _ := mime.ParseMediaType(part.Header.Get("Content-Disposition")); disposition == "attachment" {
+				continue
+			}
+			text, err := messageText(part.Header.Get("Content-Type"), part.Header.Get("Content-Transfer-Encoding"), part, depth+1)
+			if err != nil {
+				return "", err
+			}
+			partType, _, _ := mime.ParseMediaType(part.Header.Get("Content-Type"))
+			if text != "" && partType != "text/html" {
+				return text, nil
+			}
+			if htmlText == "" {
+				htmlText = text
+			}
+		}
+		return htmlText, nil
+	}
+	if mediaType != "text/plain" && mediaType != "text/html" {
+		return "", nil
+	}
+
+	switch strings.ToLower(strings.TrimSpace(encoding)) {
+	case "quoted-printable":
+		body = quotedprintable.NewReader(body)
+	case "base64":
+		body = base64.NewDecoder(base64.StdEncoding, newlineStripper{body})
+	}
+	data, err := io.ReadAll(io.LimitReader(body, 4*maxInboundText))
+	if err != nil {
+		return "", err
+	}
+	switch strings.ToLower(params["charset"]) {
+	case "iso-8859-1", "latin1", "windows-1252":
+		runes := make([]rune, len(data))
+		for i, b := range data {
+			runes[i] = rune(b)
+		}
+		data = []byte(string(runes))
+	}
+	text := strings.ToValidUTF8(strings.ReplaceAll(string(data), "\r\n", "\n"), "")
+	if mediaType == "text/html" {
+		text = htmlToText(text)
+	}
+	return text, nil
+}
+
+// newlineStripper drops the line breaks base64 bodies are wrapped with.
+type newlineStripper struct{ r io.Reader }
+
+func (n newlineStripper) Read(p []byte) (int, error) {
+	count, err := n.r.Read(p)
+	kept := 0
+	for _, b := range p[:count] {
+		if b != '\r' && b != '\n' {
+			p[kept] = b
+			kept++
+		}
+	}
+	return kept, err
+}
+
+var (
+	htmlBreaks = regexp.MustCompile(`(?i)<br\s*/?>|</p>|</div>|</li>|</tr>`)
+	htmlTags   = regexp.MustCompile(`(?s)<[^>]*>`)
+	htmlHidden = regexp.MustCompile(`(?is)<(style|script|head)[^>]*>.*?</(style|script|head)>`)
+	// htmlQuotes are the elements mail clients wrap quoted history in.
+	htmlQuotes = regexp.MustCompile(`(?is)<blockquote.*|<div class="gmail_quote.*`)
+)
+
+// htmlToText is a rough rendering of an HTML body as plain text, good
+// enough for replies, which are rarely more than a few paragraphs.
+func htmlToText(s string) string {
+	s = htmlHidden.ReplaceAllString(s, "")
+	s = htmlQuotes.ReplaceAllString(s, "")
+	s = htmlBreaks.ReplaceAllString(s, "\n")
+	s = htmlTags.ReplaceAllString(s, "")
+	return html.UnescapeString(s)
+}
+
+// quoteHeaders are lines mail clients put before the quoted original.
+var quoteHeaders = []*regexp.Regexp{
+	regexp.MustCompile(`(?i)^on\b.*wrote:\s*$`),
+	regexp.MustCompile(`^-{2,}\s*Original Message\s*-{2,}$`),
+	regexp.MustCompile(`^_{10,}$`),
+	regexp.MustCompile(`(?i)^from:\s.*@`),
+}
+
+func isQuoteHeader(line string) bool {
+	for _, header := range quoteHeaders {
+		if header.MatchString(line) {
+			return true
+		}
+	}
+	return false
+}
+
+// StripQuotedReply keeps the part of a reply above the quoted original
+// and the signature. When nothing would be left the text is kept whole.
+func StripQuotedReply(text string) string {
+	lines := strings.Split(strings.ReplaceAll(text, "\r\n", "\n"), "\n")
+	end := len(lines)
+	for n, line := range lines {
+		trimmed := strings.TrimSpace(line)
+		next := ""
+		if n+1 < len(lines) {
+			next = strings.TrimSpace(lines[n+1])
+		}
+		// Some clients wrap "On <date>, <name> wrote:" over two lines.
+		wrapped := strings.HasPrefix(strings.ToLower(trimmed), "on ") && isQuoteHeader(trimmed+" "+next)
+		if strings.HasPrefix(trimmed, ">") || line == "-- " || isQuoteHeader(trimmed) || wrapped {
+			end = n
+			break
+		}
+	}
+	reply := strings.TrimSpace(strings.Join(lines[:end], "\n"))
+	if reply == "" {
+		return strings.TrimSpace(text)
+	}
+	return reply
+}
@@ -0,0 +1,410 @@
+package services
+
+import (
+	"context"
+	"crypto"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"log"
+	"math/big"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"gorm.io/gorm"
+
+	"event-booking-backend/models"
+)
+
+const (
+	// rotationLockID is the Postgres advisory lock taken while rotating, so
+	// only one instance creates the next key.
+	rotationLockID = 7428310912
+	// keyReloadInterval is how often instances pick up keys created by
+	// other instances.
+	keyReloadInterval = time.Minute
+	// MaxTokenLifetime is the longest a token signed by the keys is valid
+	// for: a customer sign-in. The grace period must cover it, or a
+	// rotation would cut tokens short.
+	MaxTokenLifetime = 24 * time.Hour
+)
+
+// KeyManager signs and verifies JWTs with a rotating set of asymmetric keys
+// kept in the database. The newest unretired key signs; every unexpired key
+// verifies, so tokens outlive a rotation by the grace period.
+type KeyManager struct {
+	db          *gorm.DB
+	aead        cipher.AEAD
+	algorithm   string
+	rotateEvery time.Duration
+	grace       time.Duration
+
+	mu         sync.RWMutex
+	signing    *loadedKey
+	verifying  map[string]*loadedKey
+	lastReload time.Time
+}
+
+type loadedKey struct {
+	kid     string
+	method  jwt.SigningMethod
+	private crypto.Signer
+	public  crypto.PublicKey
+	expires *time.Time
+}
+
+// NewKeyManager reads the key configuration from the environment, loads the
+// keys and creates the first one if none exist. It fails when
+// JWT_MASTER_KEY, which encrypts the stored private keys, is missing, or
+// when JWT_KEY_GRACE_HOURS is shorter than MaxTokenLifetime.
+func NewKeyManager(db *gorm.DB) (*KeyManager, error) {
+	masterKey, err := base64.StdEncoding.DecodeString(os.Getenv("JWT_MASTER_KEY"))
+	if err != nil || len(masterKey) != 32 {
+		return nil, errors.New("JWT_MASTER_KEY must be set to 32 base64-encoded bytes")
+	}
+	block, err := aes.NewCipher(masterKey)
+	if err != nil {
+		return nil, err
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+
+	algorithm := os.Getenv("JWT_SIGNING_ALG")
+	if algorithm == "" {
+		algorithm = jwt.SigningMethodEdDSA.Alg()
+	}
+	if algorithm != jwt.SigningMethodEdDSA.Alg() && algorithm != jwt.SigningMethodRS256.Alg() {
+		return nil, fmt.Errorf("unsupported JWT_SIGNING_ALG %q, use EdDSA or RS256", algorithm)
+	}
+
+	m := &KeyManager{
+		db:          db,
+		aead:        aead,
+		algorithm:   algorithm,
+		rotateEvery: envDays("JWT_KEY_ROTATION_DAYS", 30),
+		grace:       envHours("JWT_KEY_GRACE_HOURS", 48),
+	}
+	if m.grace < MaxTokenLifetime {
+		return nil, fmt.Errorf("JWT_KEY_GRACE_HOURS must be at least %d, the lifetime of customer tokens", int(MaxTokenLifetime.Hours()))
+	}
+	if err := m.Rotate(false, nil); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// Run rotates the signing key when it is due and reloads keys created by
+// other instances, until ctx is cancelled.
+func (m *KeyManager) Run(ctx context.Context) {
+	ticker := time.NewTicker(keyReloadInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := m.Rotate(false, nil); err != nil {
+				log.Printf("Failed to rotate signing keys: %v", err)
+			}
+		}
+	}
+}
+
+// Rotate creates a new signing key when the current one is older than the
+// rotation interval, or unconditionally when force is set. The old key is
+// retired and kept for verification until the grace period has passed.
+// record, when set, runs in the same transaction once a key is created,
+// e.g. to audit the rotation; if it fails nothing is rotated.
+func (m *KeyManager) Rotate(force bool, record func(tx *gorm.DB) error) error {
+	return m.rotate(force, m.grace, record)
+}
+
+// Revoke creates a new signing key and expires the old ones at once, for
+// when a key may have leaked. Every token they signed stops verifying, so
+// customers and staff have to sign in again. Other instances drop the old
+// keys when they next reload, within keyReloadInterval.
+func (m *KeyManager) Revoke(record func(tx *gorm.DB) error) error {
+	return m.rotate(true, 0, record)
+}
+
+// rotate is Rotate with the time retired keys keep verifying for.
+func (m *KeyManager) rotate(force bool, grace time.Duration, record func(tx *gorm.DB) error) error {
+	err := m.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Exec("SELECT pg_advisory_xact_lock(?)", rotationLockID).Error; err != nil {
+			return err
+		}
+
+		var current models.SigningKey
+		err := tx.Where("retired_at IS NULL").Order("created_at DESC").First(&current).Error
+		if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+			return err
+		}
+		found := err == nil
+		if found && !force && time.Since(current.CreatedAt) < m.rotateEvery && current.Algorithm == m.algorithm {
+			return nil
+		}
+
+		next, err := m.generate()
+		if err != nil {
+			return err
+		}
+		if err := tx.Create(next).Error; err != nil {
+			return err
+		}
+		now := time.Now()
+		if found {
+			if err := tx.Model(&models.SigningKey{}).Where("retired_at IS NULL AND kid != ?", next.KID).
+				Updates(map[string]interface{}{"retired_at": now, "expires_at": now.Add(grace)}).Error; err != nil {
+				return err
+			}
+		}
+		// Without a grace period, keys retired earlier stop verifying too.
+		if grace == 0 {
+			if err := tx.Model(&models.SigningKey{}).Where("expires_at > ?", now).
+				Update("expires_at", now).Error; err != nil {
+				return err
+			}
+		}
+		if record != nil {
+			return record(tx)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	return m.reload()
+}
+
+// Sign signs claims with the current key and sets its kid header.
+func (m *KeyManager) Sign(claims jwt.Claims) (string, error) {
+	m.mu.RLock()
+	key := m.signing
+	m.mu.RUnlock()
+	if key == nil {
+		return "", errors.New("no signing key available")
+	}
+
+	token := jwt.NewWithClaims(key.method, claims)
+	token.Header["kid"] = key.kid
+	return token.SignedString(key.private)
+}
+
+// Keyfunc resolves the verification key for a token from its kid header.
+func (m *KeyManager) Keyfunc(token *jwt.Token) (interface{}, error) {
+	kid, _ := token.Header["kid"].(string)
+	if kid == "" {
+		return nil, errors.New("token has no kid")
+	}
+
+	key := m.lookup(kid)
+	if key == nil {
+		// Another instance may have rotated since our last reload.
+		m.reloadIfStale()
+		key = m.lookup(kid)
+	}
+	if key == nil {
+		return nil, fmt.Errorf("unknown signing key %q", kid)
+	}
+	if key.expires != nil && time.Now().After(*key.expires) {
+		return nil, fmt.Errorf("signing key %q has expired", kid)
+	}
+	if token.Method.Alg() != key.method.Alg() {
+		return nil, errors.New("token algorithm does not match its key")
+	}
+	return key.public, nil
+}
+
+// ValidMethods lists the algorithms tokens may be signed with.
+func (m *KeyManager) ValidMethods() []string {
+	return []string{jwt.SigningMethodEdDSA.Alg(), jwt.SigningMethodRS256.Alg()}
+}
+
+// JWKS returns the public keys that currently verify tokens, in JSON Web
+// Key Set form.
+func (m *KeyManager) JWKS() map[string]interface{} {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	keys := []map[string]string{}
+	for _, key := range m.verifying {
+		jwk := map[string]string{
+			"kid": key.kid,
+			"alg": key.method.Alg(),
+			"use": "sig",
+		}
+		switch pub := key.public.(type) {
+		case ed25519.PublicKey:
+			jwk["kty"] = "OKP"
+			jwk["crv"] = "Ed25519"
+			jwk["x"] = base64.RawURLEncoding.EncodeToString(pub)
+		case *rsa.PublicKey:
+			jwk["kty"] = "RSA"
+			jwk["n"] = base64.RawURLEncoding.EncodeToString(pub.N.Bytes())
+			jwk["e"] = base64.RawURLEncoding.EncodeToString(big.NewInt(int64(pub.E)).Bytes())
+		}
+		keys = append(keys, jwk)
+	}
+	return map[string]interface{}{"keys": keys}
+}
+
+func (m *KeyManager) lookup(kid string) *loadedKey {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.verifying[kid]
+}
+
+func (m *KeyManager) reloadIfStale() {
+	m.mu.RLock()
+	stale := time.Since(m.lastReload) > 5*time.Second
+	m.mu.RUnlock()
+	if stale {
+		if err := m.reload(); err != nil {
+			log.Printf("Failed to reload signing keys: %v", err)
+		}
+	}
+}
+
+// reload replaces the in-memory keys with the unexpired keys in the
+// database.
+func (m *KeyManager) reload() error {
+	var rows []models.SigningKey
+	err := m.db.Where("expires_at IS NULL OR expires_at > ?", time.Now()).
+		Order("created_at DESC").Find(&rows).Error
+	if err != nil {
+		return err
+	}
+
+	var signing *loadedKey
+	verifying := make(map[string]*loadedKey, len(rows))
+	for i := range rows {
+		key, err := m.decode(&rows[i])
+		if err != nil {
+			return fmt.Errorf("signing key %s: %w", rows[i].KID, err)
+		}
+		verifying[key.kid] = key
+		if signing == nil && rows[i].RetiredAt == nil {
+			signing = key
+		}
+	}
+	if signing == nil {
+		return errors.New("no active signing key")
+	}
+
+	m.mu.Lock()
+	m.signing = signing
+	m.verifying = verifying
+	m.lastReload = time.Now()
+	m.mu.Unlock()
+	return nil
+}
+
+func (m *KeyManager) generate() (*models.SigningKey, error) {
+	var private crypto.Signer
+	var err error
+	switch m.algorithm {
+	case jwt.SigningMethodRS256.Alg():
+		private, err = rsa.GenerateKey(rand.Reader, 2048)
+	default:
+		_, private, err = ed25519.GenerateKey(rand.Reader)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	privateDER, err := x509.MarshalPKCS8PrivateKey(private)
+	if err != nil {
+		return nil, err
+	}
+	publicDER, err := x509.MarshalPKIXPublicKey(private.Public())
+	if err != nil {
+		return nil, err
+	}
+	sealed, err := m.seal(privateDER)
+	if err != nil {
+		return nil, err
+	}
+	kid, _, err := NewOpaqueToken()
+	if err != nil {
+		return nil, err
+	}
+
+	return &models.SigningKey{
+		KID:        kid[:16],
+		Algorithm:  m.algorithm,
+		PrivateKey: sealed,
+		PublicKey:  publicDER,
+	}, nil
+}
+
+func (m *KeyManager) decode(row *models.SigningKey) (*loadedKey, error) {
+	privateDER, err := m.open(row.PrivateKey)
+	if err != nil {
+		return nil, err
+	}
+	parsed, err := x509.ParsePKCS8PrivateKey(privateDER)
+	if err != nil {
+		return nil, err
+	}
+	private, ok := parsed.(crypto.Signer)
+	if !ok {
+		return nil, errors.New("unsupported private key type")
+	}
+
+	var method jwt.SigningMethod
+	switch row.Algorithm {
+	case jwt.SigningMethodEdDSA.Alg():
+		method = jwt.SigningMethodEdDSA
+	case jwt.SigningMethodRS256.Alg():
+		method = jwt.SigningMethodRS256
+	default:
+		return nil, fmt.Errorf("unsupported algorithm %q", row.Algorithm)
+	}
+
+	return &loadedKey{
+		kid:     row.KID,
+		method:  method,
+		private: private,
+		public:  private.Public(),
+		expires: row.ExpiresAt,
+	}, nil
+}
+
+func (m *KeyManager) seal(plaintext []byte) ([]byte, error) {
+	nonce := make([]byte, m.aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+	return m.aead.Seal(nonce, nonce, plaintext, nil), nil
+}
+
+func (m *KeyManager) open(sealed []byte) ([]byte, error) {
+	size := m.aead.NonceSize()
+	if len(sealed) < size {
+		return nil, errors.New("sealed key is too short")
+	}
+	plaintext, err := m.aead.Open(nil, sealed[:size], sealed[size:], nil)
+	if err != nil {
+		return nil, errors.New("cannot decrypt key, check JWT_MASTER_KEY")
+	}
+	return plaintext, nil
+}
+
+func envDays(key string, fallback int) time.Duration {
+	days := fallback
+	if v, err := strconv.Atoi(os.Getenv(key)); err == nil && v > 0 {
+		days = v
+	}
+	return time.Duration(days) * 24 * time.Hour
+}
@@ -0,0 +1,18 @@
+package services
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestKeyGraceMustCoverTokenLifetime(t *testing.T) {
+	t.Setenv("JWT_MASTER_KEY", "MDEyMzQ1Njc4OWFiY2RlZjAxMjM0NTY3ODlhYmNkZWY=")
+	for _, hours := range []string{"0", "12", "23"} {
+		t.Setenv("JWT_KEY_GRACE_HOURS", hours)
+		// The check comes before the database is touched
+		_, err := NewKeyManager(nil)
+		if err == nil || !strings.Contains(err.Error(), "JWT_KEY_GRACE_HOURS") {
+			t.Errorf("grace of %s hours: %v, want it refused", hours, err)
+		}
+	}
+}
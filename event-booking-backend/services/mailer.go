@@ -0,0 +1,174 @@
+package services
+
+import (
+	"fmt"
+	"log"
+	"os"
+
+	"gorm.io/gorm"
+
+	"event-booking-backend/models"
+)
+
+// Templates, the kinds of message the Mailer sends.
+const (
+	TemplateBookingConfirmation   = "booking_confirmation"
+	TemplateAdminNewBooking       = "admin_new_booking"
+	TemplateBookingCancelled      = "booking_cancelled"
+	TemplateRescheduleRequest     = "reschedule_request"
+	TemplateBookingRescheduled    = "booking_rescheduled"
+	TemplateEmailVerification     = "email_verification"
+	TemplatePasswordReset         = "password_reset"
+	TemplateBookingConfirmed      = "booking_confirmed"
+	TemplateContactAcknowledgment = "contact_acknowledgment"
+	TemplateContactReply          = "contact_reply"
+)
+
+// Mailer renders the emails the system sends from the templates in
+// emails/ and hands them to a Notifier, normally the Outbox. Customers are
+// written to in their own locale, staff in English. Mail to customers
+// about a booking or a contact ticket carries its reply address, so their
+// answers reach the inbox.
+type Mailer struct {
+	notifier   Notifier
+	replies    *ReplyAddressService
+	adminEmail string
+	// tx, set by In, is where booking halls are looked up for their name.
+	tx *gorm.DB
+}
+
+// NewMailer sends through notifier. Staff notifications go to ADMIN_EMAIL.
+func NewMailer(notifier Notifier, replies *ReplyAddressService) *Mailer {
+	return &Mailer{
+		notifier:   notifier,
+		replies:    replies,
+		adminEmail: os.Getenv("ADMIN_EMAIL"),
+	}
+}
+
+// In returns a Mailer that reads from tx and, with an Outbox, queues its
+// messages in tx, so they go out only if the change they report is
+// committed.
+func (m *Mailer) In(tx *gorm.DB) *Mailer {
+	in := *m
+	in.tx = tx
+	if queue, ok := m.notifier.(interface{ In(tx *gorm.DB) Notifier }); ok {
+		in.notifier = queue.In(tx)
+	}
+	return &in
+}
+
+// hallName returns the name of the booking's hall, or its ID when the hall
+// cannot be looked up.
+func (m *Mailer) hallName(booking *models.Booking) string {
+	if m.tx != nil {
+		var hall models.Hall
+		if err := m.tx.Select("name").First(&hall, "id = ?", booking.HallID).Error; err == nil {
+			return hall.Name
+		}
+	}
+	return booking.HallID
+}
+
+func (m *Mailer) send(template string, to Address, replyTo string, data emailData) error {
+	data.CanReply = replyTo != ""
+	msg, err := renderEmail(template, data)
+	if err != nil {
+		return fmt.Errorf("rendering %s: %w", template, err)
+	}
+	msg.To = []Address{to}
+	msg.ReplyTo = replyTo
+	return m.notifier.Send(*msg)
+}
+
+func (m *Mailer) sendToCustomer(template string, booking *models.Booking, data emailData) error {
+	data.Locale = booking.Locale
+	data.Name = booking.CustomerName
+	data.Booking = booking
+	data.HallName = m.hallName(booking)
+	to := Address{Email: booking.CustomerEmail, Name: booking.CustomerName}
+	return m.send(template, to, m.replies.Address(ReplyToBooking, booking.ID), data)
+}
+
+func (m *Mailer) sendToAdmin(template string, data emailData) error {
+	if m.adminEmail == "" {
+		log.Printf("ADMIN_EMAIL is not set; not sending %s", template)
+		return nil
+	}
+	data.Locale = DefaultLocale
+	if data.Booking != nil {
+		data.HallName = m.hallName(data.Booking)
+	}
+	return m.send(template, Address{Email: m.adminEmail, Name: "Admin"}, "", data)
+}
+
+func (m *Mailer) sendToContact(template string, contact *models.Contact, data emailData) error {
+	data.Locale = contact.Locale
+	data.Name = contact.Name
+	data.Contact = contact
+	to := Address{Email: contact.Email, Name: contact.Name}
+	return m.send(template, to, m.replies.Address(ReplyToContact, uint64(contact.ID)), data)
+}
+
+// SendBookingConfirmation tells the customer their booking was received,
+// with the link to manage it.
+func (m *Mailer) SendBookingConfirmation(booking *models.Booking, manageURL string) error {
+	return m.sendToCustomer(TemplateBookingConfirmation, booking, emailData{Link: manageURL})
+}
+
+// SendAdminNotification tells staff about a new booking.
+func (m *Mailer) SendAdminNotification(booking *models.Booking) error {
+	return m.sendToAdmin(TemplateAdminNewBooking, emailData{Booking: booking})
+}
+
+// SendCancellationConfirmation tells the customer their booking was
+// cancelled and, when refund is set, what is refunded.
+func (m *Mailer) SendCancellationConfirmation(booking *models.Booking, refund *float64) error {
+	return m.sendToCustomer(TemplateBookingCancelled, booking, emailData{Refund: refund})
+}
+
+// SendStatusConfirmed tells the customer staff have confirmed their
+// pending booking.
+func (m *Mailer) SendStatusConfirmed(booking *models.Booking) error {
+	return m.sendToCustomer(TemplateBookingConfirmed, booking, emailData{})
+}
+
+// SendRescheduleConfirmation tells the customer their booking moved, with
+// a manage link that lasts until after the new date.
+func (m *Mailer) SendRescheduleConfirmation(booking *models.Booking, manageURL string) error {
+	return m.sendToCustomer(TemplateBookingRescheduled, booking, emailData{Link: manageURL})
+}
+
+// SendRescheduleRequestNotification tells staff a customer asked to move
+// their booking.
+func (m *Mailer) SendRescheduleRequestNotification(booking *models.Booking, request *models.RescheduleRequest) error {
+	return m.sendToAdmin(TemplateRescheduleRequest, emailData{Booking: booking, Request: request})
+}
+
+// SendEmailVerification sends a new customer the link that verifies their
+// address.
+func (m *Mailer) SendEmailVerification(customer *models.Customer, verifyURL string) error {
+	return m.sendCustomerLink(TemplateEmailVerification, customer, verifyURL)
+}
+
+// SendPasswordReset sends a customer the link to choose a new password.
+func (m *Mailer) SendPasswordReset(customer *models.Customer, resetURL string) error {
+	return m.sendCustomerLink(TemplatePasswordReset, customer, resetURL)
+}
+
+func (m *Mailer) sendCustomerLink(template string, customer *models.Customer, link string) error {
+	data := emailData{Locale: customer.Locale, Name: customer.Name, Link: link}
+	return m.send(template, Address{Email: customer.Email, Name: customer.Name}, "", data)
+}
+
+// SendContactAcknowledgment tells someone who used the contact form that
+// their message arrived.
+func (m *Mailer) SendContactAcknowledgment(contact *models.Contact) error {
+	return m.sendToContact(TemplateContactAcknowledgment, contact, emailData{})
+}
+
+// SendContactReply emails a staff reply to a contact ticket. The reply is
+// plain text; its line breaks are kept.
+func (m *Mailer) SendContactReply(contact *models.Contact, subject, reply string) error {
+	return m.sendToContact(TemplateContactReply, contact, emailData{Subject: subject, Reply: reply})
+}
@@ -0,0 +1,86 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+
+	"event-booking-backend/models"
+)
+
+const manageLinkAudience = "booking-manage"
+
+// manageLinkValidity is how long a link keeps working after the event has
+// taken place, so customers can still download their documents.
+const manageLinkValidity = 30 * 24 * time.Hour
+
+// ManageClaims are the claims carried by a booking manage-link token.
+type ManageClaims struct {
+	BookingID uint64 `json:"booking_id"`
+	Email     string `json:"email"`
+	jwt.RegisteredClaims
+}
+
+// ManageLinkService issues and verifies the signed links that let customers
+// manage a booking without an account.
+type ManageLinkService struct {
+	secret  []byte
+	baseURL string
+}
+
+func NewManageLinkService() (*ManageLinkService, error) {
+	// Manage links outlive JWT signing key rotation, so they are signed
+	// with their own long-lived secret.
+	secret := os.Getenv("MANAGE_LINK_SECRET")
+	if secret == "" {
+		return nil, errors.New("MANAGE_LINK_SECRET must be set")
+	}
+
+	return &ManageLinkService{
+		secret:  []byte(secret),
+		baseURL: FrontendURL(),
+	}, nil
+}
+
+// Token issues a manage token for the booking. It stays valid until a while
+// after the event.
+func (s *ManageLinkService) Token(booking *models.Booking) (string, error) {
+	claims := &ManageClaims{
+		BookingID: booking.ID,
+		Email:     booking.CustomerEmail,
+		RegisteredClaims: jwt.RegisteredClaims{
+			Audience:  jwt.ClaimStrings{manageLinkAudience},
+			ExpiresAt: jwt.NewNumericDate(booking.EventDate.Add(manageLinkValidity)),
+			IssuedAt:  jwt.NewNumericDate(time.Now()),
+		},
+	}
+
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+	return token.SignedString(s.secret)
+}
+
+// URL returns the customer-facing link for a manage token.
+func (s *ManageLinkService) URL(token string) string {
+	return fmt.Sprintf("%s/?manage=%s", s.baseURL, url.QueryEscape(token))
+}
+
+// Verify checks the token's signature, expiry and audience and returns its
+// claims.
+func (s *ManageLinkService) Verify(token string) (*ManageClaims, error) {
+	claims := &ManageClaims{}
+	parsed, err := jwt.ParseWithClaims(token, claims, func(t *jwt.Token) (interface{}, error) {
+		return s.secret, nil
+	},
+		jwt.WithValidMethods([]string{jwt.SigningMethodHS256.Alg()}),
+		jwt.WithAudience(manageLinkAudience),
+		jwt.WithExpirationRequired(),
+	)
+	if err != nil || !parsed.Valid {
+		return nil, errors.New("invalid or expired manage link")
+	}
+	return claims, nil
+}
@@ -0,0 +1,78 @@
+package services
+
+import (
+	"fmt"
+	"os"
+	"strings"
+)
+
+// Address is an email recipient or sender.
+type Address struct {
+	Email string `json:"email"`
+	Name  string `json:"name,omitempty"`
+}
+
+// Message is one email, rendered and ready to send.
+type Message struct {
+	// Template names the kind of message, e.g. "booking_confirmation".
+	Template string    `json:"template"`
+	Locale   string    `json:"locale,omitempty"`
+	To       []Address `json:"to"`
+	ReplyTo  string    `json:"replyTo,omitempty"`
+	Subject  string    `json:"subject"`
+	HTML     string    `json:"html"`
+	Text     string    `json:"text"`
+}
+
+// Notifier delivers messages. Every email the system sends goes through
+// one, so the transport is chosen in one place.
+type Notifier interface {
+	Send(msg Message) error
+}
+
+// NewNotifier builds the driver named by MAIL_DRIVER:
+//
+//   - smtp sends through SMTP_HOST and SMTP_PORT, signing in with
+//     SMTP_USERNAME and SMTP_PASSWORD when set.
+//   - brevo sends through the Brevo API with BREVO_API_KEY.
+//   - log writes messages to the log, and to MAIL_LOG_DIR when set,
+//     instead of sending them; for development and tests.
+//
+// Without MAIL_DRIVER, brevo is used when BREVO_API_KEY is set, then smtp
+// when SMTP_HOST is, and log otherwise. Mail comes from MAIL_FROM (or
+// FROM_EMAIL) with the name MAIL_FROM_NAME.
+func NewNotifier() (Notifier, error) {
+	from := Address{Email: os.Getenv("MAIL_FROM"), Name: os.Getenv("MAIL_FROM_NAME")}
+	if from.Email == "" {
+		from.Email = os.Getenv("FROM_EMAIL")
+	}
+	if from.Name == "" {
+		from.Name = "Event Booking System"
+	}
+
+	driver := strings.ToLower(strings.TrimSpace(os.Getenv("MAIL_DRIVER")))
+	if driver == "" {
+		switch {
+		case os.Getenv("BREVO_API_KEY") != "":
+			driver = "brevo"
+		case os.Getenv("SMTP_HOST") != "":
+			driver = "smtp"
+		default:
+			driver = "log"
+		}
+	}
+	if driver != "log" && from.Email == "" {
+		return nil, fmt.Errorf("MAIL_FROM must be set for the %s mail driver", driver)
+	}
+
+	switch driver {
+	case "smtp":
+		return NewSMTPNotifier(from)
+	case "brevo":
+		return NewBrevoNotifier(from)
+	case "log":
+		return NewLogNotifier(os.Getenv("MAIL_LOG_DIR"))
+	default:
+		return nil, fmt.Errorf("unknown MAIL_DRIVER %q", driver)
+	}
+}
@@ -0,0 +1,71 @@
+package services
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"time"
+)
+
+// BrevoNotifier sends mail through the Brevo transactional email API.
+// Messages go out as rendered here; Brevo's own templates are not used.
+type BrevoNotifier struct {
+	apiKey     string
+	apiBaseURL string
+	from       Address
+	client     *http.Client
+}
+
+func NewBrevoNotifier(from Address) (*BrevoNotifier, error) {
+	apiKey := os.Getenv("BREVO_API_KEY")
+	if apiKey == "" {
+		return nil, errors.New("BREVO_API_KEY must be set for the brevo mail driver")
+	}
+	return &BrevoNotifier{
+		apiKey:     apiKey,
+		apiBaseURL: "https://api.brevo.com/v3",
+		from:       from,
+		client:     &http.Client{Timeout: 30 * time.Second},
+	}, nil
+}
+
+func (n *BrevoNotifier) Send(msg Message) error {
+	emailData := map[string]interface{}{
+		"sender":      n.from,
+		"to":          msg.To,
+		"subject":     msg.Subject,
+		"htmlContent": msg.HTML,
+		"textContent": msg.Text,
+	}
+	if msg.ReplyTo != "" {
+		emailData["replyTo"] = Address{Email: msg.ReplyTo}
+	}
+
+	jsonData, err := json.Marshal(emailData)
+	if err != nil {
+		return fmt.Errorf("error marshaling email data: %v", err)
+	}
+
+	req, err := http.NewRequest("POST", fmt.Sprintf("%s/smtp/email", n.apiBaseURL), bytes.NewBuffer(jsonData))
+	if err != nil {
+		return fmt.Errorf("error creating request: %v", err)
+	}
+
+	req.Header.Set("api-key", n.apiKey)
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := n.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("error sending email: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusCreated {
+		return fmt.Errorf("email API returned status code: %d", resp.StatusCode)
+	}
+
+	return nil
+}
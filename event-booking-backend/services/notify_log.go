@@ -0,0 +1,69 @@
+package services
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+// LogNotifier does not send anything: it logs each message and, when it
+// has a directory, writes it there as JSON. It also keeps the messages, so
+// tests can check what would have been sent.
+type LogNotifier struct {
+	dir  string
+	mu   sync.Mutex
+	sent []Message
+	seq  int
+}
+
+// NewLogNotifier writes messages to dir, creating it if needed, or only
+// to the log when dir is "".
+func NewLogNotifier(dir string) (*LogNotifier, error) {
+	if dir != "" {
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			return nil, err
+		}
+	}
+	return &LogNotifier{dir: dir}, nil
+}
+
+func (n *LogNotifier) Send(msg Message) error {
+	to := make([]string, len(msg.To))
+	for i, a := range msg.To {
+		to[i] = a.Email
+	}
+	log.Printf("Mail %s to %s: %s", msg.Template, strings.Join(to, ", "), msg.Subject)
+
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	n.sent = append(n.sent, msg)
+	n.seq++
+	if n.dir == "" {
+		return nil
+	}
+	data, err := json.MarshalIndent(msg, "", "  ")
+	if err != nil {
+		return err
+	}
+	name := fmt.Sprintf("%s-%04d-%s.json", time.Now().Format("20060102T150405"), n.seq, msg.Template)
+	return os.WriteFile(filepath.Join(n.dir, name), data, 0o644)
+}
+
+// Sent returns the messages sent so far.
+func (n *LogNotifier) Sent() []Message {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	return append([]Message(nil), n.sent...)
+}
+
+// Reset forgets the messages sent so far.
+func (n *LogNotifier) Reset() {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	n.sent = nil
+}
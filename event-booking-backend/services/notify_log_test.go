@@ -0,0 +1,125 @@
+package services
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"event-booking-backend/models"
+)
+
+func TestLogNotifierKeepsMessages(t *testing.T) {
+	n, err := NewLogNotifier("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	first := Message{Template: "first", To: []Address{{Email: "a@example.com"}}, Subject: "One"}
+	second := Message{Template: "second", To: []Address{{Email: "b@example.com"}}, Subject: "Two"}
+	for _, msg := range []Message{first, second} {
+		if err := n.Send(msg); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	sent := n.Sent()
+	if len(sent) != 2 || sent[0].Template != "first" || sent[1].Template != "second" {
+		t.Fatalf("Sent() = %+v", sent)
+	}
+	// Sent returns a copy
+	sent[0].Subject = "changed"
+	if n.Sent()[0].Subject != "One" {
+		t.Error("changing the result of Sent changed the notifier")
+	}
+
+	n.Reset()
+	if sent := n.Sent(); len(sent) != 0 {
+		t.Errorf("after Reset, Sent() = %+v", sent)
+	}
+}
+
+func TestLogNotifierWritesDir(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "mail")
+	n, err := NewLogNotifier(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	msg := Message{Template: "welcome", To: []Address{{Email: "a@example.com", Name: "A"}}, Subject: "Hi", Text: "Hello\n"}
+	if err := n.Send(msg); err != nil {
+		t.Fatal(err)
+	}
+
+	files, err := filepath.Glob(filepath.Join(dir, "*-welcome.json"))
+	if err != nil || len(files) != 1 {
+		t.Fatalf("files = %v, %v; want one", files, err)
+	}
+	data, err := os.ReadFile(files[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	var written Message
+	if err := json.Unmarshal(data, &written); err != nil {
+		t.Fatal(err)
+	}
+	if written.Subject != "Hi" || written.Text != "Hello\n" || written.To[0] != msg.To[0] {
+		t.Errorf("written = %+v", written)
+	}
+}
+
+func TestMailerSendsThroughNotifier(t *testing.T) {
+	n, _ := NewLogNotifier("")
+	replies := &ReplyAddressService{secret: []byte("secret"), domain: "replies.example.com"}
+	mailer := NewMailer(n, replies)
+	booking := &models.Booking{
+		ID:            7,
+		HallID:        "garden",
+		CustomerName:  "Asha <b>",
+		CustomerEmail: "asha@example.com",
+		GuestCount:    40,
+		EventDate:     time.Date(2026, 3, 14, 0, 0, 0, 0, time.UTC),
+		StartTime:     "18:00",
+		EndTime:       "20:00",
+	}
+
+	if err := mailer.SendBookingConfirmation(booking, "https://example.com/manage?token=x"); err != nil {
+		t.Fatal(err)
+	}
+	sent := n.Sent()
+	if len(sent) != 1 {
+		t.Fatalf("sent %d messages, want 1", len(sent))
+	}
+	msg := sent[0]
+	if msg.Template != TemplateBookingConfirmation || msg.To[0].Email != "asha@example.com" {
+		t.Errorf("message = %+v", msg)
+	}
+	if msg.ReplyTo != replies.Address(ReplyToBooking, 7) {
+		t.Errorf("reply-to = %q", msg.ReplyTo)
+	}
+	if !containsAll(msg.HTML, "Asha &lt;b&gt;", "https://example.com/manage?token=x") || strings.Contains(msg.HTML, "Asha <b>") {
+		t.Errorf("HTML does not escape the name or lacks the link:\n%s", msg.HTML)
+	}
+	if !containsAll(msg.Text, "Dear Asha <b>,", "March 14, 2026") {
+		t.Errorf("text part:\n%s", msg.Text)
+	}
+
+	// Without ADMIN_EMAIL, staff notifications are skipped
+	n.Reset()
+	mailer.adminEmail = ""
+	if err := mailer.SendAdminNotification(booking); err != nil {
+		t.Fatal(err)
+	}
+	if sent := n.Sent(); len(sent) != 0 {
+		t.Errorf("sent %d admin messages without ADMIN_EMAIL", len(sent))
+	}
+}
+
+func containsAll(s string, parts ...string) bool {
+	for _, part := range parts {
+		if !strings.Contains(s, part) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,121 @@
+package services
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net/mail"
+	"net/smtp"
+	"net/textproto"
+	"os"
+	"strings"
+	"time"
+)
+
+// SMTPNotifier sends mail through an SMTP relay.
+type SMTPNotifier struct {
+	addr string
+	host string
+	auth smtp.Auth
+	from Address
+}
+
+// NewSMTPNotifier reads SMTP_HOST, SMTP_PORT (default 587), SMTP_USERNAME
+// and SMTP_PASSWORD.
+func NewSMTPNotifier(from Address) (*SMTPNotifier, error) {
+	host := os.Getenv("SMTP_HOST")
+	if host == "" {
+		return nil, errors.New("SMTP_HOST must be set for the smtp mail driver")
+	}
+	port := os.Getenv("SMTP_PORT")
+	if port == "" {
+		port = "587"
+	}
+	n := &SMTPNotifier{addr: host + ":" + port, host: host, from: from}
+	if username := os.Getenv("SMTP_USERNAME"); username != "" {
+		n.auth = smtp.PlainAuth("", username, os.Getenv("SMTP_PASSWORD"), host)
+	}
+	return n, nil
+}
+
+func (n *SMTPNotifier) Send(msg Message) error {
+	raw, err := buildMIMEMessage(n.from, msg)
+	if err != nil {
+		return err
+	}
+	to := make([]string, len(msg.To))
+	for i, a := range msg.To {
+		to[i] = a.Email
+	}
+	return smtp.SendMail(n.addr, n.auth, n.from.Email, to, raw)
+}
+
+// headerBreaks would end a header line early.
+var headerBreaks = strings.NewReplacer("\r", " ", "\n", " ")
+
+// buildMIMEMessage writes msg as an RFC 5322 message with text and HTML
+// alternatives. Header values are encoded, so nothing in them can start a
+// header of its own.
+func buildMIMEMessage(from Address, msg Message) ([]byte, error) {
+	if len(msg.To) == 0 {
+		return nil, errors.New("message has no recipients")
+	}
+	var buf bytes.Buffer
+	header := func(name, value string) {
+		fmt.Fprintf(&buf, "%s: %s\r\n", name, headerBreaks.Replace(value))
+	}
+	to := make([]string, len(msg.To))
+	for i, a := range msg.To {
+		to[i] = (&mail.Address{Name: a.Name, Address: a.Email}).String()
+	}
+	domain := from.Email[strings.LastIndex(from.Email, "@")+1:]
+	id := make([]byte, 12)
+	if _, err := rand.Read(id); err != nil {
+		return nil, err
+	}
+
+	header("From", (&mail.Address{Name: from.Name, Address: from.Email}).String())
+	header("To", strings.Join(to, ", "))
+	if msg.ReplyTo != "" {
+		header("Reply-To", msg.ReplyTo)
+	}
+	header("Subject", mime.QEncoding.Encode("utf-8", headerBreaks.Replace(msg.Subject)))
+	header("Date", time.Now().Format(time.RFC1123Z))
+	header("Message-ID", "<"+hex.EncodeToString(id)+"@"+domain+">")
+	header("MIME-Version", "1.0")
+
+	parts := multipart.NewWriter(&buf)
+	header("Content-Type", "multipart/alternative; boundary="+parts.Boundary())
+	buf.WriteString("\r\n")
+	for _, part := range []struct{ contentType, body string }{
+		{"text/plain", msg.Text},
+		{"text/html", msg.HTML},
+	} {
+		if part.body == "" {
+			continue
+		}
+		w, err := parts.CreatePart(textproto.MIMEHeader{
+			"Content-Type":              {part.contentType + "; charset=utf-8"},
+			"Content-Transfer-Encoding": {"quoted-printable"},
+		})
+		if err != nil {
+			return nil, err
+		}
+		qp := quotedprintable.NewWriter(w)
+		if _, err := qp.Write([]byte(part.body)); err != nil {
+			return nil, err
+		}
+		if err := qp.Close(); err != nil {
+			return nil, err
+		}
+	}
+	if err := parts.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
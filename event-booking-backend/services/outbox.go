@@ -0,0 +1,194 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log"
+	"regexp"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"event-booking-backend/models"
+)
+
+const (
+	outboxPollInterval = 5 * time.Second
+	// maxOutboxError is how much of a send error is kept.
+	maxOutboxError = 1000
+)
+
+var (
+	ErrOutboxMessageNotFound = errors.New("outbox message not found")
+	ErrOutboxMessageQueued   = errors.New("outbox message is already queued")
+)
+
+// Outbox is a Notifier that queues messages in Postgres, and the worker
+// that sends them through the real Notifier. A failed send is retried with
+// exponential backoff until the policy's attempts run out, after which the
+// message is dead until staff resend it.
+type Outbox struct {
+	db       *gorm.DB
+	notifier Notifier
+	policy   OutboxPolicy
+}
+
+func NewOutbox(db *gorm.DB, notifier Notifier, policy OutboxPolicy) *Outbox {
+	return &Outbox{db: db, notifier: notifier, policy: policy}
+}
+
+// Send queues msg on its own.
+func (o *Outbox) Send(msg Message) error {
+	return enqueue(o.db, msg)
+}
+
+// In returns a Notifier that queues messages in tx, so they are only sent
+// if tx commits.
+func (o *Outbox) In(tx *gorm.DB) Notifier {
+	return outboxTx{tx: tx}
+}
+
+type outboxTx struct {
+	tx *gorm.DB
+}
+
+func (t outboxTx) Send(msg Message) error {
+	return enqueue(t.tx, msg)
+}
+
+func enqueue(db *gorm.DB, msg Message) error {
+	payload, err := json.Marshal(msg)
+	if err != nil {
+		return err
+	}
+	row := models.OutboxMessage{
+		Template:      msg.Template,
+		Subject:       msg.Subject,
+		Payload:       string(payload),
+		Status:        models.OutboxPending,
+		NextAttemptAt: time.Now(),
+	}
+	if len(msg.To) > 0 {
+		row.Recipient = msg.To[0].Email
+	}
+	return db.Create(&row).Error
+}
+
+// Run sends due messages until ctx is cancelled.
+func (o *Outbox) Run(ctx context.Context) {
+	ticker := time.NewTicker(outboxPollInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			o.DeliverDue(ctx)
+		}
+	}
+}
+
+// DeliverDue sends every message that is due, one at a time.
+func (o *Outbox) DeliverDue(ctx context.Context) {
+	for ctx.Err() == nil {
+		delivered, err := o.deliverNext()
+		if err != nil {
+			log.Printf("Failed to deliver outbox message: %v", err)
+			return
+		}
+		if !delivered {
+			return
+		}
+	}
+}
+
+// deliverNext sends the message due longest ago and reports whether there
+// was one. Its row stays locked while it is sent, so other instances skip
+// it; if the process dies mid-send the lock goes and the message is tried
+// again, so a message may be sent twice but is never lost.
+func (o *Outbox) deliverNext() (bool, error) {
+	found := false
+	err := o.db.Transaction(func(tx *gorm.DB) error {
+		var row models.OutboxMessage
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+			Where("status = ? AND next_attempt_at <= ?", models.OutboxPending, time.Now()).
+			Order("next_attempt_at").Take(&row).Error
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		found = true
+
+		var msg Message
+		sendErr := json.Unmarshal([]byte(row.Payload), &msg)
+		if sendErr == nil {
+			sendErr = o.notifier.Send(msg)
+		}
+		now := time.Now()
+		row.Attempts++
+		switch {
+		case sendErr == nil:
+			row.Status = models.OutboxSent
+			row.SentAt = &now
+			row.LastError = ""
+		case row.Attempts >= o.policy.MaxAttempts:
+			row.Status = models.OutboxDead
+			row.LastError = truncateError(sendErr)
+			log.Printf("Gave up on outbox message %d (%s to %s) after %d attempts: %v",
+				row.ID, row.Template, row.Recipient, row.Attempts, sendErr)
+		default:
+			row.NextAttemptAt = now.Add(o.policy.RetryDelay(row.Attempts))
+			row.LastError = truncateError(sendErr)
+		}
+		return tx.Save(&row).Error
+	})
+	return found, err
+}
+
+// secretLinkToken matches the token of a password reset, email
+// verification or manage link, up to the end of the URL in text, HTML or
+// JSON.
+var secretLinkToken = regexp.MustCompile(`([?&](?:amp;)?(?:reset|verify|manage)=)[^\s"'&<>\\]+`)
+
+// RedactPayload blanks the tokens of the sign-in and manage links in a
+// message payload. Anyone who can read them could take over the account or
+// booking they were sent for, so staff only ever see them redacted.
+func RedactPayload(payload string) string {
+	return secretLinkToken.ReplaceAllString(payload, "${1}REDACTED")
+}
+
+func truncateError(err error) string {
+	message := err.Error()
+	if len(message) > maxOutboxError {
+		message = message[:maxOutboxError]
+	}
+	return message
+}
+
+// Resend queues a sent or dead message again, with a fresh set of
+// attempts. It returns the message as queued.
+func (o *Outbox) Resend(tx *gorm.DB, id uint64) (*models.OutboxMessage, error) {
+	var row models.OutboxMessage
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&row, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrOutboxMessageNotFound
+		}
+		return nil, err
+	}
+	if row.Status == models.OutboxPending {
+		return nil, ErrOutboxMessageQueued
+	}
+	row.Status = models.OutboxPending
+	row.Attempts = 0
+	row.NextAttemptAt = time.Now()
+	row.SentAt = nil
+	row.LastError = ""
+	if err := tx.Save(&row).Error; err != nil {
+		return nil, err
+	}
+	return &row, nil
+}
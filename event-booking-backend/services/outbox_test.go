@@ -0,0 +1,123 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/glebarez/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+
+	"event-booking-backend/models"
+)
+
+// stubNotifier fails every send while err is set.
+type stubNotifier struct {
+	err  error
+	sent int
+}
+
+func (n *stubNotifier) Send(msg Message) error {
+	if n.err != nil {
+		return n.err
+	}
+	n.sent++
+	return nil
+}
+
+func newOutboxDB(t *testing.T) *gorm.DB {
+	t.Helper()
+	dsn := fmt.Sprintf("file:%s?mode=memory&cache=shared", strings.ReplaceAll(t.Name(), "/", "_"))
+	db, err := gorm.Open(sqlite.Open(dsn), &gorm.Config{TranslateError: true, Logger: logger.Default.LogMode(logger.Silent)})
+	if err != nil {
+		t.Fatalf("open test database: %v", err)
+	}
+	sqlDB, err := db.DB()
+	if err != nil {
+		t.Fatalf("open test database: %v", err)
+	}
+	sqlDB.SetMaxOpenConns(1)
+	t.Cleanup(func() { sqlDB.Close() })
+	if err := db.AutoMigrate(&models.OutboxMessage{}); err != nil {
+		t.Fatalf("migrate test database: %v", err)
+	}
+	return db
+}
+
+func TestOutboxRetriesUntilDead(t *testing.T) {
+	db := newOutboxDB(t)
+	notifier := &stubNotifier{err: errors.New("451 mailbox busy")}
+	outbox := NewOutbox(db, notifier, OutboxPolicy{MaxAttempts: 3, RetryBase: time.Minute, RetryMax: time.Hour})
+	if err := outbox.Send(Message{Template: TemplateBookingConfirmation, To: []Address{{Email: "jo@example.com"}}}); err != nil {
+		t.Fatal(err)
+	}
+
+	var row models.OutboxMessage
+	var lastDelay time.Duration
+	for attempt := 1; attempt <= 3; attempt++ {
+		before := time.Now()
+		if delivered, err := outbox.deliverNext(); err != nil || !delivered {
+			t.Fatalf("attempt %d: delivered %v, %v", attempt, delivered, err)
+		}
+		db.First(&row)
+		if row.Attempts != attempt || row.LastError != "451 mailbox busy" {
+			t.Fatalf("attempt %d: row = %+v", attempt, row)
+		}
+		if attempt == 3 {
+			break
+		}
+		delay := row.NextAttemptAt.Sub(before)
+		if row.Status != models.OutboxPending || delay <= lastDelay {
+			t.Fatalf("attempt %d: %s, retry in %s after %s; want pending with a longer wait", attempt, row.Status, delay, lastDelay)
+		}
+		lastDelay = delay
+		// Not due yet, so nothing is sent until the wait is over
+		if delivered, _ := outbox.deliverNext(); delivered {
+			t.Fatalf("attempt %d: retried before it was due", attempt)
+		}
+		db.Model(&row).Update("next_attempt_at", time.Now().Add(-time.Second))
+	}
+	if row.Status != models.OutboxDead {
+		t.Fatalf("after %d attempts: %s, want dead", row.Attempts, row.Status)
+	}
+	db.Model(&row).Update("next_attempt_at", time.Now().Add(-time.Second))
+	if delivered, _ := outbox.deliverNext(); delivered {
+		t.Error("dead message was tried again")
+	}
+
+	// Staff resend it with a fresh set of attempts
+	queued, err := outbox.Resend(db, row.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if queued.Status != models.OutboxPending || queued.Attempts != 0 || queued.LastError != "" || queued.NextAttemptAt.After(time.Now()) {
+		t.Errorf("resent = %+v, want pending now with no attempts", queued)
+	}
+	if _, err := outbox.Resend(db, row.ID); !errors.Is(err, ErrOutboxMessageQueued) {
+		t.Errorf("resend of a queued message: %v, want ErrOutboxMessageQueued", err)
+	}
+	notifier.err = nil
+	if delivered, err := outbox.deliverNext(); err != nil || !delivered {
+		t.Fatalf("resend: delivered %v, %v", delivered, err)
+	}
+	db.First(&row)
+	if row.Status != models.OutboxSent || row.SentAt == nil || row.Attempts != 1 || notifier.sent != 1 {
+		t.Errorf("after resend: row = %+v, %d sent; want sent once", row, notifier.sent)
+	}
+	if _, err := outbox.Resend(db, 999); !errors.Is(err, ErrOutboxMessageNotFound) {
+		t.Errorf("resend of a missing message: %v, want ErrOutboxMessageNotFound", err)
+	}
+}
+
+func TestOutboxRetryDelay(t *testing.T) {
+	policy := OutboxPolicy{MaxAttempts: 8, RetryBase: 30 * time.Second, RetryMax: 5 * time.Minute}
+	want := []time.Duration{30 * time.Second, time.Minute, 2 * time.Minute, 4 * time.Minute, 5 * time.Minute, 5 * time.Minute}
+	for i, w := range want {
+		if got := policy.RetryDelay(i + 1); got != w {
+			t.Errorf("RetryDelay(%d) = %s, want %s", i+1, got, w)
+		}
+	}
+}
@@ -0,0 +1,188 @@
+package services
+
+import (
+	"os"
+	"strconv"
+	"time"
+)
+
+// BookingPolicy holds the rules customers are held to when they manage
+// their own bookings. Admins are not bound by it.
+type BookingPolicy struct {
+	// FreeCancellationWindow is how long before the event a customer can
+	// cancel and still get a full refund.
+	FreeCancellationWindow time.Duration
+	// CancellationCutoff is how close to the event self-service
+	// cancellation stops being possible at all.
+	CancellationCutoff time.Duration
+	// LateCancellationRefund is the fraction of the price refunded when
+	// cancelling between the free window and the cutoff.
+	LateCancellationRefund float64
+	// RescheduleCutoff is how close to the event self-service rescheduling
+	// stops being possible.
+	RescheduleCutoff time.Duration
+	// MaxReschedules is how many times a customer may move one booking.
+	MaxReschedules int
+	// RescheduleFee is charged on every customer reschedule.
+	RescheduleFee float64
+}
+
+// CancellationDecision is the outcome of applying the policy to a
+// cancellation request.
+type CancellationDecision struct {
+	Allowed      bool    `json:"allowed"`
+	Reason       string  `json:"reason,omitempty"`
+	RefundAmount float64 `json:"refundAmount"`
+}
+
+// LoadBookingPolicy reads the policy from the environment, falling back to
+// sensible defaults for anything that is unset or malformed.
+func LoadBookingPolicy() BookingPolicy {
+	return BookingPolicy{
+		FreeCancellationWindow: envHours("CANCELLATION_FREE_HOURS", 72),
+		CancellationCutoff:     envHours("CANCELLATION_CUTOFF_HOURS", 24),
+		LateCancellationRefund: envPercent("LATE_CANCELLATION_REFUND_PERCENT", 50),
+		RescheduleCutoff:       envHours("RESCHEDULE_CUTOFF_HOURS", 48),
+		MaxReschedules:         envInt("MAX_RESCHEDULES", 2),
+		RescheduleFee:          envFloat("RESCHEDULE_FEE", 0),
+	}
+}
+
+// Cancellation decides whether a booking starting at eventStart and costing
+// price can be cancelled at now, and how much is refunded.
+func (p BookingPolicy) Cancellation(eventStart time.Time, price float64, now time.Time) CancellationDecision {
+	remaining := eventStart.Sub(now)
+	switch {
+	case remaining <= p.CancellationCutoff:
+		return CancellationDecision{Reason: "Bookings can no longer be cancelled this close to the event"}
+	case remaining > p.FreeCancellationWindow:
+		return CancellationDecision{Allowed: true, RefundAmount: price}
+	default:
+		return CancellationDecision{Allowed: true, RefundAmount: price * p.LateCancellationRefund}
+	}
+}
+
+// Reschedule checks whether a booking starting at eventStart that has
+// already been moved count times may be moved again at now. The returned
+// reason is empty when it may.
+func (p BookingPolicy) Reschedule(eventStart time.Time, count int, now time.Time) string {
+	switch {
+	case eventStart.Sub(now) <= p.RescheduleCutoff:
+		return "Bookings can no longer be rescheduled this close to the event"
+	case count >= p.MaxReschedules:
+		return "This booking has reached the maximum number of reschedules"
+	default:
+		return ""
+	}
+}
+
+func envHours(key string, fallback int) time.Duration {
+	hours := fallback
+	if v, err := strconv.Atoi(os.Getenv(key)); err == nil && v >= 0 {
+		hours = v
+	}
+	return time.Duration(hours) * time.Hour
+}
+
+func envInt(key string, fallback int) int {
+	if v, err := strconv.Atoi(os.Getenv(key)); err == nil && v >= 0 {
+		return v
+	}
+	return fallback
+}
+
+func envFloat(key string, fallback float64) float64 {
+	if v, err := strconv.ParseFloat(os.Getenv(key), 64); err == nil && v >= 0 {
+		return v
+	}
+	return fallback
+}
+
+func envPercent(key string, fallback float64) float64 {
+	percent := fallback
+	if v, err := strconv.ParseFloat(os.Getenv(key), 64); err == nil && v >= 0 && v <= 100 {
+		percent = v
+	}
+	return percent / 100
+}
+
+// TicketSLA is how quickly contact form tickets should be answered and
+// resolved.
+type TicketSLA struct {
+	FirstResponse time.Duration
+	Resolution    time.Duration
+}
+
+// LoadTicketSLA reads the SLA from TICKET_FIRST_RESPONSE_HOURS (default
+// 24) and TICKET_RESOLUTION_HOURS (default 72).
+func LoadTicketSLA() TicketSLA {
+	return TicketSLA{
+		FirstResponse: envHours("TICKET_FIRST_RESPONSE_HOURS", 24),
+		Resolution:    envHours("TICKET_RESOLUTION_HOURS", 72),
+	}
+}
+
+// SpamPolicy limits how much the public booking and contact forms take
+// from one client. A limit of 0 turns that check off.
+type SpamPolicy struct {
+	// Window is the period the per-IP and per-email limits count over.
+	Window           time.Duration
+	BookingsPerIP    int
+	BookingsPerEmail int
+	ContactsPerIP    int
+	ContactsPerEmail int
+	// MaxLinks is how many links a submission may hold before it is held
+	// for review.
+	MaxLinks int
+}
+
+// LoadSpamPolicy reads the limits from the environment: SPAM_WINDOW_MINUTES
+// (default 60), SPAM_BOOKINGS_PER_IP (10), SPAM_BOOKINGS_PER_EMAIL (5),
+// SPAM_CONTACTS_PER_IP (5), SPAM_CONTACTS_PER_EMAIL (3) and SPAM_MAX_LINKS
+// (2).
+func LoadSpamPolicy() SpamPolicy {
+	return SpamPolicy{
+		Window:           time.Duration(envInt("SPAM_WINDOW_MINUTES", 60)) * time.Minute,
+		BookingsPerIP:    envInt("SPAM_BOOKINGS_PER_IP", 10),
+		BookingsPerEmail: envInt("SPAM_BOOKINGS_PER_EMAIL", 5),
+		ContactsPerIP:    envInt("SPAM_CONTACTS_PER_IP", 5),
+		ContactsPerEmail: envInt("SPAM_CONTACTS_PER_EMAIL", 3),
+		MaxLinks:         envInt("SPAM_MAX_LINKS", 2),
+	}
+}
+
+// OutboxPolicy is how the outbox retries mail that failed to send.
+type OutboxPolicy struct {
+	// MaxAttempts is how many sends are tried before a message is dead.
+	MaxAttempts int
+	// RetryBase is the wait after the first failure; it doubles with each
+	// further failure up to RetryMax.
+	RetryBase time.Duration
+	RetryMax  time.Duration
+}
+
+// LoadOutboxPolicy reads OUTBOX_MAX_ATTEMPTS (default 8),
+// OUTBOX_RETRY_BASE_SECONDS (30) and OUTBOX_RETRY_MAX_MINUTES (360).
+func LoadOutboxPolicy() OutboxPolicy {
+	policy := OutboxPolicy{
+		MaxAttempts: envInt("OUTBOX_MAX_ATTEMPTS", 8),
+		RetryBase:   time.Duration(envInt("OUTBOX_RETRY_BASE_SECONDS", 30)) * time.Second,
+		RetryMax:    time.Duration(envInt("OUTBOX_RETRY_MAX_MINUTES", 360)) * time.Minute,
+	}
+	if policy.MaxAttempts < 1 {
+		policy.MaxAttempts = 1
+	}
+	return policy
+}
+
+// RetryDelay is the wait before the next send after attempts failures.
+func (p OutboxPolicy) RetryDelay(attempts int) time.Duration {
+	delay := p.RetryBase
+	for i := 1; i < attempts && delay < p.RetryMax; i++ {
+		delay *= 2
+	}
+	if delay > p.RetryMax {
+		delay = p.RetryMax
+	}
+	return delay
+}
@@ -0,0 +1,99 @@
+package services
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// Conversations a reply address can lead back to.
+const (
+	ReplyToBooking = "b"
+	ReplyToContact = "c"
+)
+
+// ReplyTarget is the conversation a reply address belongs to.
+type ReplyTarget struct {
+	Kind string // ReplyToBooking or ReplyToContact
+	ID   uint64
+}
+
+// ReplyAddressService makes the Reply-To addresses put on customer email,
+// reply+<kind><id>.<signature>@INBOUND_DOMAIN, and reads them back when a
+// reply arrives. The signature stops anyone from writing into a
+// conversation by guessing its number.
+type ReplyAddressService struct {
+	secret []byte
+	domain string
+}
+
+// NewReplyAddressService reads INBOUND_DOMAIN and INBOUND_REPLY_SECRET.
+// Without a domain, replies are not routed: Address returns "" and
+// outgoing mail keeps its default Reply-To.
+func NewReplyAddressService() (*ReplyAddressService, error) {
+	domain := strings.ToLower(strings.TrimSpace(os.Getenv("INBOUND_DOMAIN")))
+	if domain == "" {
+		return &ReplyAddressService{}, nil
+	}
+	secret := os.Getenv("INBOUND_REPLY_SECRET")
+	if secret == "" {
+		return nil, errors.New("INBOUND_REPLY_SECRET must be set when INBOUND_DOMAIN is")
+	}
+	return &ReplyAddressService{secret: []byte(secret), domain: domain}, nil
+}
+
+// Enabled reports whether replies are routed at all.
+func (s *ReplyAddressService) Enabled() bool {
+	return s != nil && s.domain != ""
+}
+
+// Address is the reply address of a conversation, or "" when disabled.
+func (s *ReplyAddressService) Address(kind string, id uint64) string {
+	if !s.Enabled() {
+		return ""
+	}
+	ref := kind + strconv.FormatUint(id, 10)
+	return "reply+" + ref + "." + s.sign(ref) + "@" + s.domain
+}
+
+// Parse reads a reply address back. Mail servers may change the case of
+// the local part, so it is compared in lower case.
+func (s *ReplyAddressService) Parse(address string) (ReplyTarget, bool) {
+	if !s.Enabled() {
+		return ReplyTarget{}, false
+	}
+	address = strings.ToLower(strings.Trim(strings.TrimSpace(address), "<>"))
+	local, domain, ok := strings.Cut(address, "@")
+	if !ok || domain != s.domain {
+		return ReplyTarget{}, false
+	}
+	token, ok := strings.CutPrefix(local, "reply+")
+	if !ok {
+		return ReplyTarget{}, false
+	}
+	ref, signature, ok := strings.Cut(token, ".")
+	if !ok || len(ref) < 2 || !hmac.Equal([]byte(signature), []byte(s.sign(ref))) {
+		return ReplyTarget{}, false
+	}
+	kind := ref[:1]
+	if kind != ReplyToBooking && kind != ReplyToContact {
+		return ReplyTarget{}, false
+	}
+	id, err := strconv.ParseUint(ref[1:], 10, 64)
+	if err != nil {
+		return ReplyTarget{}, false
+	}
+	return ReplyTarget{Kind: kind, ID: id}, true
+}
+
+// sign is a shortened HMAC of ref, in lower case hex so it survives case
+// folding. 80 bits is plenty against guessing.
+func (s *ReplyAddressService) sign(ref string) string {
+	mac := hmac.New(sha256.New, s.secret)
+	mac.Write([]byte("reply-address:" + ref))
+	return hex.EncodeToString(mac.Sum(nil)[:10])
+}
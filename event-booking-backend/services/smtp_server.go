@@ -0,0 +1,220 @@
+package services
+
+import (
+	"bufio"
+	"errors"
+	"io"
+	"log"
+	"net"
+	"net/textproto"
+	"strings"
+	"time"
+)
+
+const (
+	smtpCommandTimeout = 5 * time.Minute
+	smtpMaxRecipients  = 50
+	smtpMaxLineLength  = 2048
+)
+
+// InboundSMTPServer is a small SMTP server that takes in replies for the
+// inbound mail pipeline. It speaks enough of RFC 5321 for mail servers to
+// relay to it and for local clients to test it with (HELO/EHLO, MAIL,
+// RCPT, DATA, RSET, NOOP, QUIT). It has no TLS or authentication, so run
+// it behind the venue's own MTA or a provider relay rather than on the
+// open internet.
+type InboundSMTPServer struct {
+	Addr     string
+	Hostname string
+	// MaxSize is the largest message accepted, in bytes.
+	MaxSize int64
+	// Accept reports whether mail for a recipient is wanted; the rest are
+	// refused at RCPT time.
+	Accept func(recipient string) bool
+	// Deliver handles a message. ErrNoConversation bounces it; any other
+	// error asks the sender to try again later.
+	Deliver func(recipients []string, raw []byte) error
+}
+
+// ListenAndServe listens on Addr and serves connections until the listener
+// fails.
+func (s *InboundSMTPServer) ListenAndServe() error {
+	listener, err := net.Listen("tcp", s.Addr)
+	if err != nil {
+		return err
+	}
+	return s.Serve(listener)
+}
+
+// Serve accepts connections on listener until it fails, and closes it.
+func (s *InboundSMTPServer) Serve(listener net.Listener) error {
+	defer listener.Close()
+	for {
+		conn, err := listener.Accept()
+		if err != nil {
+			var netErr net.Error
+			if errors.As(err, &netErr) && netErr.Timeout() {
+				continue
+			}
+			return err
+		}
+		go s.serve(conn)
+	}
+}
+
+// smtpSession is the state of one connection.
+type smtpSession struct {
+	server     *InboundSMTPServer
+	conn       net.Conn
+	reader     *bufio.Reader
+	writer     *textproto.Writer
+	greeted    bool
+	from       *string
+	recipients []string
+}
+
+func (s *InboundSMTPServer) serve(conn net.Conn) {
+	defer conn.Close()
+	session := &smtpSession{
+		server: s,
+		conn:   conn,
+		reader: bufio.NewReaderSize(conn, smtpMaxLineLength),
+		writer: textproto.NewWriter(bufio.NewWriter(conn)),
+	}
+	session.reply(220, s.Hostname+" ESMTP ready")
+
+	for {
+		conn.SetDeadline(time.Now().Add(smtpCommandTimeout))
+		// A command longer than the buffer is refused rather than
+		// collected, so a client cannot fill memory.
+		line, err := session.reader.ReadSlice('\n')
+		if errors.Is(err, bufio.ErrBufferFull) {
+			session.reply(500, "5.5.2 Line too long")
+			return
+		}
+		if err != nil {
+			return
+		}
+		verb, arg, _ := strings.Cut(strings.TrimRight(string(line), "\r\n"), " ")
+		if !session.handle(strings.ToUpper(verb), strings.TrimSpace(arg)) {
+			return
+		}
+	}
+}
+
+func (c *smtpSession) reply(code int, message string) {
+	c.writer.PrintfLine("%d %s", code, message)
+}
+
+func (c *smtpSession) reset() {
+	c.from = nil
+	c.recipients = nil
+}
+
+// handle runs one command and reports whether to keep the connection.
+func (c *smtpSession) handle(verb, arg string) bool {
+	switch verb {
+	case "HELO":
+		c.greeted = true
+		c.reset()
+		c.reply(250, c.server.Hostname)
+	case "EHLO":
+		c.greeted = true
+		c.reset()
+		c.writer.PrintfLine("250-%s", c.server.Hostname)
+		c.writer.PrintfLine("250-SIZE %d", c.server.MaxSize)
+		c.writer.PrintfLine("250 8BITMIME")
+	case "MAIL":
+		address, ok := smtpPath(arg, "FROM:")
+		switch {
+		case !c.greeted:
+			c.reply(503, "5.5.1 Say HELO first")
+		case c.from != nil:
+			c.reply(503, "5.5.1 Sender already given")
+		case !ok:
+			c.reply(501, "5.5.4 Syntax: MAIL FROM:<address>")
+		default:
+			c.from = &address
+			c.reply(250, "2.1.0 OK")
+		}
+	case "RCPT":
+		address, ok := smtpPath(arg, "TO:")
+		switch {
+		case c.from == nil:
+			c.reply(503, "5.5.1 Need MAIL first")
+		case !ok || address == "":
+			c.reply(501, "5.5.4 Syntax: RCPT TO:<address>")
+		case len(c.recipients) >= smtpMaxRecipients:
+			c.reply(452, "4.5.3 Too many recipients")
+		case !c.server.Accept(address):
+			c.reply(550, "5.1.1 No such mailbox")
+		default:
+			c.recipients = append(c.recipients, address)
+			c.reply(250, "2.1.5 OK")
+		}
+	case "DATA":
+		if len(c.recipients) == 0 {
+			c.reply(503, "5.5.1 Need RCPT first")
+			return true
+		}
+		c.reply(354, "End data with <CR><LF>.<CR><LF>")
+		c.conn.SetDeadline(time.Now().Add(smtpCommandTimeout))
+		data := textproto.NewReader(c.reader).DotReader()
+		raw, err := io.ReadAll(io.LimitReader(data, c.server.MaxSize+1))
+		if err != nil {
+			return false
+		}
+		if int64(len(raw)) > c.server.MaxSize {
+			// Drain the rest so the connection stays in step.
+			if _, err := io.Copy(io.Discard, data); err != nil {
+				return false
+			}
+			c.reply(552, "5.3.4 Message too big")
+		} else {
+			c.deliver(raw)
+		}
+		c.reset()
+	case "RSET":
+		c.reset()
+		c.reply(250, "2.0.0 OK")
+	case "NOOP":
+		c.reply(250, "2.0.0 OK")
+	case "VRFY":
+		c.reply(252, "2.5.0 Cannot verify")
+	case "QUIT":
+		c.reply(221, "2.0.0 Bye")
+		return false
+	default:
+		c.reply(502, "5.5.2 Command not recognized")
+	}
+	return true
+}
+
+func (c *smtpSession) deliver(raw []byte) {
+	err := c.server.Deliver(c.recipients, raw)
+	switch {
+	case err == nil:
+		c.reply(250, "2.0.0 Delivered")
+	case errors.Is(err, ErrNoConversation):
+		c.reply(550, "5.1.1 "+err.Error())
+	default:
+		log.Printf("Failed to deliver inbound mail: %v", err)
+		c.reply(451, "4.3.0 Temporary failure, try again later")
+	}
+}
+
+// smtpPath reads the address out of "FROM:<address> [parameters]".
+func smtpPath(arg, prefix string) (string, bool) {
+	if len(arg) < len(prefix) || !strings.EqualFold(arg[:len(prefix)], prefix) {
+		return "", false
+	}
+	path := strings.TrimSpace(arg[len(prefix):])
+	if !strings.HasPrefix(path, "<") {
+		return "", false
+	}
+	end := strings.Index(path, ">")
+	if end < 0 {
+		return "", false
+	}
+	return path[1:end], true
+}
@@ -0,0 +1,198 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"regexp"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+
+	"event-booking-backend/models"
+)
+
+// counterRetention is how long idle rate limit counters are kept.
+const counterRetention = 24 * time.Hour
+
+// ErrSpamRejected is returned for a submission that is certainly automated,
+// such as one that filled in the honeypot field.
+var ErrSpamRejected = errors.New("submission rejected")
+
+// RateLimitedError is returned when a client has sent too many submissions.
+type RateLimitedError struct {
+	RetryAfter time.Duration
+}
+
+func (e *RateLimitedError) Error() string {
+	return fmt.Sprintf("too many submissions, retry after %s", e.RetryAfter)
+}
+
+// Kinds of public submission.
+const (
+	SubmissionBooking = "booking"
+	SubmissionContact = "contact"
+)
+
+// Submission is what SpamGuard looks at in a public form post.
+type Submission struct {
+	Kind  string // SubmissionBooking or SubmissionContact
+	IP    string
+	Email string
+	// Honeypot is a form field hidden from people; only bots fill it in.
+	Honeypot     string
+	CaptchaToken string
+	// Text is the free text of the submission, checked for links.
+	Text []string
+}
+
+// Review reasons.
+const (
+	ReasonDisposableEmail = "disposable_email"
+	ReasonLinks           = "links"
+)
+
+// disposableDomains are throwaway mailbox services, which real customers
+// rarely book with. DISPOSABLE_EMAIL_DOMAINS adds to the list.
+var disposableDomains = []string{
+	"10minutemail.com", "20minutemail.com", "33mail.com", "dispostable.com",
+	"emailondeck.com", "fakeinbox.com", "getairmail.com", "getnada.com",
+	"guerrillamail.com", "guerrillamail.net", "guerrillamailblock.com",
+	"maildrop.cc", "mailinator.com", "mailnesia.com", "mintemail.com",
+	"mohmal.com", "mytemp.email", "sharklasers.com", "spamgourmet.com",
+	"temp-mail.org", "tempail.com", "tempmail.dev", "tempmailo.com",
+	"tempr.email", "throwawaymail.com", "trashmail.com", "yopmail.com",
+	"yopmail.net",
+}
+
+var linkPattern = regexp.MustCompile(`(?i)https?://|www\.`)
+
+// SpamGuard screens bookings and contact messages from the public: it
+// refuses bots outright (honeypot, CAPTCHA, rate limits) and names what
+// looks off about the rest so it can be held for review. Counters live in
+// Postgres so every server instance sees the same state.
+type SpamGuard struct {
+	db         *gorm.DB
+	policy     SpamPolicy
+	captcha    CaptchaVerifier
+	disposable map[string]bool
+}
+
+// NewSpamGuard builds a guard; captcha may be nil to skip that check.
+func NewSpamGuard(db *gorm.DB, policy SpamPolicy, captcha CaptchaVerifier) *SpamGuard {
+	disposable := map[string]bool{}
+	for _, domain := range disposableDomains {
+		disposable[domain] = true
+	}
+	for _, domain := range strings.Split(os.Getenv("DISPOSABLE_EMAIL_DOMAINS"), ",") {
+		if domain = strings.ToLower(strings.TrimSpace(domain)); domain != "" {
+			disposable[domain] = true
+		}
+	}
+	return &SpamGuard{db: db, policy: policy, captcha: captcha, disposable: disposable}
+}
+
+// Screen checks a submission. It returns ErrSpamRejected, ErrCaptchaFailed
+// or a *RateLimitedError when the submission must be refused; otherwise the
+// reasons to hold it for review, if any.
+func (g *SpamGuard) Screen(s Submission) ([]string, error) {
+	if strings.TrimSpace(s.Honeypot) != "" {
+		log.Printf("Rejected %s from %s: honeypot filled in", s.Kind, s.IP)
+		return nil, ErrSpamRejected
+	}
+
+	perIP, perEmail := g.policy.BookingsPerIP, g.policy.BookingsPerEmail
+	if s.Kind == SubmissionContact {
+		perIP, perEmail = g.policy.ContactsPerIP, g.policy.ContactsPerEmail
+	}
+	email := strings.ToLower(strings.TrimSpace(s.Email))
+	limits := []struct {
+		key   string
+		limit int
+	}{
+		{s.Kind + ":ip:" + s.IP, perIP},
+		{s.Kind + ":email:" + email, perEmail},
+	}
+	for _, l := range limits {
+		if l.limit == 0 {
+			continue
+		}
+		if err := g.hit(l.key, l.limit); err != nil {
+			return nil, err
+		}
+	}
+
+	if g.captcha != nil {
+		if err := g.captcha.Verify(s.CaptchaToken, s.IP); err != nil {
+			return nil, err
+		}
+	}
+
+	var reasons []string
+	if g.IsDisposableEmail(email) {
+		reasons = append(reasons, ReasonDisposableEmail)
+	}
+	links := 0
+	for _, text := range s.Text {
+		links += len(linkPattern.FindAllStringIndex(text, -1))
+	}
+	if links > g.policy.MaxLinks {
+		reasons = append(reasons, ReasonLinks)
+	}
+	return reasons, nil
+}
+
+// IsDisposableEmail reports whether email is at a throwaway mailbox
+// service, or a subdomain of one.
+func (g *SpamGuard) IsDisposableEmail(email string) bool {
+	_, domain, ok := strings.Cut(strings.ToLower(email), "@")
+	for ok && domain != "" {
+		if g.disposable[domain] {
+			return true
+		}
+		_, domain, ok = strings.Cut(domain, ".")
+	}
+	return false
+}
+
+// hit counts one submission against key, returning a *RateLimitedError
+// once more than limit have come in during the current window.
+func (g *SpamGuard) hit(key string, limit int) error {
+	now := time.Now()
+	var counter models.RateLimitCounter
+	// One statement, so concurrent submissions on any instance are each
+	// counted; a window that has run out starts over.
+	err := g.db.Raw(`INSERT INTO rate_limit_counters (key, count, window_start) VALUES (?, 1, ?)
+	ON CONFLICT (key) DO UPDATE SET
+		count = CASE WHEN rate_limit_counters.window_start <= ? THEN 1 ELSE rate_limit_counters.count + 1 END,
+		window_start = CASE WHEN rate_limit_counters.window_start <= ? THEN EXCLUDED.window_start ELSE rate_limit_counters.window_start END
+	RETURNING key, count, window_start`, key, now, now.Add(-g.policy.Window), now.Add(-g.policy.Window)).
+		Scan(&counter).Error
+	if err != nil {
+		return err
+	}
+	if counter.Count > limit {
+		return &RateLimitedError{RetryAfter: time.Until(counter.WindowStart.Add(g.policy.Window))}
+	}
+	return nil
+}
+
+// Run prunes idle rate limit counters until ctx is cancelled.
+func (g *SpamGuard) Run(ctx context.Context) {
+	ticker := time.NewTicker(time.Hour)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			cutoff := time.Now().Add(-g.policy.Window - counterRetention)
+			if err := g.db.Where("window_start < ?", cutoff).Delete(&models.RateLimitCounter{}).Error; err != nil {
+				log.Printf("Failed to prune rate limit counters: %v", err)
+			}
+		}
+	}
+}
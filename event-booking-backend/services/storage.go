@@ -0,0 +1,131 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// MediaStorage keeps uploaded files and says where clients can fetch them.
+// Keys are slash-separated paths chosen by the server, never by the
+// uploader.
+type MediaStorage interface {
+	Put(ctx context.Context, key string, body io.ReadSeeker, size int64, contentType string) error
+	Delete(ctx context.Context, key string) error
+	// URL is the public address of the object stored under key.
+	URL(key string) string
+}
+
+// NewMediaStorage picks the storage backend from MEDIA_STORAGE: "local"
+// (the default) or "s3".
+//
+// Local storage writes under MEDIA_DIR (default ./media); the server itself
+// serves the files at /media, and MEDIA_BASE_URL can put a host or CDN in
+// front of that path.
+//
+// S3 storage works with AWS and S3-compatible servers such as MinIO. It
+// reads S3_BUCKET, S3_REGION (default us-east-1), S3_ACCESS_KEY_ID,
+// S3_SECRET_ACCESS_KEY and S3_ENDPOINT (default the AWS endpoint of the
+// region). Set S3_FORCE_PATH_STYLE=true for servers without virtual-host
+// buckets, and S3_PUBLIC_URL when files are served from somewhere other
+// than the bucket itself. The bucket must allow public reads.
+func NewMediaStorage() (MediaStorage, error) {
+	switch backend := os.Getenv("MEDIA_STORAGE"); backend {
+	case "", "local":
+		dir := os.Getenv("MEDIA_DIR")
+		if dir == "" {
+			dir = "media"
+		}
+		baseURL := os.Getenv("MEDIA_BASE_URL")
+		if baseURL == "" {
+			baseURL = LocalMediaPath
+		}
+		return NewLocalStorage(dir, baseURL)
+	case "s3":
+		return NewS3Storage(S3Config{
+			Endpoint:       os.Getenv("S3_ENDPOINT"),
+			Region:         os.Getenv("S3_REGION"),
+			Bucket:         os.Getenv("S3_BUCKET"),
+			AccessKeyID:    os.Getenv("S3_ACCESS_KEY_ID"),
+			SecretKey:      os.Getenv("S3_SECRET_ACCESS_KEY"),
+			ForcePathStyle: os.Getenv("S3_FORCE_PATH_STYLE") == "true",
+			PublicURL:      os.Getenv("S3_PUBLIC_URL"),
+		})
+	default:
+		return nil, fmt.Errorf("unknown MEDIA_STORAGE %q", backend)
+	}
+}
+
+// LocalMediaPath is where the server serves locally stored media.
+const LocalMediaPath = "/media"
+
+// LocalStorage keeps media on the server's disk.
+type LocalStorage struct {
+	// Dir is the directory files are written under.
+	Dir     string
+	baseURL string
+}
+
+func NewLocalStorage(dir, baseURL string) (*LocalStorage, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, err
+	}
+	return &LocalStorage{Dir: dir, baseURL: strings.TrimSuffix(baseURL, "/")}, nil
+}
+
+func (s *LocalStorage) path(key string) (string, error) {
+	clean := filepath.Clean(filepath.FromSlash(key))
+	if clean == "." || filepath.IsAbs(clean) || strings.HasPrefix(clean, "..") {
+		return "", errors.New("invalid storage key")
+	}
+	return filepath.Join(s.Dir, clean), nil
+}
+
+// Put writes to a temporary file and renames it into place, so a reader
+// never sees half a file.
+func (s *LocalStorage) Put(ctx context.Context, key string, body io.ReadSeeker, size int64, contentType string) error {
+	path, err := s.path(key)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".upload-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := io.Copy(tmp, body); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	if err := os.Chmod(tmp.Name(), 0o644); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+func (s *LocalStorage) Delete(ctx context.Context, key string) error {
+	path, err := s.path(key)
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(path); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	// Drop the object's directory once it is empty.
+	os.Remove(filepath.Dir(path))
+	return nil
+}
+
+func (s *LocalStorage) URL(key string) string {
+	return s.baseURL + "/" + key
+}
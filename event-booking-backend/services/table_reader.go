@@ -0,0 +1,50 @@
+package services
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strings"
+
+	"github.com/xuri/excelize/v2"
+)
+
+// TableFormat guesses the format of an uploaded table from its file name.
+func TableFormat(filename string) (string, error) {
+	switch strings.ToLower(filepath.Ext(filename)) {
+	case ".csv":
+		return ExportCSV, nil
+	case ".xlsx":
+		return ExportXLSX, nil
+	default:
+		return "", errors.New("file must be .csv or .xlsx")
+	}
+}
+
+// ReadTable reads every row of a CSV file or of the first sheet of an XLSX
+// file. XLSX cells are returned raw, so dates and times come back as Excel
+// serial numbers rather than in the display format of the sheet.
+func ReadTable(format string, r io.Reader) ([][]string, error) {
+	switch format {
+	case ExportCSV:
+		reader := csv.NewReader(r)
+		reader.FieldsPerRecord = -1
+		reader.TrimLeadingSpace = true
+		return reader.ReadAll()
+	case ExportXLSX:
+		f, err := excelize.OpenReader(r)
+		if err != nil {
+			return nil, fmt.Errorf("cannot read spreadsheet: %w", err)
+		}
+		defer f.Close()
+		sheets := f.GetSheetList()
+		if len(sheets) == 0 {
+			return nil, errors.New("spreadsheet has no sheets")
+		}
+		return f.GetRows(sheets[0], excelize.Options{RawCellValue: true})
+	default:
+		return nil, fmt.Errorf("unsupported table format %q", format)
+	}
+}
@@ -0,0 +1,157 @@
+package services
+
+import (
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"event-booking-backend/models"
+)
+
+const (
+	// backoffThreshold is how many failures are free before each further
+	// attempt has to wait.
+	backoffThreshold = 3
+	backoffBase      = time.Second
+	backoffMax       = 5 * time.Minute
+	// lockoutThreshold failures lock the key for lockoutDuration.
+	lockoutThreshold = 10
+	lockoutDuration  = 30 * time.Minute
+	// failureWindow is how long failures are remembered after the last one.
+	failureWindow = 24 * time.Hour
+	// ipMultiplier scales the thresholds for IP keys, since many customers
+	// can share one address.
+	ipMultiplier = 5
+)
+
+// LoginThrottle slows down and then locks out repeated failed sign-ins. The
+// counters live in Postgres so every server instance sees the same state.
+type LoginThrottle struct {
+	db *gorm.DB
+}
+
+func NewLoginThrottle(db *gorm.DB) *LoginThrottle {
+	return &LoginThrottle{db: db}
+}
+
+// AccountKey is the throttle key for an account of the given kind.
+func AccountKey(kind, email string) string {
+	return kind + ":" + strings.ToLower(strings.TrimSpace(email))
+}
+
+// IPKey is the throttle key for a client address.
+func IPKey(ip string) string {
+	return "ip:" + ip
+}
+
+// Blocked returns how long the caller must wait before trying again, or
+// zero if none of the keys is currently blocked.
+func (t *LoginThrottle) Blocked(keys ...string) time.Duration {
+	var rows []models.LoginThrottle
+	if err := t.db.Where("key IN ? AND locked_until > ?", keys, time.Now()).Find(&rows).Error; err != nil {
+		log.Printf("Failed to check login throttle: %v", err)
+		return 0
+	}
+
+	var wait time.Duration
+	for _, row := range rows {
+		if remaining := time.Until(*row.LockedUntil); remaining > wait {
+			wait = remaining
+		}
+	}
+	return wait
+}
+
+// RecordFailure counts a failed attempt against each key, applying backoff
+// and recording a security event when a key gets locked out.
+func (t *LoginThrottle) RecordFailure(ip string, keys ...string) {
+	for _, key := range keys {
+		if err := t.recordFailure(ip, key); err != nil {
+			log.Printf("Failed to record login failure for %s: %v", key, err)
+		}
+	}
+}
+
+// Reset clears the failures for key, after a successful sign-in.
+func (t *LoginThrottle) Reset(key string) {
+	if err := t.db.Delete(&models.LoginThrottle{}, "key = ?", key).Error; err != nil {
+		log.Printf("Failed to reset login throttle for %s: %v", key, err)
+	}
+}
+
+// Unlock clears a lockout on behalf of an admin and records who did it, in
+// tx.
+func (t *LoginThrottle) Unlock(tx *gorm.DB, key string, actorID uint, ip string) error {
+	if err := tx.Delete(&models.LoginThrottle{}, "key = ?", key).Error; err != nil {
+		return err
+	}
+	return tx.Create(&models.SecurityEvent{
+		Type:    models.SecurityEventUnlock,
+		Key:     key,
+		IP:      ip,
+		ActorID: &actorID,
+	}).Error
+}
+
+func (t *LoginThrottle) recordFailure(ip, key string) error {
+	return t.db.Transaction(func(tx *gorm.DB) error {
+		// Make sure the row exists, then lock it so concurrent failures on
+		// other instances are counted one at a time.
+		err := tx.Clauses(clause.OnConflict{DoNothing: true}).
+			Create(&models.LoginThrottle{Key: key}).Error
+		if err != nil {
+			return err
+		}
+		var row models.LoginThrottle
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&row, "key = ?", key).Error; err != nil {
+			return err
+		}
+
+		now := time.Now()
+		if now.Sub(row.LastFailureAt) > failureWindow {
+			row.Failures = 0
+		}
+		row.Failures++
+		row.LastFailureAt = now
+
+		backoff, lockout := backoffThreshold, lockoutThreshold
+		if strings.HasPrefix(key, "ip:") {
+			backoff, lockout = backoff*ipMultiplier, lockout*ipMultiplier
+		}
+
+		lockedOut := false
+		switch {
+		case row.Failures >= lockout:
+			// Every failure past the threshold, such as the first one after
+			// a lockout expires, locks the key again.
+			until := now.Add(lockoutDuration)
+			row.LockedUntil = &until
+			lockedOut = true
+		case row.Failures > backoff:
+			delay := backoffBase << (row.Failures - backoff - 1)
+			if delay > backoffMax {
+				delay = backoffMax
+			}
+			until := now.Add(delay)
+			row.LockedUntil = &until
+		}
+		if err := tx.Save(&row).Error; err != nil {
+			return err
+		}
+
+		if lockedOut {
+			log.Printf("Locked out %s after %d failed sign-ins", key, row.Failures)
+			return tx.Create(&models.SecurityEvent{
+				Type:    models.SecurityEventLockout,
+				Key:     key,
+				IP:      ip,
+				Details: fmt.Sprintf("%d failed attempts, locked for %s", row.Failures, lockoutDuration),
+			}).Error
+		}
+		return nil
+	})
+}
@@ -0,0 +1,33 @@
+package services
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"os"
+)
+
+// NewOpaqueToken returns a random token to hand out and the hash to store
+// in its place, so a leaked database row cannot be replayed.
+func NewOpaqueToken() (token, hash string, err error) {
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return "", "", err
+	}
+	token = hex.EncodeToString(buf)
+	return token, HashToken(token), nil
+}
+
+// HashToken returns the stored form of an opaque token.
+func HashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// FrontendURL is the base URL customer-facing links point at.
+func FrontendURL() string {
+	if url := os.Getenv("FRONTEND_URL"); url != "" {
+		return url
+	}
+	return "http://localhost:5173"
+}
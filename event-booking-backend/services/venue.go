@@ -0,0 +1,26 @@
+package services
+
+import (
+	"os"
+	"time"
+)
+
+// VenueTimezone returns the IANA time zone the venue operates in, from
+// VENUE_TIMEZONE. It falls back to UTC when unset or unknown.
+func VenueTimezone() string {
+	if tz := os.Getenv("VENUE_TIMEZONE"); tz != "" {
+		if _, err := time.LoadLocation(tz); err == nil {
+			return tz
+		}
+	}
+	return "UTC"
+}
+
+// VenueLocation returns the location of VenueTimezone.
+func VenueLocation() *time.Location {
+	loc, err := time.LoadLocation(VenueTimezone())
+	if err != nil {
+		return time.UTC
+	}
+	return loc
+}
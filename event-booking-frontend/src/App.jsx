@@ -9,6 +9,7 @@ import BookingConfirmation from './components/pages/BookingConfirmation';
 import AdminLogin from './components/pages/AdminLogin';
 import AdminDashboard from './components/pages/AdminDashboard';
 import NotFound from './components/NotFound';
+import { adminLogout, storeAdminSession } from './services/api';
 import './App.css';
 import './styles/party-theme.css';
 
@@ -29,15 +30,15 @@ function App() {
   }, []);
 
   // Handle admin login
-  const handleAdminLogin = (token) => {
-    localStorage.setItem('adminToken', token);
+  const handleAdminLogin = (session) => {
+    storeAdminSession(session);
     setIsAdminAuthenticated(true);
     setCurrentView('adminDashboard');
   };
 
   // Handle admin logout
-  const handleAdminLogout = () => {
-    localStorage.removeItem('adminToken');
+  const handleAdminLogout = async () => {
+    await adminLogout().catch(() => {});
     setIsAdminAuthenticated(false);
     setCurrentView('home');
   };
@@ -1,6 +1,6 @@
 import { useState, useEffect } from 'react';
 import { motion, AnimatePresence } from 'framer-motion';
-import { fetchAllBookings, updateBookingStatus } from '../../services/api';
+import { fetchBookings, updateBookingStatus, bulkUpdateBookings, downloadExport } from '../../services/api';
 import { BOOKING_STATUS } from '../../config/api';
 import LoadingSpinner from '../LoadingSpinner';
 import { 
@@ -12,30 +12,94 @@ import {
   CurrencyDollarIcon,
   BuildingOfficeIcon,
   ArrowPathIcon,
-  BellAlertIcon
+  BellAlertIcon,
+  MagnifyingGlassIcon,
+  ArrowDownTrayIcon
 } from '@heroicons/react/24/outline';
 
+const PAGE_SIZE = 50;
+
+// localDate formats a date as YYYY-MM-DD in the browser's time zone.
+const localDate = (date) => {
+  const pad = (n) => String(n).padStart(2, '0');
+  return `${date.getFullYear()}-${pad(date.getMonth() + 1)}-${pad(date.getDate())}`;
+};
+
+// tabFilters returns the server-side filters and default order for a tab.
+const tabFilters = (tab) => {
+  const today = new Date();
+  const yesterday = new Date(today);
+  yesterday.setDate(today.getDate() - 1);
+
+  switch (tab) {
+    case 'upcoming':
+      return { dateFrom: localDate(today), status: 'pending,confirmed', sort: 'eventDate', order: 'asc' };
+    case 'past':
+      return { dateTo: localDate(yesterday), sort: 'eventDate', order: 'desc' };
+    case 'cancelled':
+      return { status: 'cancelled', sort: 'createdAt', order: 'desc' };
+    default:
+      return {};
+  }
+};
+
+const SORT_OPTIONS = [
+  { value: '', label: 'Default order' },
+  { value: 'eventDate:asc', label: 'Event date (earliest)' },
+  { value: 'eventDate:desc', label: 'Event date (latest)' },
+  { value: 'createdAt:desc', label: 'Newest bookings' },
+  { value: 'totalPrice:desc', label: 'Price (highest)' },
+  { value: 'totalPrice:asc', label: 'Price (lowest)' },
+  { value: 'customerName:asc', label: 'Customer name' },
+];
+
 const AdminDashboard = ({ onLogout, navigateTo }) => {
   const [bookings, setBookings] = useState([]);
+  const [nextCursor, setNextCursor] = useState(null);
+  const [total, setTotal] = useState(0);
   const [loading, setLoading] = useState(true);
+  const [loadingMore, setLoadingMore] = useState(false);
   const [error, setError] = useState(null);
   const [selectedTab, setSelectedTab] = useState('upcoming');
+  const [search, setSearch] = useState('');
+  const [query, setQuery] = useState('');
+  const [sortOption, setSortOption] = useState('');
   const [notification, setNotification] = useState(null);
   const [isRefreshing, setIsRefreshing] = useState(false);
+  const [selected, setSelected] = useState([]);
+  const [bulkTag, setBulkTag] = useState('');
+
+  // Wait for the admin to stop typing before searching
+  useEffect(() => {
+    const timer = setTimeout(() => setQuery(search.trim()), 300);
+    return () => clearTimeout(timer);
+  }, [search]);
 
   useEffect(() => {
     loadBookings();
-  }, []);
+  }, [selectedTab, query, sortOption]);
+
+  const requestParams = (cursor) => {
+    const params = { ...tabFilters(selectedTab), q: query, limit: PAGE_SIZE, cursor };
+    if (sortOption) {
+      const [sort, order] = sortOption.split(':');
+      params.sort = sort;
+      params.order = order;
+    }
+    return params;
+  };
 
   const loadBookings = async () => {
     try {
       setLoading(true);
-      const adminToken = localStorage.getItem('adminToken');
-      if (!adminToken) {
-        throw new Error('Admin token not found');
+      if (!localStorage.getItem('adminToken')) {
+        throw new Error('Admin session not found');
       }
-      const data = await fetchAllBookings(adminToken);
-      setBookings(data);
+      const page = await fetchBookings(requestParams());
+      setBookings(page.bookings);
+      setSelected([]);
+      setNextCursor(page.nextCursor || null);
+      setTotal(page.total);
     } catch (err) {
       setError(err.message);
     } finally {
@@ -43,11 +107,24 @@ const AdminDashboard = ({ onLogout, navigateTo }) => {
     }
   };
 
+  const loadMore = async () => {
+    try {
+      setLoadingMore(true);
+      const page = await fetchBookings(requestParams(nextCursor));
+      setBookings((current) => [...current, ...page.bookings]);
+      setNextCursor(page.nextCursor || null);
+      setTotal(page.total);
+    } catch (err) {
+      setError(err.message);
+    } finally {
+      setLoadingMore(false);
+    }
+  };
+
   const handleStatusUpdate = async (bookingId, newStatus) => {
     try {
       setIsRefreshing(true);
-      const adminToken = localStorage.getItem('adminToken');
-      await updateBookingStatus(bookingId, newStatus, adminToken);
+      await updateBookingStatus(bookingId, newStatus);
       await loadBookings(); // Refresh the list
       
       // Show notification
@@ -67,31 +144,52 @@ const AdminDashboard = ({ onLogout, navigateTo }) => {
     }
   };
 
+  const toggleSelected = (bookingId) => {
+    setSelected((current) =>
+      current.includes(bookingId) ? current.filter((id) => id !== bookingId) : [...current, bookingId]
+    );
+  };
+
+  const toggleAll = () => {
+    setSelected(selected.length === bookings.length ? [] : bookings.map((b) => b.id));
+  };
+
+  const handleBulkAction = async (action, options = {}) => {
+    try {
+      setIsRefreshing(true);
+      const result = await bulkUpdateBookings(selected, action, options);
+      await loadBookings();
+      const failures = result.results.filter((r) => !r.ok);
+      setNotification({
+        message: failures.length === 0
+          ? `${result.succeeded} bookings updated`
+          : `${result.succeeded} updated, ${result.failed} failed: ${failures.map((r) => `#${r.id} ${r.error}`).join('; ')}`,
+        type: failures.length === 0 ? 'success' : 'warning'
+      });
+      setTimeout(() => {
+        setNotification(null);
+      }, failures.length === 0 ? 3000 : 8000);
+    } catch (err) {
+      setError(err.message);
+    } finally {
+      setIsRefreshing(false);
+    }
+  };
+
+  const handleExport = async (format) => {
+    try {
+      await downloadExport('bookings', format, requestParams());
+    } catch (err) {
+      setError(err.message);
+    }
+  };
+
   const refreshData = async () => {
     setIsRefreshing(true);
     await loadBookings();
     setIsRefreshing(false);
   };
 
-  const filterBookings = () => {
-    const today = new Date();
-    today.setHours(0, 0, 0, 0);
-
-    return bookings.filter(booking => {
-      const bookingDate = new Date(booking.eventDate);
-      switch (selectedTab) {
-        case 'upcoming':
-          return bookingDate >= today && booking.status !== BOOKING_STATUS.CANCELLED;
-        case 'past':
-          return bookingDate < today;
-        case 'cancelled':
-          return booking.status === BOOKING_STATUS.CANCELLED;
-        default:
-          return true;
-      }
-    });
-  };
-
   const getStatusIcon = (status) => {
     switch (status) {
       case BOOKING_STATUS.CONFIRMED:
@@ -114,7 +212,7 @@ const AdminDashboard = ({ onLogout, navigateTo }) => {
     }
   };
 
-  if (loading) return <LoadingSpinner />;
+  if (loading && bookings.length === 0) return <LoadingSpinner />;
 
   return (
     <motion.div 
@@ -220,11 +318,87 @@ const AdminDashboard = ({ onLogout, navigateTo }) => {
           </div>
         </div>
 
+        <div className="mt-4 flex flex-col sm:flex-row sm:items-center sm:justify-between gap-3">
+          <div className="relative flex-1 max-w-md">
+            <MagnifyingGlassIcon className="pointer-events-none absolute left-3 top-2.5 h-5 w-5 text-gray-400" />
+            <input
+              type="search"
+              value={search}
+              onChange={(e) => setSearch(e.target.value)}
+              placeholder="Search name, email or phone"
+              className="block w-full rounded-md border-gray-300 py-2 pl-10 pr-3 text-sm focus:border-primary-500 focus:outline-none focus:ring-primary-500"
+            />
+          </div>
+          <div className="flex items-center gap-3">
+            <span className="text-sm text-gray-500">
+              Showing {bookings.length} of {total}
+            </span>
+            {['csv', 'xlsx'].map((format) => (
+              <button
+                key={format}
+                onClick={() => handleExport(format)}
+                className="inline-flex items-center px-3 py-2 border border-gray-300 rounded-md shadow-sm text-sm font-medium text-gray-700 bg-white hover:bg-gray-50"
+              >
+                <ArrowDownTrayIcon className="-ml-0.5 mr-1.5 h-4 w-4 text-gray-500" />
+                {format.toUpperCase()}
+              </button>
+            ))}
+            <select
+              value={sortOption}
+              onChange={(e) => setSortOption(e.target.value)}
+              className="rounded-md border-gray-300 py-2 pl-3 pr-10 text-sm focus:border-primary-500 focus:outline-none focus:ring-primary-500"
+            >
+              {SORT_OPTIONS.map((option) => (
+                <option key={option.value} value={option.value}>{option.label}</option>
+              ))}
+            </select>
+          </div>
+        </div>
+
+        {selected.length > 0 && (
+          <div className="mt-4 flex flex-wrap items-center gap-2 rounded-md bg-gray-50 border border-gray-200 p-3">
+            <span className="text-sm font-medium text-gray-700 mr-2">{selected.length} selected</span>
+            <button
+              onClick={() => handleBulkAction('confirm')}
+              disabled={isRefreshing}
+              className="inline-flex items-center px-2.5 py-1.5 border border-transparent text-xs font-medium rounded text-white bg-green-600 hover:bg-green-700"
+            >
+              <CheckCircleIcon className="mr-1 h-4 w-4" />
+              Confirm
+            </button>
+            <button
+              onClick={() => handleBulkAction('cancel')}
+              disabled={isRefreshing}
+              className="inline-flex items-center px-2.5 py-1.5 border border-transparent text-xs font-medium rounded text-white bg-red-600 hover:bg-red-700"
+            >
+              <XCircleIcon className="mr-1 h-4 w-4" />
+              Cancel
+            </button>
+            <input
+              type="text"
+              value={bulkTag}
+              onChange={(e) => setBulkTag(e.target.value)}
+              placeholder="Tag"
+              className="rounded-md border-gray-300 py-1 px-2 text-xs focus:border-primary-500 focus:outline-none focus:ring-primary-500"
+            />
+            {['tag', 'untag'].map((action) => (
+              <button
+                key={action}
+                onClick={() => handleBulkAction(action, { tags: [bulkTag] })}
+                disabled={isRefreshing || !bulkTag.trim()}
+                className="inline-flex items-center px-2.5 py-1.5 border border-gray-300 text-xs font-medium rounded text-gray-700 bg-white hover:bg-gray-50 capitalize"
+              >
+                {action === 'tag' ? 'Add tag' : 'Remove tag'}
+              </button>
+            ))}
+          </div>
+        )}
+
         <div className="mt-6">
           <div className="flex flex-col">
             <div className="-my-2 overflow-x-auto sm:-mx-6 lg:-mx-8">
               <div className="py-2 align-middle inline-block min-w-full sm:px-6 lg:px-8">
-                {filterBookings().length === 0 ? (
+                {bookings.length === 0 ? (
                   <div className="text-center py-12 bg-white shadow overflow-hidden rounded-lg">
                     <p className="text-gray-500">No bookings found in this category</p>
                   </div>
@@ -233,6 +407,14 @@ const AdminDashboard = ({ onLogout, navigateTo }) => {
                     <table className="min-w-full divide-y divide-gray-200">
                       <thead className="bg-gray-50">
                         <tr>
+                          <th scope="col" className="pl-6 py-3 text-left">
+                            <input
+                              type="checkbox"
+                              checked={selected.length === bookings.length}
+                              onChange={toggleAll}
+                              aria-label="Select all bookings"
+                            />
+                          </th>
                           <th scope="col" className="px-6 py-3 text-left text-xs font-medium text-gray-500 uppercase tracking-wider">
                             Customer
                           </th>
@@ -248,7 +430,7 @@ const AdminDashboard = ({ onLogout, navigateTo }) => {
                         </tr>
                       </thead>
                       <tbody className="bg-white divide-y divide-gray-200">
-                        {filterBookings().map((booking) => (
+                        {bookings.map((booking) => (
                           <motion.tr 
                             key={booking.id}
                             initial={{ opacity: 0 }}
@@ -256,6 +438,14 @@ const AdminDashboard = ({ onLogout, navigateTo }) => {
                             transition={{ duration: 0.3 }}
                             whileHover={{ backgroundColor: 'rgba(249, 250, 251, 0.5)' }}
                           >
+                            <td className="pl-6 py-4">
+                              <input
+                                type="checkbox"
+                                checked={selected.includes(booking.id)}
+                                onChange={() => toggleSelected(booking.id)}
+                                aria-label={`Select booking ${booking.id}`}
+                              />
+                            </td>
                             <td className="px-6 py-4 whitespace-nowrap">
                               <div className="text-sm font-medium text-gray-900">{booking.customerName}</div>
                               <div className="text-sm text-gray-500">{booking.customerEmail}</div>
@@ -290,6 +480,13 @@ const AdminDashboard = ({ onLogout, navigateTo }) => {
                                   {booking.status}
                                 </span>
                               </div>
+                              {booking.tags?.length > 0 && (
+                                <div className="mt-1 flex flex-wrap gap-1">
+                                  {booking.tags.map((tag) => (
+                                    <span key={tag} className="px-1.5 text-xs rounded bg-gray-100 text-gray-600">{tag}</span>
+                                  ))}
+                                </div>
+                              )}
                             </td>
                             <td className="px-6 py-4 whitespace-nowrap text-sm text-gray-500">
                               {booking.status === BOOKING_STATUS.PENDING && (
@@ -321,6 +518,17 @@ const AdminDashboard = ({ onLogout, navigateTo }) => {
                     </table>
                   </div>
                 )}
+                {nextCursor && (
+                  <div className="mt-4 text-center">
+                    <button
+                      onClick={loadMore}
+                      disabled={loadingMore}
+                      className={`inline-flex items-center px-4 py-2 border border-gray-300 rounded-md shadow-sm text-sm font-medium text-gray-700 bg-white hover:bg-gray-50 ${loadingMore ? 'opacity-50 cursor-not-allowed' : ''}`}
+                    >
+                      {loadingMore ? 'Loading...' : 'Load more'}
+                    </button>
+                  </div>
+                )}
               </div>
             </div>
           </div>
@@ -1,17 +1,24 @@
 import { useState } from 'react';
 import { motion } from 'framer-motion';
+import { adminLogin } from '../../services/api';
 
 const AdminLogin = ({ onLogin, navigateTo }) => {
-  const [token, setToken] = useState('');
+  const [email, setEmail] = useState('');
+  const [password, setPassword] = useState('');
   const [error, setError] = useState('');
+  const [submitting, setSubmitting] = useState(false);
 
   const handleSubmit = async (e) => {
     e.preventDefault();
+    setError('');
+    setSubmitting(true);
     try {
-      // In a real app, you would validate the token with the backend
-      onLogin(token);
+      const session = await adminLogin(email, password);
+      onLogin(session);
     } catch (err) {
-      setError('Invalid admin token');
+      setError(err.message || 'Invalid email or password');
+    } finally {
+      setSubmitting(false);
     }
   };
 
@@ -28,24 +35,41 @@ const AdminLogin = ({ onLogin, navigateTo }) => {
             Admin Login
           </h2>
           <p className="mt-2 text-center text-sm text-gray-600">
-            Enter your admin token to access the dashboard
+            Sign in with your staff account to access the dashboard
           </p>
         </div>
         <form className="mt-8 space-y-6" onSubmit={handleSubmit}>
-          <div className="rounded-md shadow-sm -space-y-px">
+          <div className="rounded-md shadow-sm space-y-2">
             <div>
-              <label htmlFor="admin-token" className="sr-only">
-                Admin Token
+              <label htmlFor="admin-email" className="sr-only">
+                Email
               </label>
               <input
-                id="admin-token"
-                name="token"
+                id="admin-email"
+                name="email"
+                type="email"
+                autoComplete="username"
+                required
+                value={email}
+                onChange={(e) => setEmail(e.target.value)}
+                className="appearance-none rounded-md relative block w-full px-3 py-2 border border-gray-300 placeholder-gray-500 text-gray-900 focus:outline-none focus:ring-primary-500 focus:border-primary-500 focus:z-10 sm:text-sm"
+                placeholder="Email"
+              />
+            </div>
+            <div>
+              <label htmlFor="admin-password" className="sr-only">
+                Password
+              </label>
+              <input
+                id="admin-password"
+                name="password"
                 type="password"
+                autoComplete="current-password"
                 required
-                value={token}
-                onChange={(e) => setToken(e.target.value)}
+                value={password}
+                onChange={(e) => setPassword(e.target.value)}
                 className="appearance-none rounded-md relative block w-full px-3 py-2 border border-gray-300 placeholder-gray-500 text-gray-900 focus:outline-none focus:ring-primary-500 focus:border-primary-500 focus:z-10 sm:text-sm"
-                placeholder="Enter admin token"
+                placeholder="Password"
               />
             </div>
           </div>
@@ -67,9 +91,10 @@ const AdminLogin = ({ onLogin, navigateTo }) => {
               whileHover={{ scale: 1.02 }}
               whileTap={{ scale: 0.98 }}
               type="submit"
+              disabled={submitting}
               className="group relative w-full flex justify-center py-2 px-4 border border-transparent text-sm font-medium rounded-md text-white bg-gradient-to-r from-party-purple to-party-magenta hover:from-party-purple-dark hover:to-party-magenta-dark focus:outline-none focus:ring-2 focus:ring-offset-2 focus:ring-primary-500"
             >
-              Sign in
+              {submitting ? 'Signing in...' : 'Sign in'}
             </motion.button>
           </div>
           
@@ -1,11 +1,11 @@
-import { useState } from 'react';
+import { useState, useEffect } from 'react';
 import { useForm } from 'react-hook-form';
 import { motion } from 'framer-motion';
 import DatePicker from 'react-datepicker';
 import "react-datepicker/dist/react-datepicker.css";
 import { useFormPersistence } from '../../hooks/useFormPersistence';
-import { createBooking } from '../../services/api';
-import { HALL_TYPES, TIME_SLOTS } from '../../config/api';
+import { createBooking, fetchHalls, searchHalls } from '../../services/api';
+import { SEATING_LAYOUTS, TIME_SLOTS } from '../../config/api';
 import LoadingSpinner from '../LoadingSpinner';
 
 const validateDate = (date) => {
@@ -24,7 +24,12 @@ const validateTime = (time, selectedDate) => {
 };
 
 const Booking = ({ onSubmit, navigateTo }) => {
+  const [halls, setHalls] = useState([]);
   const [selectedHall, setSelectedHall] = useState('');
+  const [selectedLayout, setSelectedLayout] = useState('');
+  // Prices of the halls free for the chosen date and times, by hall ID;
+  // null until a date and start time are chosen
+  const [available, setAvailable] = useState(null);
   const [selectedDate, setSelectedDate] = useState(null);
   const [selectedTime, setSelectedTime] = useState('');
   const [selectedEndTime, setSelectedEndTime] = useState('');
@@ -38,6 +43,46 @@ const Booking = ({ onSubmit, navigateTo }) => {
 
   const guestCount = watch('guestCount');
 
+  useEffect(() => {
+    fetchHalls()
+      .then(setHalls)
+      .catch(() => setError('Failed to load halls. Please try again later.'));
+  }, []);
+
+  useEffect(() => {
+    if (!selectedDate || !selectedTime) {
+      setAvailable(null);
+      return;
+    }
+    const date = [
+      selectedDate.getFullYear(),
+      String(selectedDate.getMonth() + 1).padStart(2, '0'),
+      String(selectedDate.getDate()).padStart(2, '0'),
+    ].join('-');
+    let cancelled = false;
+    searchHalls({ date, start: selectedTime, end: selectedEndTime })
+      .then((results) => {
+        if (cancelled) return;
+        const prices = {};
+        results.forEach((result) => {
+          prices[result.id] = result.price;
+        });
+        setAvailable(prices);
+      })
+      .catch(() => {
+        if (!cancelled) setAvailable(null);
+      });
+    return () => {
+      cancelled = true;
+    };
+  }, [selectedDate, selectedTime, selectedEndTime]);
+
+  // The chosen layout, if any, sets how many guests the hall holds
+  const hall = halls.find((h) => h.id === selectedHall);
+  const layouts = hall?.layouts || [];
+  const layout = layouts.find((l) => l.layout === selectedLayout);
+  const maxGuests = layout ? layout.capacity : hall?.capacity;
+
   // Only show validation errors after the field has been touched
   const allErrors = {
     ...Object.keys(reactHookFormErrors).reduce((acc, key) => {
@@ -58,12 +103,14 @@ const Booking = ({ onSubmit, navigateTo }) => {
       return;
     }
 
+    if (available && available[selectedHall] === undefined) {
+      setError('The selected hall is not free at this time. Please choose another hall or time.');
+      return;
+    }
+
     setIsSubmitting(true);
     setError(null);
 
-    const hall = selectedHall === 'hall1' ? HALL_TYPES.HALL1 : HALL_TYPES.HALL2;
-    const totalPrice = hall.basePrice;
-
     // Format the date with the selected time
     const [hours, minutes] = selectedTime.split(':');
     const eventDateTime = new Date(selectedDate);
@@ -75,12 +122,12 @@ const Booking = ({ onSubmit, navigateTo }) => {
       customerEmail: data.customerEmail,
       customerPhone: data.customerPhone,
       guestCount: parseInt(data.guestCount),
+      layout: selectedLayout,
       eventDate: eventDateTime.toISOString(),
       startTime: selectedTime,
       endTime: selectedEndTime,
       specialRequests: data.specialRequests || '',
-      status: 'pending',
-      totalPrice: totalPrice
+      website: data.website || '',
     };
 
     try {
@@ -138,6 +185,7 @@ const Booking = ({ onSubmit, navigateTo }) => {
                 value={selectedHall}
                 onChange={(e) => {
                   setSelectedHall(e.target.value);
+                  setSelectedLayout(''); // Layouts differ between halls
                   setSelectedTime(''); // Reset time when hall changes
                   setSelectedEndTime(''); // Reset end time when hall changes
                 }}
@@ -149,16 +197,51 @@ const Booking = ({ onSubmit, navigateTo }) => {
                 aria-describedby={allErrors.hallId ? "hall-error" : undefined}
               >
                 <option value="">Choose a hall</option>
-                <option value="hall1">Hall 1 (Small) - Up to 10 people</option>
-                <option value="hall2">Hall 2 (Large) - Up to 30 people</option>
+                {halls.map((h) => (
+                  <option key={h.id} value={h.id} disabled={available && available[h.id] === undefined}>
+                    {h.name} - Up to {h.capacity} people
+                    {available && (available[h.id] === undefined ? ' (not available)' : ` - ₹${available[h.id]}`)}
+                  </option>
+                ))}
               </select>
               {allErrors.hallId && (
                 <p id="hall-error" className="mt-1 text-sm text-red-600" role="alert">
                   {allErrors.hallId}
                 </p>
               )}
+              {hall?.amenities?.length > 0 && (
+                <div className="mt-2 flex flex-wrap gap-2">
+                  {hall.amenities.map((amenity) => (
+                    <span key={amenity.id} className="px-2 py-1 text-xs rounded-full bg-indigo-50 text-indigo-700">
+                      {amenity.name}
+                    </span>
+                  ))}
+                </div>
+              )}
             </div>
 
+            {/* Seating Layout */}
+            {layouts.length > 0 && (
+              <div>
+                <label htmlFor="layout" className="block text-sm font-medium text-gray-700">
+                  Seating Layout
+                </label>
+                <select
+                  id="layout"
+                  value={selectedLayout}
+                  onChange={(e) => setSelectedLayout(e.target.value)}
+                  className="mt-1 block w-full py-2 px-3 border border-gray-300 bg-white rounded-md shadow-sm focus:outline-none focus:ring-primary-500 focus:border-primary-500"
+                >
+                  <option value="">No preference - up to {hall.capacity} people</option>
+                  {layouts.map((l) => (
+                    <option key={l.layout} value={l.layout}>
+                      {SEATING_LAYOUTS[l.layout] || l.layout} - Up to {l.capacity} people
+                    </option>
+                  ))}
+                </select>
+              </div>
+            )}
+
             {/* Date Selection */}
             <div>
               <label htmlFor="date" className="block text-sm font-medium text-gray-700">
@@ -345,9 +428,9 @@ const Booking = ({ onSubmit, navigateTo }) => {
                       value: 1,
                       message: 'Must have at least 1 guest',
                     },
-                    max: {
-                      value: selectedHall === 'hall1' ? 10 : 30,
-                      message: `Maximum ${selectedHall === 'hall1' ? '10' : '30'} guests allowed`,
+                    max: maxGuests && {
+                      value: maxGuests,
+                      message: `Maximum ${maxGuests} guests allowed`,
                     },
                   })}
                   className={`mt-1 block w-full py-2 px-3 border ${
@@ -375,6 +458,12 @@ const Booking = ({ onSubmit, navigateTo }) => {
                   placeholder="Any special requirements or requests..."
                 />
               </div>
+
+              {/* Honeypot: hidden from people, so only bots fill it in */}
+              <div className="hidden" aria-hidden="true">
+                <label htmlFor="website">Website</label>
+                <input id="website" type="text" tabIndex={-1} autoComplete="off" {...register('website')} />
+              </div>
             </div>
 
             {/* Submit Button */}
@@ -1,6 +1,9 @@
+import { useState, useEffect } from 'react';
 import { motion } from 'framer-motion';
 import { useInView } from 'react-intersection-observer';
 import { SparklesIcon } from '@heroicons/react/24/outline';
+import { fetchHalls } from '../../services/api';
+import { mediaUrl, SEATING_LAYOUTS } from '../../config/api';
 import '../../../src/styles/party-theme.css';
 
 const Packages = ({ navigateTo }) => {
@@ -8,6 +11,24 @@ const Packages = ({ navigateTo }) => {
     triggerOnce: true,
     threshold: 0.1
   });
+  const [gallery, setGallery] = useState({});
+  const [layouts, setLayouts] = useState({});
+
+  // Photos and seating layouts come from the halls managed in the admin API
+  useEffect(() => {
+    fetchHalls()
+      .then((halls) => {
+        const byHall = {};
+        const layoutsByHall = {};
+        halls.forEach((hall) => {
+          byHall[hall.id] = (hall.media || []).filter((m) => m.kind === 'image');
+          layoutsByHall[hall.id] = hall.layouts || [];
+        });
+        setGallery(byHall);
+        setLayouts(layoutsByHall);
+      })
+      .catch(() => setGallery({}));
+  }, []);
 
   const containerVariants = {
     hidden: { opacity: 0 },
@@ -33,6 +54,7 @@ const Packages = ({ navigateTo }) => {
   const packages = [
     {
       id: 1,
+      hallId: 'hall1',
       name: 'Hall 1 (Small)',
       capacity: '10 people',
       price: '₹2000/hour',
@@ -63,6 +85,7 @@ const Packages = ({ navigateTo }) => {
     },
     {
       id: 2,
+      hallId: 'hall2',
       name: 'Hall 2 (Large)',
       capacity: '30 people',
       price: '₹5000/hour',
@@ -136,8 +159,31 @@ const Packages = ({ navigateTo }) => {
                 <span className="text-4xl font-extrabold text-gray-900">{pkg.price}</span>
               </div>
               <p className="mt-2 text-lg text-gray-500">Up to {pkg.capacity}</p>
+              {layouts[pkg.hallId]?.length > 0 && (
+                <p className="mt-1 text-sm text-gray-500">
+                  {layouts[pkg.hallId]
+                    .map((l) => `${SEATING_LAYOUTS[l.layout] || l.layout}: ${l.capacity}`)
+                    .join(' · ')}
+                </p>
+              )}
             </div>
 
+            {gallery[pkg.hallId]?.length > 0 && (
+              <div className="mt-6 grid grid-cols-3 gap-2">
+                {gallery[pkg.hallId].slice(0, 6).map((image) => (
+                  <a key={image.id} href={mediaUrl(image.variants?.display || image.url)} target="_blank" rel="noreferrer">
+                    <img
+                      src={mediaUrl(image.variants?.thumb || image.url)}
+                      alt={image.caption || pkg.name}
+                      title={image.caption}
+                      loading="lazy"
+                      className="h-24 w-full rounded-lg object-cover"
+                    />
+                  </a>
+                ))}
+              </div>
+            )}
+
             <div className="mt-8">
               <h3 className="text-lg font-semibold text-gray-900">Features:</h3>
               <ul className="mt-4 space-y-2">
@@ -7,6 +7,10 @@ export const API_ENDPOINTS = {
   ADMIN: `${API_BASE_URL}/admin`,
 };
 
+// mediaUrl resolves a media URL from the API, which is relative to the API
+// server when media is stored on its disk.
+export const mediaUrl = (url) => new URL(url, API_BASE_URL).toString();
+
 export const API_TIMEOUT = 10000; // 10 seconds
 
 export const API_HEADERS = {
@@ -14,31 +18,15 @@ export const API_HEADERS = {
   'Accept': 'application/json',
 };
 
-export const HALL_TYPES = {
-  HALL1: {
-    id: 'hall1',
-    name: 'Hall 1 (Small)',
-    capacity: 10,
-    basePrice: 1000,
-    features: [
-      'Cozy atmosphere',
-      'Modern audio system',
-      'Comfortable seating',
-      'Basic decorations included',
-    ],
-  },
-  HALL2: {
-    id: 'hall2',
-    name: 'Hall 2 (Large)',
-    capacity: 30,
-    basePrice: 2000,
-    features: [
-      'Spacious layout',
-      'Premium sound system',
-      'Projector setup',
-      'Custom decoration options',
-    ],
-  },
+// SEATING_LAYOUTS names the layouts a hall can offer; each hall lists its
+// own with a capacity.
+export const SEATING_LAYOUTS = {
+  theatre: 'Theatre',
+  banquet: 'Banquet',
+  cocktail: 'Cocktail',
+  classroom: 'Classroom',
+  boardroom: 'Boardroom',
+  u_shape: 'U-shape',
 };
 
 export const TIME_SLOTS = [
@@ -13,6 +13,20 @@ export const fetchHalls = async () => {
   }
 };
 
+// searchHalls finds the halls free for a time window, with their price.
+// params: date (YYYY-MM-DD), start, end, guests, layout, amenity, maxPrice.
+export const searchHalls = async (params) => {
+  const query = new URLSearchParams(
+    Object.entries(params).filter(([, value]) => value !== undefined && value !== null && value !== '')
+  );
+  const response = await fetch(`${API_ENDPOINTS.HALLS}/search?${query}`);
+  if (!response.ok) {
+    const errorData = await response.json().catch(() => ({}));
+    throw new Error(errorData.error || 'Failed to search halls');
+  }
+  return response.json();
+};
+
 export const createBooking = async (bookingData) => {
   try {
     const response = await fetch(API_ENDPOINTS.BOOKINGS, {
@@ -51,14 +65,85 @@ export const checkAvailability = async (hallId, date, time) => {
 };
 
 // Admin API calls
-export const fetchAllBookings = async (adminToken) => {
+export const adminLogin = async (email, password) => {
+  const response = await fetch(`${API_ENDPOINTS.ADMIN}/auth/login`, {
+    method: 'POST',
+    headers: API_HEADERS,
+    body: JSON.stringify({ email, password }),
+  });
+
+  const data = await response.json().catch(() => ({}));
+  if (!response.ok) {
+    throw new Error(data.error || 'Login failed');
+  }
+  return data;
+};
+
+export const storeAdminSession = (session) => {
+  localStorage.setItem('adminToken', session.accessToken);
+  localStorage.setItem('adminRefreshToken', session.refreshToken);
+};
+
+export const clearAdminSession = () => {
+  localStorage.removeItem('adminToken');
+  localStorage.removeItem('adminRefreshToken');
+};
+
+const refreshAdminSession = async () => {
+  const refreshToken = localStorage.getItem('adminRefreshToken');
+  if (!refreshToken) {
+    return false;
+  }
+
+  const response = await fetch(`${API_ENDPOINTS.ADMIN}/auth/refresh`, {
+    method: 'POST',
+    headers: API_HEADERS,
+    body: JSON.stringify({ refreshToken }),
+  });
+  if (!response.ok) {
+    clearAdminSession();
+    return false;
+  }
+
+  storeAdminSession(await response.json());
+  return true;
+};
+
+// adminRequest sends an authenticated admin request, refreshing the access
+// token once if it has expired.
+const adminRequest = async (path, options = {}) => {
+  const send = () => fetch(`${API_ENDPOINTS.ADMIN}${path}`, {
+    ...options,
+    headers: {
+      ...API_HEADERS,
+      'Authorization': `Bearer ${localStorage.getItem('adminToken')}`,
+    },
+  });
+
+  let response = await send();
+  if (response.status === 401 && await refreshAdminSession()) {
+    response = await send();
+  }
+  return response;
+};
+
+export const adminLogout = async () => {
   try {
-    const response = await fetch(`${API_ENDPOINTS.ADMIN}/bookings`, {
-      headers: {
-        ...API_HEADERS,
-        'Authorization': `Bearer ${adminToken}`,
-      },
-    });
+    await adminRequest('/auth/logout', { method: 'POST' });
+  } finally {
+    clearAdminSession();
+  }
+};
+
+// fetchBookings returns one page of bookings. params holds the server-side
+// filters (status, hallId, dateFrom, dateTo, createdFrom, createdTo, q,
+// minPrice, maxPrice), sort, order, limit and the cursor of the next page.
+export const fetchBookings = async (params = {}) => {
+  try {
+    const query = new URLSearchParams(
+      Object.entries(params).filter(([, value]) => value !== undefined && value !== null && value !== '')
+    );
+    const response = await adminRequest(`/bookings?${query}`);
 
     if (!response.ok) {
       throw new Error('Failed to fetch bookings');
@@ -71,14 +156,34 @@ export const fetchAllBookings = async (adminToken) => {
   }
 };
 
-export const updateBookingStatus = async (bookingId, status, adminToken) => {
+// downloadExport saves an export ('bookings', 'payments' or 'contacts') in
+// the given format ('csv' or 'xlsx'), using the same filters as fetchBookings.
+export const downloadExport = async (kind, format, params = {}) => {
+  const query = new URLSearchParams(
+    Object.entries({ ...params, format, cursor: undefined, limit: undefined })
+      .filter(([, value]) => value !== undefined && value !== null && value !== '')
+  );
+  const response = await adminRequest(`/exports/${kind}?${query}`);
+  if (!response.ok) {
+    throw new Error(`Failed to export ${kind}`);
+  }
+
+  const disposition = response.headers.get('Content-Disposition') || '';
+  const match = disposition.match(/filename="([^"]+)"/);
+  const url = URL.createObjectURL(await response.blob());
+  const link = document.createElement('a');
+  link.href = url;
+  link.download = match ? match[1] : `${kind}.${format}`;
+  document.body.appendChild(link);
+  link.click();
+  link.remove();
+  URL.revokeObjectURL(url);
+};
+
+export const updateBookingStatus = async (bookingId, status) => {
   try {
-    const response = await fetch(`${API_ENDPOINTS.ADMIN}/bookings/${bookingId}/status`, {
+    const response = await adminRequest(`/bookings/${bookingId}/status`, {
       method: 'PUT',
-      headers: {
-        ...API_HEADERS,
-        'Authorization': `Bearer ${adminToken}`,
-      },
       body: JSON.stringify({ status }),
     });
 
@@ -93,14 +198,24 @@ export const updateBookingStatus = async (bookingId, status, adminToken) => {
   }
 };
 
-export const createHall = async (hallData, adminToken) => {
+// bulkUpdateBookings applies one action (confirm, cancel, reassign_hall, tag
+// or untag) to many bookings and returns the per-booking results.
+export const bulkUpdateBookings = async (ids, action, options = {}) => {
+  const response = await adminRequest('/bookings/bulk', {
+    method: 'POST',
+    body: JSON.stringify({ ids, action, ...options }),
+  });
+  const data = await response.json().catch(() => ({}));
+  if (!response.ok) {
+    throw new Error(data.error || 'Failed to update bookings');
+  }
+  return data;
+};
+
+export const createHall = async (hallData) => {
   try {
-    const response = await fetch(`${API_ENDPOINTS.ADMIN}/halls`, {
+    const response = await adminRequest('/halls', {
       method: 'POST',
-      headers: {
-        ...API_HEADERS,
-        'Authorization': `Bearer ${adminToken}`,
-      },
       body: JSON.stringify(hallData),
     });
 
@@ -115,14 +230,10 @@ export const createHall = async (hallData, adminToken) => {
   }
 };
 
-export const updateHall = async (hallId, hallData, adminToken) => {
+export const updateHall = async (hallId, hallData) => {
   try {
-    const response = await fetch(`${API_ENDPOINTS.ADMIN}/halls/${hallId}`, {
+    const response = await adminRequest(`/halls/${hallId}`, {
       method: 'PUT',
-      headers: {
-        ...API_HEADERS,
-        'Authorization': `Bearer ${adminToken}`,
-      },
       body: JSON.stringify(hallData),
     });
 
@@ -135,4 +246,4 @@ export const updateHall = async (hallId, hallData, adminToken) => {
     console.error('Error updating hall:', error);
     throw error;
   }
-}; 
\ No newline at end of file
+};